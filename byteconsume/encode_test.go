@@ -0,0 +1,36 @@
+package byteconsume
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/arran4/go-consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixConsumer_MarshalBinary(t *testing.T) {
+	data, err := NewPrefixConsumer("GET", "POST", "PUT").MarshalBinary()
+	assert.NoError(t, err)
+
+	var pc PrefixConsumer
+	assert.NoError(t, pc.UnmarshalBinary(data))
+	matched, ok := pc.LongestPrefix([]byte("POST /index.html"))
+	assert.True(t, ok)
+	assert.Equal(t, "POST", string(matched))
+
+	assert.ErrorIs(t, pc.UnmarshalBinary(data[:len(data)-1]), consume.ErrBadEncoding)
+}
+
+func TestUntilConsumer_MarshalJSON(t *testing.T) {
+	cu, err := NewUntilConsumer("\r\n").With(consume.Inclusive(true))
+	assert.NoError(t, err)
+	data, err := json.Marshal(cu)
+	assert.NoError(t, err)
+
+	var got UntilConsumer
+	assert.NoError(t, json.Unmarshal(data, &got))
+	matched, _, remaining, ok := got.Consume([]byte("line\r\nrest"))
+	assert.True(t, ok)
+	assert.Equal(t, "line\r\n", string(matched))
+	assert.Equal(t, "rest", string(remaining))
+}
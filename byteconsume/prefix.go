@@ -0,0 +1,197 @@
+package byteconsume
+
+import (
+	"bufio"
+	"iter"
+
+	"github.com/arran4/go-consume"
+	"github.com/arran4/go-consume/internal/engine"
+)
+
+type PrefixConsumer struct {
+	trie   *engine.Trie[struct{}]
+	config consume.Config
+}
+
+func NewPrefixConsumer(paths ...string) *PrefixConsumer {
+	return &PrefixConsumer{trie: engine.NewTrie[struct{}](paths, nil)}
+}
+
+// With returns a copy of the consumer which applies the given options to every call, before any options
+// passed to the call itself. The copy shares the trie. The options are validated once here rather than on
+// each call.
+func (ps *PrefixConsumer) With(ops ...any) (*PrefixConsumer, error) {
+	c := *ps
+	if err := c.config.Apply(ops...); err != nil {
+		return nil, err
+	}
+	if err := consume.CheckSupported("PrefixConsumer.With", engine.PrefixWithSupports, ops...); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Insert adds a path to the set and reports whether it was not already there. It only copies the nodes along
+// the path, so it is much cheaper than building a new consumer. Insert and Delete may be called concurrently
+// with each other and with any method which reads the set, which sees the set either before or after each
+// change. Copies made by With share the set, copies made by Clone do not. The first Consume after a change
+// rebuilds the automaton it searches with.
+func (ps *PrefixConsumer) Insert(path string) bool {
+	return ps.trie.Insert(path, struct{}{})
+}
+
+// Delete removes a path from the set and reports whether it was there. It has the same concurrency rules as
+// Insert.
+func (ps *PrefixConsumer) Delete(path string) bool {
+	return ps.trie.Delete(path)
+}
+
+// Contains reports whether path is in the set.
+func (ps *PrefixConsumer) Contains(path string) bool {
+	return ps.trie.Contains(path)
+}
+
+// Len returns the number of paths in the set.
+func (ps *PrefixConsumer) Len() int {
+	return ps.trie.Len()
+}
+
+// Clone returns a copy of the consumer with its own set of paths, which later changes to either do not
+// affect. It takes constant time, as the copies share nodes until they change them, so it can be used to take
+// a snapshot of a set which is being updated.
+func (ps *PrefixConsumer) Clone() *PrefixConsumer {
+	c := *ps
+	c.trie = ps.trie.Clone()
+	return &c
+}
+
+// LongestPrefix finds the longest path in the set that is a prefix of text. It returns the matching
+// subslice of text and true if found, otherwise an empty slice and false.
+// Options:
+// - consume.CaseInsensitive(true): Matches paths using Unicode simple case folding.
+// - consume.NFC, consume.NFD, consume.NFKC or consume.NFKD: Matches paths under a Unicode normalization form.
+// - consume.Strict(true): Ignored, as LongestPrefix cannot return an error. Use With to have unsupported options
+// reported.
+func (ps *PrefixConsumer) LongestPrefix(text []byte, ops ...any) ([]byte, bool) {
+	cfg := engine.MustApplyOptions(ps.config, ops)
+	n, found := engine.LongestPrefix(ps.trie, text, engine.MappingOf(&cfg))
+	return text[:n], found
+}
+
+// ShortestPrefix finds the shortest path in the set that is a prefix of text. It returns the matching
+// subslice of text and true if found, otherwise an empty slice and false.
+// Options:
+// - consume.CaseInsensitive(true): Matches paths using Unicode simple case folding.
+// - consume.NFC, consume.NFD, consume.NFKC or consume.NFKD: Matches paths under a Unicode normalization form.
+// - consume.Strict(true): Ignored, as ShortestPrefix cannot return an error. Use With to have unsupported options
+// reported.
+func (ps *PrefixConsumer) ShortestPrefix(text []byte, ops ...any) ([]byte, bool) {
+	cfg := engine.MustApplyOptions(ps.config, ops)
+	n, _, _, found := engine.ShortestPrefixKey(ps.trie, text, engine.MappingOf(&cfg))
+	return text[:n], found
+}
+
+// AllPrefixes returns an iterator over every path in the set that is a prefix of text, shortest first, as
+// subslices of text. The last one yielded is the one LongestPrefix returns.
+// Options:
+// - consume.CaseInsensitive(true): Matches paths using Unicode simple case folding.
+// - consume.NFC, consume.NFD, consume.NFKC or consume.NFKD: Matches paths under a Unicode normalization form.
+// - consume.Strict(true): Ignored, as AllPrefixes cannot return an error. Use With to have unsupported options
+// reported.
+func (ps *PrefixConsumer) AllPrefixes(text []byte, ops ...any) iter.Seq[[]byte] {
+	cfg := engine.MustApplyOptions(ps.config, ops)
+	return func(yield func([]byte) bool) {
+		engine.AllPrefixes(ps.trie, text, engine.MappingOf(&cfg), func(n int, _ string, _ struct{}) bool {
+			return yield(text[:n])
+		})
+	}
+}
+
+// KeysWithPrefix returns an iterator over every path in the set that starts with prefix, in sorted order,
+// for uses such as completion. An empty prefix yields every path.
+// Options:
+// - consume.CaseInsensitive(true): Matches paths whose Unicode simple case folding starts with that of prefix.
+// The matching paths are collected before the first is yielded.
+// - consume.NFC, consume.NFD, consume.NFKC or consume.NFKD: Matches paths whose normalized form starts with that
+// of prefix.
+// - consume.Strict(true): Ignored, as KeysWithPrefix cannot return an error. Use With to have unsupported options
+// reported.
+func (ps *PrefixConsumer) KeysWithPrefix(prefix string, ops ...any) iter.Seq[string] {
+	cfg := engine.MustApplyOptions(ps.config, ops)
+	return func(yield func(string) bool) {
+		engine.KeysWithPrefix(ps.trie, prefix, engine.MappingOf(&cfg), func(key string, _ struct{}) bool {
+			return yield(key)
+		})
+	}
+}
+
+// Consume scans 'from' to find the longest matching prefix from the configured set, in the same way as
+// strconsume.PrefixConsumer.Consume. It returns (before, match, remaining, found), all subslices of from.
+// Matches start on rune boundaries, and the search reads each byte once.
+// Options:
+// - consume.Inclusive(true): If true, 'before' includes the matched prefix, and 'remaining' starts after it.
+// - consume.StartOffset(n): Starts the search at offset n.
+// - consume.Ignore0PositionMatch(true): Ignores matches at the very start of the search (offset).
+// - consume.MustBePrecededBy(func(rune) bool): The match must come after a rune satisfying the predicate, or at
+// the start of the input.
+// - consume.MustBeFollowedBy(func(rune) bool): The match must be followed by a rune satisfying the predicate.
+// - consume.MustBeAtEnd(true): The match must be at the end of the input.
+// - consume.WordBoundary(true): The match must start and end at word boundaries.
+// - consume.CaseInsensitive(true): Matches prefixes case-insensitively.
+// - consume.NFC, consume.NFD, consume.NFKC or consume.NFKD: Matches prefixes under a Unicode normalization form.
+// - consume.MustMatchWholeString(true): The input must be exactly one of the configured prefixes.
+// - consume.Strict(true): Ignored, as Consume cannot return an error. Use ConsumeE or With to have unsupported
+// options reported.
+func (ps *PrefixConsumer) Consume(from []byte, ops ...any) ([]byte, []byte, []byte, bool) {
+	cfg := engine.MustApplyOptions(ps.config, ops)
+	return engine.Prefix(ps.trie, from, &cfg)
+}
+
+// ConsumeE is like Consume, but reports invalid or unsupported options as an error instead of panicking or
+// ignoring them.
+func (ps *PrefixConsumer) ConsumeE(from []byte, ops ...any) ([]byte, []byte, []byte, bool, error) {
+	cfg, err := engine.ApplyOptions(ps.config, "PrefixConsumer.ConsumeE", engine.PrefixConsumeSupports, ops)
+	if err == nil {
+		err = consume.CheckSupported("PrefixConsumer.ConsumeE", engine.PrefixConsumeSupports, ops...)
+	}
+	if err != nil {
+		return from[:0], from[:0], from, false, err
+	}
+	matched, separator, remaining, found := engine.Prefix(ps.trie, from, &cfg)
+	return matched, separator, remaining, found, nil
+}
+
+// Iterator yields each consecutive prefix found at the start of the input along with the data remaining after it.
+// Iteration stops at the first position where no prefix matches.
+// Options:
+// - consume.CaseInsensitive(true): Matches prefixes case-insensitively.
+// - consume.NFC, consume.NFD, consume.NFKC or consume.NFKD: Matches prefixes under a Unicode normalization form.
+// - consume.Strict(true): Ignored, as Iterator cannot return an error. Use With to have unsupported options
+// reported.
+func (ps *PrefixConsumer) Iterator(from []byte, ops ...any) func(yield func([]byte, []byte) bool) {
+	cfg := engine.MustApplyOptions(ps.config, ops)
+	return engine.PrefixIterator(ps.trie, from, cfg)
+}
+
+// SplitFunc returns a bufio.SplitFunc which yields each consecutive prefix found at the start of the data.
+// The longest prefix is always taken, so the split function asks for more data while a longer one might still
+// match. An empty prefix is never yielded.
+// Options:
+// - consume.CaseInsensitive(true): Matches prefixes case-insensitively.
+// - consume.NFC, consume.NFD, consume.NFKC or consume.NFKD: Matches prefixes under a Unicode normalization form.
+// - consume.MustBeFollowedBy(func(rune) bool): A prefix must be followed by a rune satisfying the predicate, or
+// the end of the input.
+// - consume.MustBePrecededBy(func(rune) bool) and consume.WordBoundary(true): As for Consume, where the rune
+// before a prefix is the end of the previous token, so the split function must only be used by one scanner.
+// - consume.NoMatchError, consume.NoMatchSkip or consume.NoMatchToken: What to do with input which does not start
+// with a prefix. By default the scan stops with consume.ErrNoMatch. NoMatchSkip discards the input up to the next
+// rune where a prefix may match, and NoMatchToken yields it as a token.
+// - consume.Strict(true): The split function fails with a *consume.UnsupportedOptionError if any other option
+// is not supported.
+func (ps *PrefixConsumer) SplitFunc(ops ...any) bufio.SplitFunc {
+	cfg, err := engine.SplitOptions(ps.config, "PrefixConsumer.SplitFunc", engine.PrefixSplitFuncSupports, ops)
+	if err != nil {
+		return engine.FailingSplitFunc(err)
+	}
+	return engine.PrefixSplitFunc(ps.trie, cfg)
+}
@@ -0,0 +1,149 @@
+package byteconsume
+
+import (
+	"bufio"
+	"bytes"
+	"slices"
+	"testing"
+	"unicode"
+
+	"github.com/arran4/go-consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixConsumer_LongestPrefix(t *testing.T) {
+	pc := NewPrefixConsumer("/api", "/api/v1", "/apple")
+	tests := []struct {
+		input    string
+		ops      []any
+		expected string
+		found    bool
+	}{
+		{input: "/api/v1/users", expected: "/api/v1", found: true},
+		{input: "/api/v2", expected: "/api", found: true},
+		{input: "/apple/pie", expected: "/apple", found: true},
+		{input: "/ap", expected: "", found: false},
+		{input: "/API/V1/users", ops: []any{consume.CaseInsensitive(true)}, expected: "/API/V1", found: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			input := []byte(tt.input)
+			matched, found := pc.LongestPrefix(input, tt.ops...)
+			assert.Equal(t, tt.found, found)
+			assert.Equal(t, tt.expected, string(matched))
+			if found {
+				assert.True(t, sharesMemory(input, matched))
+			}
+		})
+	}
+}
+
+func TestPrefixConsumer_Consume(t *testing.T) {
+	pc := NewPrefixConsumer("if", "else")
+
+	t.Run("Inclusive", func(t *testing.T) {
+		before, match, remaining, found := pc.Consume([]byte("x else y"), consume.Inclusive(true))
+		assert.True(t, found)
+		assert.Equal(t, "x else", string(before))
+		assert.Equal(t, "else", string(match))
+		assert.Equal(t, " y", string(remaining))
+	})
+
+	t.Run("MustBeFollowedBy", func(t *testing.T) {
+		before, match, remaining, found := pc.Consume([]byte("elsewhere if x"), consume.MustBeFollowedBy(unicode.IsSpace))
+		assert.True(t, found)
+		assert.Equal(t, "elsewhere ", string(before))
+		assert.Equal(t, "if", string(match))
+		assert.Equal(t, "if x", string(remaining))
+	})
+
+	t.Run("MustMatchWholeString", func(t *testing.T) {
+		_, _, _, found := pc.Consume([]byte("else"), consume.MustMatchWholeString(true))
+		assert.True(t, found)
+		_, _, _, found = pc.Consume([]byte("elsewhere"), consume.MustMatchWholeString(true))
+		assert.False(t, found)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		_, _, remaining, found := pc.Consume([]byte("while"))
+		assert.False(t, found)
+		assert.Equal(t, "while", string(remaining))
+	})
+}
+
+func TestPrefixConsumer_Iterator(t *testing.T) {
+	pc := NewPrefixConsumer("a", "ab", "c")
+	var tokens []string
+	for matched := range pc.Iterator([]byte("abcax")) {
+		tokens = append(tokens, string(matched))
+	}
+	assert.Equal(t, []string{"ab", "c", "a"}, tokens)
+}
+
+func TestPrefixConsumer_AllPrefixes(t *testing.T) {
+	pc := NewPrefixConsumer("/", "/api", "/api/v1", "/apple")
+	input := []byte("/API/v1/users")
+	var prefixes []string
+	for matched := range pc.AllPrefixes(input, consume.CaseInsensitive(true)) {
+		assert.True(t, sharesMemory(input, matched))
+		prefixes = append(prefixes, string(matched))
+	}
+	assert.Equal(t, []string{"/", "/API", "/API/v1"}, prefixes)
+
+	matched, found := pc.ShortestPrefix(input)
+	assert.True(t, found)
+	assert.Equal(t, "/", string(matched))
+	_, found = pc.ShortestPrefix([]byte("api"))
+	assert.False(t, found)
+}
+
+func TestPrefixConsumer_KeysWithPrefix(t *testing.T) {
+	pc := NewPrefixConsumer("GET", "GETALL", "POST", "PUT")
+	assert.Equal(t, []string{"POST", "PUT"}, slices.Collect(pc.KeysWithPrefix("P")))
+	assert.Equal(t, []string{"GET", "GETALL"}, slices.Collect(pc.KeysWithPrefix("get", consume.CaseInsensitive(true))))
+	assert.Empty(t, slices.Collect(pc.KeysWithPrefix("DELETE")))
+}
+
+func TestPrefixConsumer_SplitFunc(t *testing.T) {
+	scanner := bufio.NewScanner(bytes.NewReader([]byte("GETpost")))
+	scanner.Split(NewPrefixConsumer("GET", "POST").SplitFunc(consume.CaseInsensitive(true)))
+	var tokens []string
+	for scanner.Scan() {
+		tokens = append(tokens, scanner.Text())
+	}
+	assert.Equal(t, []string{"GET", "post"}, tokens)
+}
+
+func TestPrefixConsumer_DoesNotAllocate(t *testing.T) {
+	pc := NewPrefixConsumer("GET", "POST", "PUT", "DELETE")
+	input := []byte("DELETE /resource")
+	split := pc.SplitFunc(consume.CaseInsensitive(true))
+
+	allocs := testing.AllocsPerRun(100, func() {
+		pc.LongestPrefix(input)
+		pc.LongestPrefix(input, consume.CaseInsensitive(true))
+		pc.ShortestPrefix(input)
+		pc.Consume(input, consume.Inclusive(true))
+		split(input, false)
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
+func TestPrefixConsumer_InsertDelete(t *testing.T) {
+	pc := NewPrefixConsumer("GET")
+	snapshot := pc.Clone()
+	assert.True(t, pc.Insert("GETALL"))
+	assert.True(t, pc.Delete("GET"))
+	assert.Equal(t, 1, pc.Len())
+	assert.True(t, pc.Contains("GETALL"))
+
+	matched, found := pc.LongestPrefix([]byte("GETALL /"))
+	assert.True(t, found)
+	assert.Equal(t, "GETALL", string(matched))
+	_, found = pc.LongestPrefix([]byte("GET /"))
+	assert.False(t, found)
+
+	matched, found = snapshot.LongestPrefix([]byte("GETALL /"))
+	assert.True(t, found)
+	assert.Equal(t, "GET", string(matched))
+}
@@ -0,0 +1,61 @@
+package byteconsume
+
+import (
+	"io"
+
+	"github.com/arran4/go-consume/internal/engine"
+)
+
+// UntilScanner reads the tokens of an UntilConsumer from an io.Reader. It yields the same tokens as
+// bufio.Scanner with SplitFunc, but keeps the encasing stack, pending escapes and partial separator matches of
+// the current token across reads, so a long token is scanned once rather than from its start on every refill.
+// With patterns, CollapseRuns or conditions on the input around a separator, the search after each read
+// resumes a few bytes before the end of the last one, but from where the outermost encasing or escape still
+// open began, and from the start of the token with regular expressions, which cannot be resumed. A long token
+// inside an encasing, or with a regular expression separator, costs time quadratic in its length.
+type UntilScanner struct {
+	stream *engine.Stream
+}
+
+// Scanner returns an UntilScanner reading from r. It accepts the same options as SplitFunc.
+func (cu UntilConsumer) Scanner(r io.Reader, ops ...any) *UntilScanner {
+	cfg, err := engine.SplitOptions(cu.config, "UntilConsumer.Scanner", engine.UntilSplitFuncSupports, ops)
+	stream := engine.NewStream(cu.separators, r, cfg)
+	if err != nil {
+		stream.Fail(err)
+	}
+	return &UntilScanner{stream: stream}
+}
+
+// Buffer sets the initial buffer and the maximum token size, as bufio.Scanner.Buffer does. The default
+// maximum is bufio.MaxScanTokenSize. Buffer panics if it is called after scanning has started.
+func (s *UntilScanner) Buffer(buf []byte, max int) {
+	s.stream.Buffer(buf, max)
+}
+
+// Scan advances to the next token. It returns false when the input ends or an error occurs.
+func (s *UntilScanner) Scan() bool {
+	return s.stream.Scan()
+}
+
+// Bytes returns the most recent token. The slice may be overwritten by the next call to Scan.
+func (s *UntilScanner) Bytes() []byte {
+	return s.stream.Token()
+}
+
+// Text returns the most recent token as a string.
+func (s *UntilScanner) Text() string {
+	return string(s.stream.Token())
+}
+
+// Separator returns the separator which ended the most recent token, or nil if it ran to the end of the
+// input. The slice may be overwritten by the next call to Scan.
+func (s *UntilScanner) Separator() []byte {
+	return s.stream.Separator()
+}
+
+// Err returns the first error encountered, other than io.EOF. A token longer than the maximum token size is
+// reported as bufio.ErrTooLong.
+func (s *UntilScanner) Err() error {
+	return s.stream.Err()
+}
@@ -0,0 +1,136 @@
+// Package byteconsume provides the consumers of strconsume for []byte input. Matching runs directly on the
+// bytes, and unless Unescape or StripEncasing change a token, every result is a subslice of the input.
+package byteconsume
+
+import (
+	"bufio"
+	"regexp"
+	"unicode"
+
+	"github.com/arran4/go-consume"
+	"github.com/arran4/go-consume/internal/engine"
+)
+
+func NewUntilConsumer(s ...string) UntilConsumer {
+	return UntilConsumer{separators: engine.NewSeparators(s)}
+}
+
+// NewUntilRegexpConsumer returns an UntilConsumer which splits on the matches of regular expressions, as
+// strconsume.NewUntilRegexpConsumer describes.
+func NewUntilRegexpConsumer(patterns ...*regexp.Regexp) UntilConsumer {
+	return UntilConsumer{separators: engine.NewSeparators(nil, engine.RegexpPatterns(patterns)...)}
+}
+
+// OrRegexp returns a copy of the consumer which also splits on the matches of regular expressions, as
+// strconsume.UntilConsumer.OrRegexp describes.
+func (cu UntilConsumer) OrRegexp(patterns ...*regexp.Regexp) UntilConsumer {
+	cu.separators = engine.WithPatterns(cu.separators, engine.RegexpPatterns(patterns))
+	return cu
+}
+
+// NewUntilFuncConsumer returns an UntilConsumer which splits on any one rune for which one of fs returns true,
+// as strconsume.NewUntilFuncConsumer describes.
+func NewUntilFuncConsumer(fs ...func(rune) bool) UntilConsumer {
+	return UntilConsumer{separators: engine.NewSeparators(nil, engine.RunePatterns(fs)...)}
+}
+
+// NewUntilRangeTableConsumer returns an UntilConsumer which splits on any one rune in tables, as
+// strconsume.NewUntilRangeTableConsumer describes.
+func NewUntilRangeTableConsumer(tables ...*unicode.RangeTable) UntilConsumer {
+	return UntilConsumer{separators: engine.NewSeparators(nil, engine.RangeTablePattern(tables))}
+}
+
+// OrFunc returns a copy of the consumer which also splits on any one rune for which one of fs returns true, as
+// strconsume.UntilConsumer.OrFunc describes.
+func (cu UntilConsumer) OrFunc(fs ...func(rune) bool) UntilConsumer {
+	cu.separators = engine.WithPatterns(cu.separators, engine.RunePatterns(fs))
+	return cu
+}
+
+// OrRangeTable returns a copy of the consumer which also splits on any one rune in tables, as
+// strconsume.UntilConsumer.OrRangeTable describes.
+func (cu UntilConsumer) OrRangeTable(tables ...*unicode.RangeTable) UntilConsumer {
+	cu.separators = engine.WithPatterns(cu.separators, []engine.Pattern{engine.RangeTablePattern(tables)})
+	return cu
+}
+
+type UntilConsumer struct {
+	separators *engine.Separators
+	config     consume.Config
+}
+
+// Consume scans the input 'from' for any of the configured separators.
+// It returns four values:
+// 1. matched: The bytes before the found separator.
+// 2. separator: The separator that was found.
+// 3. remaining: The rest of the input. If inclusive is true, this starts after the separator. If false, it starts at the separator.
+// 4. found: True if a separator was found, false otherwise.
+// If no separator is found, it returns (from[:0], from[:0], from, false).
+// Options are those of strconsume.UntilConsumer.Consume:
+// - consume.Inclusive(true): If true, matched includes the separator, and remaining starts after it.
+// - consume.StartOffset(n): Starts the search at offset n.
+// - consume.Ignore0PositionMatch(true): Ignores matches at the start of the input.
+// - consume.CaseInsensitive(true): Matches separators case-insensitively.
+// - consume.NFC, consume.NFD, consume.NFKC or consume.NFKD: Matches separators under a Unicode normalization form.
+// - consume.ConsumeRemainingIfNotFound(true): If no separator is found, return the whole input as matched, an empty separator, and true.
+// - consume.Escape("string"): Specifies an escape string (e.g. "\\"). Can be specified multiple times.
+// - consume.Encasing{Start: "(", End: ")"}: Specifies an encasing pair. Can be specified multiple times.
+// - consume.EscapeBreaksEncasing(true): If true, escape strings work inside encasings.
+// - consume.MustMatchWholeString(true): The separator must span the entire input.
+// - consume.Unescape(true): Removes escape strings from matched, keeping the runes they escape.
+// - consume.StripEncasing(true): Removes the start and end of outermost encasings from matched.
+// - consume.Strict(true): Ignored, as Consume cannot return an error. Use ConsumeE or With to have unsupported
+// options reported.
+// A matched value changed by Unescape or StripEncasing is newly allocated. The raw bytes it was decoded from
+// are always from[:len(from)-len(remaining)].
+func (cu UntilConsumer) Consume(from []byte, ops ...any) ([]byte, []byte, []byte, bool) {
+	cfg := engine.MustApplyOptions(cu.config, ops)
+	return engine.Until(cu.separators, from, &cfg, engine.NoRune)
+}
+
+// ConsumeE is like Consume, but reports invalid or unsupported options as an error instead of panicking or
+// ignoring them. When no separator is found because the input ends inside an encasing, it returns a
+// *consume.UnterminatedEncasingError, and when it ends with an escape which has nothing to escape, a
+// *consume.DanglingEscapeError. Either way the results are those of a failed match, even with
+// ConsumeRemainingIfNotFound.
+func (cu UntilConsumer) ConsumeE(from []byte, ops ...any) ([]byte, []byte, []byte, bool, error) {
+	cfg, err := engine.ApplyOptions(cu.config, "UntilConsumer.ConsumeE", engine.UntilConsumeSupports, ops)
+	if err == nil {
+		err = consume.CheckSupported("UntilConsumer.ConsumeE", engine.UntilConsumeSupports, ops...)
+	}
+	if err != nil {
+		return from[:0], from[:0], from, false, err
+	}
+	return engine.UntilE(cu.separators, from, &cfg, engine.NoRune)
+}
+
+// With returns a copy of the consumer which applies the given options to every call, before any options
+// passed to the call itself. The options are validated once here rather than on each call, and an
+// option the consumer does not support is reported as a *consume.UnsupportedOptionError.
+func (cu UntilConsumer) With(ops ...any) (UntilConsumer, error) {
+	if err := cu.config.Apply(ops...); err != nil {
+		return UntilConsumer{}, err
+	}
+	if err := consume.CheckSupported("UntilConsumer.With", engine.UntilConsumeSupports, ops...); err != nil {
+		return UntilConsumer{}, err
+	}
+	return cu, nil
+}
+
+// SplitFunc returns a bufio.SplitFunc which yields the data between separators. It behaves exactly like
+// strconsume.UntilConsumer.SplitFunc. Each call scans the data from the start of the token, so for tokens which
+// span many reads Scanner is faster.
+func (cu UntilConsumer) SplitFunc(ops ...any) bufio.SplitFunc {
+	cfg, err := engine.SplitOptions(cu.config, "UntilConsumer.SplitFunc", engine.UntilSplitFuncSupports, ops)
+	if err != nil {
+		return engine.FailingSplitFunc(err)
+	}
+	return engine.UntilSplitFunc(cu.separators, cfg)
+}
+
+// Iterator splits the input by the configured separators, yielding (matched, separator) pairs in the same
+// way as strconsume.UntilConsumer.Iterator. It accepts the same options as Consume.
+func (cu UntilConsumer) Iterator(from []byte, ops ...any) func(yield func([]byte, []byte) bool) {
+	cfg := engine.MustApplyOptions(cu.config, ops)
+	return engine.UntilIterator(cu.separators, from, cfg)
+}
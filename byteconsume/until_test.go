@@ -0,0 +1,355 @@
+package byteconsume
+
+import (
+	"bufio"
+	"bytes"
+	"math/rand"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unicode"
+	"unsafe"
+
+	"github.com/arran4/go-consume"
+	"github.com/arran4/go-consume/strconsume"
+	"github.com/stretchr/testify/assert"
+)
+
+// sharesMemory reports whether sub is a non-empty subslice of data.
+func sharesMemory(data, sub []byte) bool {
+	if len(sub) == 0 || len(data) == 0 {
+		return false
+	}
+	start := uintptr(unsafe.Pointer(unsafe.SliceData(data)))
+	p := uintptr(unsafe.Pointer(unsafe.SliceData(sub)))
+	return p >= start && p+uintptr(len(sub)) <= start+uintptr(len(data))
+}
+
+func TestUntilConsumer_Consume(t *testing.T) {
+	tests := []struct {
+		name              string
+		separators        []string
+		input             string
+		ops               []any
+		expectedMatched   string
+		expectedSeparator string
+		expectedRemaining string
+		expectedFound     bool
+	}{
+		{
+			name:              "Simple",
+			separators:        []string{"/"},
+			input:             "path/to/resource",
+			expectedMatched:   "path",
+			expectedSeparator: "/",
+			expectedRemaining: "/to/resource",
+			expectedFound:     true,
+		},
+		{
+			name:              "Inclusive",
+			separators:        []string{"/"},
+			input:             "path/to/resource",
+			ops:               []any{consume.Inclusive(true)},
+			expectedMatched:   "path/",
+			expectedSeparator: "/",
+			expectedRemaining: "to/resource",
+			expectedFound:     true,
+		},
+		{
+			name:              "Longest separator",
+			separators:        []string{"a", "ab"},
+			input:             "xaby",
+			expectedMatched:   "x",
+			expectedSeparator: "ab",
+			expectedRemaining: "aby",
+			expectedFound:     true,
+		},
+		{
+			name:              "Not found",
+			separators:        []string{"/"},
+			input:             "path",
+			expectedMatched:   "",
+			expectedSeparator: "",
+			expectedRemaining: "path",
+			expectedFound:     false,
+		},
+		{
+			name:              "ConsumeRemainingIfNotFound",
+			separators:        []string{"/"},
+			input:             "path",
+			ops:               []any{consume.ConsumeRemainingIfNotFound(true)},
+			expectedMatched:   "path",
+			expectedSeparator: "",
+			expectedRemaining: "",
+			expectedFound:     true,
+		},
+		{
+			name:              "CaseInsensitive",
+			separators:        []string{"AND"},
+			input:             "x and y",
+			ops:               []any{consume.CaseInsensitive(true)},
+			expectedMatched:   "x ",
+			expectedSeparator: "and",
+			expectedRemaining: "and y",
+			expectedFound:     true,
+		},
+		{
+			name:              "Escape and encasing",
+			separators:        []string{":"},
+			input:             `a\:"b:c":d`,
+			ops:               []any{consume.Escape("\\"), consume.Encasing{Start: "\"", End: "\""}},
+			expectedMatched:   `a\:"b:c"`,
+			expectedSeparator: ":",
+			expectedRemaining: ":d",
+			expectedFound:     true,
+		},
+		{
+			name:              "Unescape and StripEncasing",
+			separators:        []string{":"},
+			input:             `a\:"b:c":d`,
+			ops:               []any{consume.Escape("\\"), consume.Encasing{Start: "\"", End: "\""}, consume.Unescape(true), consume.StripEncasing(true), consume.Inclusive(true)},
+			expectedMatched:   `a:b:c:`,
+			expectedSeparator: ":",
+			expectedRemaining: "d",
+			expectedFound:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cu := NewUntilConsumer(tt.separators...)
+			matched, separator, remaining, found := cu.Consume([]byte(tt.input), tt.ops...)
+			assert.Equal(t, tt.expectedMatched, string(matched))
+			assert.Equal(t, tt.expectedSeparator, string(separator))
+			assert.Equal(t, tt.expectedRemaining, string(remaining))
+			assert.Equal(t, tt.expectedFound, found)
+		})
+	}
+}
+
+func TestUntilConsumer_Consume_Subslices(t *testing.T) {
+	input := []byte("key=value")
+	matched, separator, remaining, found := NewUntilConsumer("=").Consume(input, consume.Inclusive(true))
+	assert.True(t, found)
+	assert.True(t, sharesMemory(input, matched))
+	assert.True(t, sharesMemory(input, separator))
+	assert.True(t, sharesMemory(input, remaining))
+
+	t.Run("Decoded tokens are copies", func(t *testing.T) {
+		input := []byte(`a\:b:c`)
+		matched, _, _, found := NewUntilConsumer(":").Consume(input, consume.Escape("\\"), consume.Unescape(true))
+		assert.True(t, found)
+		assert.Equal(t, "a:b", string(matched))
+		assert.False(t, sharesMemory(input, matched))
+		assert.Equal(t, `a\:b:c`, string(input))
+	})
+}
+
+func TestUntilConsumer_Consume_MatchesStrconsume(t *testing.T) {
+	alphabet := []string{"a", "b", "A", "/", ":", "\\", "\"", "(", ")", "é", "É", "世", "e\u0301", "\u0323", "\ufb01"}
+	randString := func(r *rand.Rand, maxLen int) string {
+		var sb strings.Builder
+		for n := r.Intn(maxLen + 1); n > 0; n-- {
+			sb.WriteString(alphabet[r.Intn(len(alphabet))])
+		}
+		return sb.String()
+	}
+	optionSets := [][]any{
+		nil,
+		{consume.Inclusive(true)},
+		{consume.CaseInsensitive(true)},
+		{consume.Ignore0PositionMatch(true), consume.ConsumeRemainingIfNotFound(true)},
+		{consume.StartOffset(2)},
+		{consume.Escape("\\"), consume.Unescape(true), consume.Inclusive(true)},
+		{consume.Encasing{Start: "\"", End: "\""}, consume.Encasing{Start: "(", End: ")"}, consume.StripEncasing(true)},
+		{consume.Encasing{Start: "\"", End: "\""}, consume.Escape("\\"), consume.EscapeBreaksEncasing(true), consume.CaseInsensitive(true)},
+		{consume.NFC},
+		{consume.NFKD, consume.CaseInsensitive(true), consume.Inclusive(true)},
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n < 2000; n++ {
+		var seps []string
+		for k := r.Intn(4); k >= 0; k-- {
+			if sep := randString(r, 3); sep != "" {
+				seps = append(seps, sep)
+			}
+		}
+		input := randString(r, 16)
+		ops := optionSets[r.Intn(len(optionSets))]
+
+		matched, sep, remaining, ok := NewUntilConsumer(seps...).Consume([]byte(input), ops...)
+		eMatched, eSep, eRemaining, eOk := strconsume.NewUntilConsumer(seps...).Consume(input, ops...)
+		if string(matched) != eMatched || string(sep) != eSep || string(remaining) != eRemaining || ok != eOk {
+			t.Fatalf("Consume(%q, %#v) with separators %q = (%q, %q, %q, %v), expected (%q, %q, %q, %v)",
+				input, ops, seps, matched, sep, remaining, ok, eMatched, eSep, eRemaining, eOk)
+		}
+	}
+}
+
+func TestUntilConsumer_ConsumeE(t *testing.T) {
+	cu := NewUntilConsumer(":")
+	_, _, remaining, found, err := cu.ConsumeE([]byte(`"a:b`), consume.Encasing{Start: "\"", End: "\""})
+	assert.False(t, found)
+	assert.Equal(t, `"a:b`, string(remaining))
+	var unterminated *consume.UnterminatedEncasingError
+	assert.ErrorAs(t, err, &unterminated)
+
+	_, _, _, _, err = cu.ConsumeE([]byte("a:b"), consume.NoMatchSkip)
+	var unsupported *consume.UnsupportedOptionError
+	assert.ErrorAs(t, err, &unsupported)
+}
+
+func TestUntilConsumer_Iterator(t *testing.T) {
+	cu := NewUntilConsumer(",")
+	var tokens, separators []string
+	for matched, separator := range cu.Iterator([]byte("a,b,,c")) {
+		tokens = append(tokens, string(matched))
+		separators = append(separators, string(separator))
+	}
+	assert.Equal(t, []string{"a", "b", "", "c"}, tokens)
+	assert.Equal(t, []string{",", ",", ",", ""}, separators)
+}
+
+func TestUntilConsumer_SplitFunc(t *testing.T) {
+	scanner := bufio.NewScanner(strings.NewReader(`a,"b,c",d`))
+	scanner.Split(NewUntilConsumer(",").SplitFunc(consume.Encasing{Start: "\"", End: "\""}))
+	var tokens []string
+	for scanner.Scan() {
+		tokens = append(tokens, scanner.Text())
+	}
+	assert.NoError(t, scanner.Err())
+	assert.Equal(t, []string{"a", `"b,c"`, "d"}, tokens)
+}
+
+func TestUntilConsumer_DoesNotAllocate(t *testing.T) {
+	cu, err := NewUntilConsumer(":", ";").With(consume.Escape("\\"), consume.Encasing{Start: "\"", End: "\""}, consume.CaseInsensitive(true))
+	assert.NoError(t, err)
+	input := []byte(`foo\:"bar;baz";qux`)
+	split := cu.SplitFunc()
+
+	t.Run("Consume", func(t *testing.T) {
+		allocs := testing.AllocsPerRun(100, func() {
+			cu.Consume(input)
+		})
+		assert.Equal(t, 0.0, allocs)
+	})
+
+	t.Run("Consume with options", func(t *testing.T) {
+		allocs := testing.AllocsPerRun(100, func() {
+			cu.Consume(input, consume.Inclusive(true))
+		})
+		assert.Equal(t, 0.0, allocs)
+	})
+
+	t.Run("SplitFunc", func(t *testing.T) {
+		allocs := testing.AllocsPerRun(100, func() {
+			split(input, false)
+		})
+		assert.Equal(t, 0.0, allocs)
+	})
+
+	t.Run("Consume with normalization", func(t *testing.T) {
+		allocs := testing.AllocsPerRun(100, func() {
+			cu.Consume(input, consume.NFKC)
+		})
+		assert.Equal(t, 0.0, allocs)
+	})
+}
+
+func TestUntilConsumer_Consume_DoesNotModifyInput(t *testing.T) {
+	input := []byte(`a\:b:c`)
+	original := bytes.Clone(input)
+	NewUntilConsumer(":").Consume(input, consume.Escape("\\"), consume.Unescape(true), consume.Inclusive(true))
+	assert.Equal(t, original, input)
+}
+
+func TestUntilScanner(t *testing.T) {
+	cu := NewUntilConsumer("\r\n")
+	s := cu.Scanner(iotest.OneByteReader(strings.NewReader("GET / HTTP/1.1\r\nHost: \"a\r\nb\"\r\n")), consume.Encasing{Start: "\"", End: "\""}, consume.Inclusive(true))
+	var tokens, separators []string
+	for s.Scan() {
+		tokens = append(tokens, string(s.Bytes()))
+		separators = append(separators, string(s.Separator()))
+	}
+	assert.NoError(t, s.Err())
+	assert.Equal(t, []string{"GET / HTTP/1.1\r\n", "Host: \"a\r\nb\"\r\n"}, tokens)
+	assert.Equal(t, []string{"\r\n", "\r\n"}, separators)
+}
+
+func TestUntilRegexpConsumer_Consume(t *testing.T) {
+	cu := NewUntilConsumer(";").OrRegexp(regexp.MustCompile(`,\s*`))
+	input := []byte(`a\, b,  c;d`)
+	matched, separator, remaining, ok := cu.Consume(input, consume.Escape(`\`))
+	assert.True(t, ok)
+	assert.Equal(t, `a\, b`, string(matched))
+	assert.Equal(t, ",  ", string(separator))
+	assert.Equal(t, ",  c;d", string(remaining))
+	assert.True(t, sharesMemory(input, separator))
+
+	scanner := bufio.NewScanner(iotest.OneByteReader(strings.NewReader("a \t b  c")))
+	scanner.Split(NewUntilRegexpConsumer(regexp.MustCompile(`\s+`)).SplitFunc())
+	var tokens []string
+	for scanner.Scan() {
+		tokens = append(tokens, scanner.Text())
+	}
+	assert.NoError(t, scanner.Err())
+	assert.Equal(t, []string{"a", "b", "c"}, tokens)
+}
+
+func TestUntilFuncConsumer_Consume(t *testing.T) {
+	cu := NewUntilRangeTableConsumer(unicode.White_Space).OrFunc(func(r rune) bool { return r == ';' })
+	input := []byte(`a\ b ; c`)
+	matched, separator, remaining, ok := cu.Consume(input, consume.Escape(`\`), consume.CollapseRuns(true))
+	assert.True(t, ok)
+	assert.Equal(t, `a\ b`, string(matched))
+	assert.Equal(t, " ; ", string(separator))
+	assert.Equal(t, " ; c", string(remaining))
+	assert.True(t, sharesMemory(input, separator))
+
+	s := NewUntilFuncConsumer(unicode.IsSpace).Scanner(iotest.OneByteReader(strings.NewReader("a \t b  c")), consume.CollapseRuns(true))
+	var tokens []string
+	for s.Scan() {
+		tokens = append(tokens, s.Text())
+	}
+	assert.NoError(t, s.Err())
+	assert.Equal(t, []string{"a", "b", "c"}, tokens)
+}
+
+func TestUntilConsumer_Consume_WordBoundary(t *testing.T) {
+	cu := NewUntilConsumer("and")
+	input := []byte("sandy and rocky")
+	matched, separator, remaining, ok := cu.Consume(input, consume.WordBoundary(true))
+	assert.True(t, ok)
+	assert.Equal(t, "sandy ", string(matched))
+	assert.Equal(t, "and", string(separator))
+	assert.Equal(t, "and rocky", string(remaining))
+
+	s := cu.Scanner(iotest.OneByteReader(strings.NewReader("sand and andand and x")), consume.WordBoundary(true))
+	var tokens []string
+	for s.Scan() {
+		tokens = append(tokens, string(s.Bytes()))
+	}
+	assert.NoError(t, s.Err())
+	assert.Equal(t, []string{"sand ", " andand ", " x"}, tokens)
+}
+
+func TestUntilConsumer_Consume_Normalization(t *testing.T) {
+	cu := NewUntilConsumer("caf\u00e9")
+	input := []byte("un cafe\u0301 noir")
+	matched, separator, remaining, ok := cu.Consume(input, consume.NFC)
+	assert.True(t, ok)
+	assert.Equal(t, "un ", string(matched))
+	assert.Equal(t, "cafe\u0301", string(separator))
+	assert.Equal(t, "cafe\u0301 noir", string(remaining))
+	assert.True(t, sharesMemory(input, separator))
+
+	s := cu.Scanner(iotest.OneByteReader(strings.NewReader("a cafe\u0301 b caf\u00e9\u0323 c caf\u00e9")), consume.NFD)
+	var tokens []string
+	for s.Scan() {
+		tokens = append(tokens, string(s.Bytes()))
+	}
+	assert.NoError(t, s.Err())
+	assert.Equal(t, []string{"a ", " b caf\u00e9\u0323 c "}, tokens)
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"slices"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+// generator holds the settings for one generated file.
+type generator struct {
+	typeName string
+	kind     string
+	pkg      string
+	keys     []string
+
+	buf bytes.Buffer
+}
+
+// generate returns the formatted source of the matcher.
+func (g *generator) generate() ([]byte, error) {
+	if !token.IsIdentifier(g.typeName) || !token.IsExported(g.typeName) {
+		return nil, fmt.Errorf("type %q is not an exported identifier", g.typeName)
+	}
+	if !token.IsIdentifier(g.pkg) {
+		return nil, fmt.Errorf("package %q is not an identifier", g.pkg)
+	}
+	if g.kind != "prefix" && g.kind != "until" {
+		return nil, fmt.Errorf("kind %q is not prefix or until", g.kind)
+	}
+	if len(g.keys) == 0 {
+		return nil, errors.New("no keys")
+	}
+	for _, k := range g.keys {
+		// An empty key would match everywhere, and keys which are not valid UTF-8 could start part way
+		// through a rune, where strconsume never looks for them.
+		if k == "" {
+			return nil, errors.New("empty keys are not supported")
+		}
+		if !utf8.ValidString(k) {
+			return nil, fmt.Errorf("key %q is not valid UTF-8", k)
+		}
+	}
+	keys := slices.Clone(g.keys)
+	slices.Sort(keys)
+	keys = slices.Compact(keys)
+
+	g.buf.Reset()
+	g.printf("// Code generated by consumegen; DO NOT EDIT.\n\n")
+	g.printf("package %s\n\n", g.pkg)
+	g.printf("import (\n\"bufio\"\n\"sync\"\n\n")
+	if g.kind == "prefix" {
+		g.printf("\"github.com/arran4/go-consume\"\n")
+	}
+	g.printf("\"github.com/arran4/go-consume/strconsume\"\n)\n\n")
+	if g.kind == "prefix" {
+		g.prefixMethods()
+	} else {
+		g.untilMethods()
+	}
+	g.keyList(keys)
+	g.matchFunc(keys)
+
+	src, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return src, nil
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// name returns an unexported name for the generated type's helpers.
+func (g *generator) name(suffix string) string {
+	r, w := utf8.DecodeRuneInString(g.typeName)
+	return string(unicode.ToLower(r)) + g.typeName[w:] + suffix
+}
+
+func (g *generator) prefixMethods() {
+	t, consumer, match := g.typeName, g.name("Consumer"), g.name("Match")
+	g.printf(`// %[1]s matches its keys as a strconsume.PrefixConsumer of them would. Calls with options use such a
+// consumer, built the first time one is made.
+type %[1]s struct{}
+
+var %[2]s = sync.OnceValue(func() *strconsume.PrefixConsumer {
+	return strconsume.NewPrefixConsumer(%[4]s...)
+})
+
+// LongestPrefix returns the longest key which is a prefix of text, as strconsume.PrefixConsumer.LongestPrefix
+// does.
+func (%[1]s) LongestPrefix(text string, ops ...any) (string, bool) {
+	if len(ops) > 0 {
+		return %[2]s().LongestPrefix(text, ops...)
+	}
+	if n, _ := %[3]s(text); n > 0 {
+		return text[:n], true
+	}
+	return "", false
+}
+
+// Consume finds the first key in from, as strconsume.PrefixConsumer.Consume does.
+func (%[1]s) Consume(from string, ops ...any) (string, string, string, bool) {
+	if len(ops) > 0 {
+		return %[2]s().Consume(from, ops...)
+	}
+	for i := 0; i < len(from); i++ {
+		if n, _ := %[3]s(from[i:]); n > 0 {
+			return from[:i], from[i : i+n], from[i:], true
+		}
+	}
+	return "", "", from, false
+}
+
+// SplitFunc returns a bufio.SplitFunc which yields each consecutive key at the start of the data, as
+// strconsume.PrefixConsumer.SplitFunc does.
+func (%[1]s) SplitFunc(ops ...any) bufio.SplitFunc {
+	if len(ops) > 0 {
+		return %[2]s().SplitFunc(ops...)
+	}
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+		n, more := %[3]s(data)
+		if more && !atEOF {
+			return 0, nil, nil
+		}
+		if n == 0 {
+			return 0, nil, consume.ErrNoMatch
+		}
+		return n, data[:n], nil
+	}
+}
+
+`, t, consumer, match, g.name("Keys"))
+}
+
+func (g *generator) untilMethods() {
+	t, consumer, match := g.typeName, g.name("Consumer"), g.name("Match")
+	g.printf(`// %[1]s splits on its keys as a strconsume.UntilConsumer of them would. Calls with options use such a
+// consumer, built the first time one is made.
+type %[1]s struct{}
+
+var %[2]s = sync.OnceValue(func() strconsume.UntilConsumer {
+	return strconsume.NewUntilConsumer(%[4]s...)
+})
+
+// Consume finds the first key in from, as strconsume.UntilConsumer.Consume does.
+func (%[1]s) Consume(from string, ops ...any) (string, string, string, bool) {
+	if len(ops) > 0 {
+		return %[2]s().Consume(from, ops...)
+	}
+	for i := 0; i < len(from); i++ {
+		if n, _ := %[3]s(from[i:]); n > 0 {
+			return from[:i], from[i : i+n], from[i:], true
+		}
+	}
+	return "", "", from, false
+}
+
+// SplitFunc returns a bufio.SplitFunc which yields the text between keys, as
+// strconsume.UntilConsumer.SplitFunc does.
+func (%[1]s) SplitFunc(ops ...any) bufio.SplitFunc {
+	if len(ops) > 0 {
+		return %[2]s().SplitFunc(ops...)
+	}
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+		for i := range data {
+			n, more := %[3]s(data[i:])
+			if more && !atEOF {
+				// A longer key may yet match here.
+				return 0, nil, nil
+			}
+			if n > 0 {
+				return i + n, data[:i], nil
+			}
+		}
+		if atEOF {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+}
+
+`, t, consumer, match, g.name("Keys"))
+}
+
+func (g *generator) keyList(keys []string) {
+	g.printf("var %s = []string{\n", g.name("Keys"))
+	for _, k := range keys {
+		g.printf("%s,\n", strconv.Quote(k))
+	}
+	g.printf("}\n\n")
+}
+
+// matchFunc writes a function returning the length of the longest key which is a prefix of its input, or 0
+// if there is none, and whether more input could match a longer key.
+func (g *generator) matchFunc(keys []string) {
+	g.printf("func %s[T string | []byte](s T) (n int, more bool) {\n", g.name("Match"))
+	g.node(keys, 0)
+	g.printf("}\n")
+}
+
+// node writes the code matching keys, which are sorted and share their first depth bytes with each other and
+// the input. It ends by returning.
+func (g *generator) node(keys []string, depth int) {
+	// The bytes after depth shared by every key are compared together.
+	first, last := keys[0], keys[len(keys)-1]
+	common := depth
+	for common < len(first) && common < len(last) && first[common] == last[common] {
+		common++
+	}
+	if seg := first[depth:common]; seg != "" {
+		g.printf("if len(s) < %d {\n", common)
+		g.printf("return n, string(s[%d:]) == %s[:len(s)-%d]\n}\n", depth, strconv.Quote(seg), depth)
+		if len(seg) == 1 {
+			g.printf("if s[%d] != %s {\nreturn n, false\n}\n", depth, byteLit(seg[0]))
+		} else {
+			g.printf("if string(s[%d:%d]) != %s {\nreturn n, false\n}\n", depth, common, strconv.Quote(seg))
+		}
+		depth = common
+	}
+	if len(keys[0]) == depth {
+		if len(keys) == 1 {
+			g.printf("return %d, false\n", depth)
+			return
+		}
+		g.printf("n = %d\n", depth)
+		keys = keys[1:]
+	}
+	g.printf("if len(s) == %d {\nreturn n, true\n}\n", depth)
+	g.printf("switch s[%d] {\n", depth)
+	for len(keys) > 0 {
+		b := keys[0][depth]
+		i := 1
+		for i < len(keys) && keys[i][depth] == b {
+			i++
+		}
+		g.printf("case %s:\n", byteLit(b))
+		g.node(keys[:i], depth+1)
+		keys = keys[i:]
+	}
+	g.printf("}\nreturn n, false\n")
+}
+
+// byteLit returns a Go literal for b, as a character below utf8.RuneSelf.
+func byteLit(b byte) string {
+	if b < utf8.RuneSelf {
+		return strconv.QuoteRune(rune(b))
+	}
+	return fmt.Sprintf("0x%02x", b)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate_UpToDate(t *testing.T) {
+	tests := []struct {
+		file string
+		g    generator
+	}{
+		{
+			file: "internal/generated/keyword_consumegen.go",
+			g: generator{typeName: "Keyword", kind: "prefix", pkg: "generated",
+				keys: []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS", "/api", "/api/v1", "über"}},
+		},
+		{
+			file: "internal/generated/separator_consumegen.go",
+			g:    generator{typeName: "Separator", kind: "until", pkg: "generated", keys: []string{",", ";", "::", "->", "\r\n", "\n"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			expected, err := os.ReadFile(tt.file)
+			assert.NoError(t, err)
+			src, err := tt.g.generate()
+			assert.NoError(t, err)
+			assert.Equal(t, string(expected), string(src), "run go generate in internal/generated")
+		})
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		g    generator
+	}{
+		{name: "Unexported type", g: generator{typeName: "keyword", kind: "prefix", pkg: "p", keys: []string{"a"}}},
+		{name: "Missing package", g: generator{typeName: "Keyword", kind: "prefix", keys: []string{"a"}}},
+		{name: "Unknown kind", g: generator{typeName: "Keyword", kind: "suffix", pkg: "p", keys: []string{"a"}}},
+		{name: "No keys", g: generator{typeName: "Keyword", kind: "prefix", pkg: "p"}},
+		{name: "Empty key", g: generator{typeName: "Keyword", kind: "prefix", pkg: "p", keys: []string{"a", ""}}},
+		{name: "Invalid UTF-8", g: generator{typeName: "Keyword", kind: "until", pkg: "p", keys: []string{"\xff"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.g.generate()
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,6 @@
+// Package generated holds matchers written by consumegen, which its tests compare with strconsume.
+package generated
+
+//go:generate go run ../.. -type Keyword GET HEAD POST PUT PATCH DELETE OPTIONS /api /api/v1 über
+//go:generate go run ../.. -type Separator -kind until , ; :: -> "\r\n" "\n"
+//go:generate go run ../.. -type Operator -kind until = == =>
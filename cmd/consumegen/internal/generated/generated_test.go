@@ -0,0 +1,148 @@
+package generated
+
+import (
+	"bufio"
+	"math/rand"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/arran4/go-consume"
+	"github.com/arran4/go-consume/strconsume"
+	"github.com/stretchr/testify/assert"
+)
+
+// randomInput returns text made mostly of pieces of the keys.
+func randomInput(rng *rand.Rand, keys []string) string {
+	pieces := append([]string{"a", " ", "é", "\xff", "P", "/", "-", ":"}, keys...)
+	var b strings.Builder
+	for range rng.Intn(8) {
+		p := pieces[rng.Intn(len(pieces))]
+		b.WriteString(p[:rng.Intn(len(p)+1)])
+	}
+	return b.String()
+}
+
+// split returns the tokens and error of a bufio.Scanner reading text one byte at a time.
+func split(text string, split bufio.SplitFunc) ([]string, error) {
+	s := bufio.NewScanner(iotest.OneByteReader(strings.NewReader(text)))
+	s.Split(split)
+	var tokens []string
+	for s.Scan() {
+		tokens = append(tokens, s.Text())
+	}
+	return tokens, s.Err()
+}
+
+func TestKeyword_MatchesPrefixConsumer(t *testing.T) {
+	pc := strconsume.NewPrefixConsumer(keywordKeys...)
+	var kw Keyword
+	rng := rand.New(rand.NewSource(1))
+	for range 5000 {
+		text := randomInput(rng, keywordKeys)
+
+		expected, expectedOk := pc.LongestPrefix(text)
+		got, gotOk := kw.LongestPrefix(text)
+		assert.Equal(t, expectedOk, gotOk, "LongestPrefix(%q)", text)
+		assert.Equal(t, expected, got, "LongestPrefix(%q)", text)
+
+		b, m, r, ok := pc.Consume(text)
+		gb, gm, gr, gok := kw.Consume(text)
+		assert.Equal(t, []any{b, m, r, ok}, []any{gb, gm, gr, gok}, "Consume(%q)", text)
+
+		tokens, err := split(text, pc.SplitFunc())
+		gotTokens, gotErr := split(text, kw.SplitFunc())
+		assert.Equal(t, tokens, gotTokens, "SplitFunc(%q)", text)
+		assert.Equal(t, err, gotErr, "SplitFunc(%q)", text)
+	}
+}
+
+func TestKeyword_Options(t *testing.T) {
+	var kw Keyword
+	matched, ok := kw.LongestPrefix("get /", consume.CaseInsensitive(true))
+	assert.True(t, ok)
+	assert.Equal(t, "get", matched)
+
+	tokens, err := split("GETxPUT", kw.SplitFunc(consume.NoMatchToken))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"GET", "x", "PUT"}, tokens)
+}
+
+func TestSeparator_MatchesUntilConsumer(t *testing.T) {
+	cu := strconsume.NewUntilConsumer(separatorKeys...)
+	var sep Separator
+	rng := rand.New(rand.NewSource(1))
+	for range 5000 {
+		text := randomInput(rng, separatorKeys)
+
+		b, m, r, ok := cu.Consume(text)
+		gb, gm, gr, gok := sep.Consume(text)
+		assert.Equal(t, []any{b, m, r, ok}, []any{gb, gm, gr, gok}, "Consume(%q)", text)
+
+		tokens, err := split(text, cu.SplitFunc())
+		gotTokens, gotErr := split(text, sep.SplitFunc())
+		assert.Equal(t, tokens, gotTokens, "SplitFunc(%q)", text)
+		assert.Equal(t, err, gotErr, "SplitFunc(%q)", text)
+	}
+}
+
+func TestOperator_MatchesUntilConsumer(t *testing.T) {
+	// Keys which are prefixes of others must wait for the rest of the input before splitting.
+	cu := strconsume.NewUntilConsumer(operatorKeys...)
+	var op Operator
+	tokens, err := split("a==b=c", op.SplitFunc())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, tokens)
+
+	rng := rand.New(rand.NewSource(1))
+	for range 5000 {
+		text := randomInput(rng, operatorKeys)
+
+		b, m, r, ok := cu.Consume(text)
+		gb, gm, gr, gok := op.Consume(text)
+		assert.Equal(t, []any{b, m, r, ok}, []any{gb, gm, gr, gok}, "Consume(%q)", text)
+
+		tokens, err := split(text, cu.SplitFunc())
+		gotTokens, gotErr := split(text, op.SplitFunc())
+		assert.Equal(t, tokens, gotTokens, "SplitFunc(%q)", text)
+		assert.Equal(t, err, gotErr, "SplitFunc(%q)", text)
+	}
+}
+
+func TestSeparator_Options(t *testing.T) {
+	var sep Separator
+	matched, separator, remaining, ok := sep.Consume("a,b", consume.Inclusive(true))
+	assert.True(t, ok)
+	assert.Equal(t, "a,", matched)
+	assert.Equal(t, ",", separator)
+	assert.Equal(t, "b", remaining)
+}
+
+func BenchmarkKeyword_LongestPrefix(b *testing.B) {
+	inputs := []string{"GET /index.html", "OPTIONS *", "/api/v1/users", "über", "PATCHY", "nothing"}
+	b.Run("Generated", func(b *testing.B) {
+		var kw Keyword
+		for i := 0; i < b.N; i++ {
+			kw.LongestPrefix(inputs[i%len(inputs)])
+		}
+	})
+	b.Run("PrefixConsumer", func(b *testing.B) {
+		pc := strconsume.NewPrefixConsumer(keywordKeys...)
+		for i := 0; i < b.N; i++ {
+			pc.LongestPrefix(inputs[i%len(inputs)])
+		}
+	})
+}
+
+func TestSeparator_Iterator(t *testing.T) {
+	cu := strconsume.NewUntilConsumer(separatorKeys...)
+	text := "a,b::c->d\r\ne;"
+	var expected, got []string
+	for m, s := range cu.Iterator(text) {
+		expected = append(expected, m, s)
+	}
+	for m, s := range consume.Iterator(Separator{}, text) {
+		got = append(got, m, s)
+	}
+	assert.Equal(t, expected, got)
+}
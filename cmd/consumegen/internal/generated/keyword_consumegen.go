@@ -0,0 +1,180 @@
+// Code generated by consumegen; DO NOT EDIT.
+
+package generated
+
+import (
+	"bufio"
+	"sync"
+
+	"github.com/arran4/go-consume"
+	"github.com/arran4/go-consume/strconsume"
+)
+
+// Keyword matches its keys as a strconsume.PrefixConsumer of them would. Calls with options use such a
+// consumer, built the first time one is made.
+type Keyword struct{}
+
+var keywordConsumer = sync.OnceValue(func() *strconsume.PrefixConsumer {
+	return strconsume.NewPrefixConsumer(keywordKeys...)
+})
+
+// LongestPrefix returns the longest key which is a prefix of text, as strconsume.PrefixConsumer.LongestPrefix
+// does.
+func (Keyword) LongestPrefix(text string, ops ...any) (string, bool) {
+	if len(ops) > 0 {
+		return keywordConsumer().LongestPrefix(text, ops...)
+	}
+	if n, _ := keywordMatch(text); n > 0 {
+		return text[:n], true
+	}
+	return "", false
+}
+
+// Consume finds the first key in from, as strconsume.PrefixConsumer.Consume does.
+func (Keyword) Consume(from string, ops ...any) (string, string, string, bool) {
+	if len(ops) > 0 {
+		return keywordConsumer().Consume(from, ops...)
+	}
+	for i := 0; i < len(from); i++ {
+		if n, _ := keywordMatch(from[i:]); n > 0 {
+			return from[:i], from[i : i+n], from[i:], true
+		}
+	}
+	return "", "", from, false
+}
+
+// SplitFunc returns a bufio.SplitFunc which yields each consecutive key at the start of the data, as
+// strconsume.PrefixConsumer.SplitFunc does.
+func (Keyword) SplitFunc(ops ...any) bufio.SplitFunc {
+	if len(ops) > 0 {
+		return keywordConsumer().SplitFunc(ops...)
+	}
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+		n, more := keywordMatch(data)
+		if more && !atEOF {
+			return 0, nil, nil
+		}
+		if n == 0 {
+			return 0, nil, consume.ErrNoMatch
+		}
+		return n, data[:n], nil
+	}
+}
+
+var keywordKeys = []string{
+	"/api",
+	"/api/v1",
+	"DELETE",
+	"GET",
+	"HEAD",
+	"OPTIONS",
+	"PATCH",
+	"POST",
+	"PUT",
+	"über",
+}
+
+func keywordMatch[T string | []byte](s T) (n int, more bool) {
+	if len(s) == 0 {
+		return n, true
+	}
+	switch s[0] {
+	case '/':
+		if len(s) < 4 {
+			return n, string(s[1:]) == "api"[:len(s)-1]
+		}
+		if string(s[1:4]) != "api" {
+			return n, false
+		}
+		n = 4
+		if len(s) == 4 {
+			return n, true
+		}
+		switch s[4] {
+		case '/':
+			if len(s) < 7 {
+				return n, string(s[5:]) == "v1"[:len(s)-5]
+			}
+			if string(s[5:7]) != "v1" {
+				return n, false
+			}
+			return 7, false
+		}
+		return n, false
+	case 'D':
+		if len(s) < 6 {
+			return n, string(s[1:]) == "ELETE"[:len(s)-1]
+		}
+		if string(s[1:6]) != "ELETE" {
+			return n, false
+		}
+		return 6, false
+	case 'G':
+		if len(s) < 3 {
+			return n, string(s[1:]) == "ET"[:len(s)-1]
+		}
+		if string(s[1:3]) != "ET" {
+			return n, false
+		}
+		return 3, false
+	case 'H':
+		if len(s) < 4 {
+			return n, string(s[1:]) == "EAD"[:len(s)-1]
+		}
+		if string(s[1:4]) != "EAD" {
+			return n, false
+		}
+		return 4, false
+	case 'O':
+		if len(s) < 7 {
+			return n, string(s[1:]) == "PTIONS"[:len(s)-1]
+		}
+		if string(s[1:7]) != "PTIONS" {
+			return n, false
+		}
+		return 7, false
+	case 'P':
+		if len(s) == 1 {
+			return n, true
+		}
+		switch s[1] {
+		case 'A':
+			if len(s) < 5 {
+				return n, string(s[2:]) == "TCH"[:len(s)-2]
+			}
+			if string(s[2:5]) != "TCH" {
+				return n, false
+			}
+			return 5, false
+		case 'O':
+			if len(s) < 4 {
+				return n, string(s[2:]) == "ST"[:len(s)-2]
+			}
+			if string(s[2:4]) != "ST" {
+				return n, false
+			}
+			return 4, false
+		case 'U':
+			if len(s) < 3 {
+				return n, string(s[2:]) == "T"[:len(s)-2]
+			}
+			if s[2] != 'T' {
+				return n, false
+			}
+			return 3, false
+		}
+		return n, false
+	case 0xc3:
+		if len(s) < 5 {
+			return n, string(s[1:]) == "\xbcber"[:len(s)-1]
+		}
+		if string(s[1:5]) != "\xbcber" {
+			return n, false
+		}
+		return 5, false
+	}
+	return n, false
+}
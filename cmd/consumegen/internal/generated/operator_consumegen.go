@@ -0,0 +1,84 @@
+// Code generated by consumegen; DO NOT EDIT.
+
+package generated
+
+import (
+	"bufio"
+	"sync"
+
+	"github.com/arran4/go-consume/strconsume"
+)
+
+// Operator splits on its keys as a strconsume.UntilConsumer of them would. Calls with options use such a
+// consumer, built the first time one is made.
+type Operator struct{}
+
+var operatorConsumer = sync.OnceValue(func() strconsume.UntilConsumer {
+	return strconsume.NewUntilConsumer(operatorKeys...)
+})
+
+// Consume finds the first key in from, as strconsume.UntilConsumer.Consume does.
+func (Operator) Consume(from string, ops ...any) (string, string, string, bool) {
+	if len(ops) > 0 {
+		return operatorConsumer().Consume(from, ops...)
+	}
+	for i := 0; i < len(from); i++ {
+		if n, _ := operatorMatch(from[i:]); n > 0 {
+			return from[:i], from[i : i+n], from[i:], true
+		}
+	}
+	return "", "", from, false
+}
+
+// SplitFunc returns a bufio.SplitFunc which yields the text between keys, as
+// strconsume.UntilConsumer.SplitFunc does.
+func (Operator) SplitFunc(ops ...any) bufio.SplitFunc {
+	if len(ops) > 0 {
+		return operatorConsumer().SplitFunc(ops...)
+	}
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+		for i := range data {
+			n, more := operatorMatch(data[i:])
+			if more && !atEOF {
+				// A longer key may yet match here.
+				return 0, nil, nil
+			}
+			if n > 0 {
+				return i + n, data[:i], nil
+			}
+		}
+		if atEOF {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+}
+
+var operatorKeys = []string{
+	"=",
+	"==",
+	"=>",
+}
+
+func operatorMatch[T string | []byte](s T) (n int, more bool) {
+	if len(s) < 1 {
+		return n, string(s[0:]) == "="[:len(s)-0]
+	}
+	if s[0] != '=' {
+		return n, false
+	}
+	n = 1
+	if len(s) == 1 {
+		return n, true
+	}
+	switch s[1] {
+	case '=':
+		return 2, false
+	case '>':
+		return 2, false
+	}
+	return n, false
+}
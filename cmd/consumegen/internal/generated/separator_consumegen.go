@@ -0,0 +1,106 @@
+// Code generated by consumegen; DO NOT EDIT.
+
+package generated
+
+import (
+	"bufio"
+	"sync"
+
+	"github.com/arran4/go-consume/strconsume"
+)
+
+// Separator splits on its keys as a strconsume.UntilConsumer of them would. Calls with options use such a
+// consumer, built the first time one is made.
+type Separator struct{}
+
+var separatorConsumer = sync.OnceValue(func() strconsume.UntilConsumer {
+	return strconsume.NewUntilConsumer(separatorKeys...)
+})
+
+// Consume finds the first key in from, as strconsume.UntilConsumer.Consume does.
+func (Separator) Consume(from string, ops ...any) (string, string, string, bool) {
+	if len(ops) > 0 {
+		return separatorConsumer().Consume(from, ops...)
+	}
+	for i := 0; i < len(from); i++ {
+		if n, _ := separatorMatch(from[i:]); n > 0 {
+			return from[:i], from[i : i+n], from[i:], true
+		}
+	}
+	return "", "", from, false
+}
+
+// SplitFunc returns a bufio.SplitFunc which yields the text between keys, as
+// strconsume.UntilConsumer.SplitFunc does.
+func (Separator) SplitFunc(ops ...any) bufio.SplitFunc {
+	if len(ops) > 0 {
+		return separatorConsumer().SplitFunc(ops...)
+	}
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+		for i := range data {
+			n, more := separatorMatch(data[i:])
+			if more && !atEOF {
+				// A longer key may yet match here.
+				return 0, nil, nil
+			}
+			if n > 0 {
+				return i + n, data[:i], nil
+			}
+		}
+		if atEOF {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+}
+
+var separatorKeys = []string{
+	"\n",
+	"\r\n",
+	",",
+	"->",
+	"::",
+	";",
+}
+
+func separatorMatch[T string | []byte](s T) (n int, more bool) {
+	if len(s) == 0 {
+		return n, true
+	}
+	switch s[0] {
+	case '\n':
+		return 1, false
+	case '\r':
+		if len(s) < 2 {
+			return n, string(s[1:]) == "\n"[:len(s)-1]
+		}
+		if s[1] != '\n' {
+			return n, false
+		}
+		return 2, false
+	case ',':
+		return 1, false
+	case '-':
+		if len(s) < 2 {
+			return n, string(s[1:]) == ">"[:len(s)-1]
+		}
+		if s[1] != '>' {
+			return n, false
+		}
+		return 2, false
+	case ':':
+		if len(s) < 2 {
+			return n, string(s[1:]) == ":"[:len(s)-1]
+		}
+		if s[1] != ':' {
+			return n, false
+		}
+		return 2, false
+	case ';':
+		return 1, false
+	}
+	return n, false
+}
@@ -0,0 +1,80 @@
+// Command consumegen writes a Go source file containing a matcher for a fixed set of keys. The matcher is a
+// nest of switch statements over the input bytes rather than a trie built at run time, and has the same
+// Consume, LongestPrefix and SplitFunc methods as strconsume.PrefixConsumer, or the same Consume and SplitFunc
+// methods as strconsume.UntilConsumer.
+//
+// Usage:
+//
+//	consumegen -type Name [-kind prefix|until] [-package name] [-o file] [-keys file] [key ...]
+//
+// The keys are the arguments, followed by the lines of the -keys file, if any. It is meant to be run by
+// go generate, for example:
+//
+//	//go:generate go run github.com/arran4/go-consume/cmd/consumegen -type Keyword GET POST PUT DELETE
+//
+// Calls without options run the generated code. Calls with options are passed to a strconsume consumer of the
+// same keys, built the first time one is made, so the results are always the same as those of strconsume.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	var g generator
+	flag.StringVar(&g.typeName, "type", "", "name of the generated `type` (required)")
+	flag.StringVar(&g.kind, "kind", "prefix", "the consumer to generate, prefix or until")
+	flag.StringVar(&g.pkg, "package", os.Getenv("GOPACKAGE"), "package `name` of the generated file")
+	output := flag.String("o", "", "output `file` (default the lower case type name with _consumegen.go)")
+	keysFile := flag.String("keys", "", "read further keys from `file`, one per line, ignoring blank lines")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: consumegen -type Name [flags] [key ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	g.keys = flag.Args()
+	if *keysFile != "" {
+		keys, err := readKeys(*keysFile)
+		if err != nil {
+			fail(err)
+		}
+		g.keys = append(g.keys, keys...)
+	}
+	src, err := g.generate()
+	if err != nil {
+		fail(err)
+	}
+	if *output == "" {
+		*output = strings.ToLower(g.typeName) + "_consumegen.go"
+	}
+	if err := os.WriteFile(*output, src, 0o644); err != nil {
+		fail(err)
+	}
+}
+
+// readKeys returns the lines of a file, other than blank ones.
+func readKeys(name string) ([]string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var keys []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if s.Text() != "" {
+			keys = append(keys, s.Text())
+		}
+	}
+	return keys, s.Err()
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "consumegen: %v\n", err)
+	os.Exit(1)
+}
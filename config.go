@@ -0,0 +1,217 @@
+package consume
+
+// Config is a set of options which have been validated ahead of time, so that consumers do not need to
+// re-parse them on every call. Create one with Compile, or pass it as an option to apply all of its settings.
+// Every setting except MustBePrecededBy and MustBeFollowedBy can be encoded as JSON.
+type Config struct {
+	Inclusive                  bool             `json:"inclusive,omitempty"`
+	StartOffset                int              `json:"startOffset,omitempty"`
+	Ignore0PositionMatch       bool             `json:"ignore0PositionMatch,omitempty"`
+	MustBePrecededBy           MustBePrecededBy `json:"-"`
+	MustBeFollowedBy           MustBeFollowedBy `json:"-"`
+	MustBeAtEnd                bool             `json:"mustBeAtEnd,omitempty"`
+	CaseInsensitive            bool             `json:"caseInsensitive,omitempty"`
+	Normalization              Normalization    `json:"normalization,omitempty"`
+	MustMatchWholeString       bool             `json:"mustMatchWholeString,omitempty"`
+	ConsumeRemainingIfNotFound bool             `json:"consumeRemainingIfNotFound,omitempty"`
+	Escapes                    []string         `json:"escapes,omitempty"`
+	Encasings                  []Encasing       `json:"encasings,omitempty"`
+	EscapeBreaksEncasing       bool             `json:"escapeBreaksEncasing,omitempty"`
+	Unescape                   bool             `json:"unescape,omitempty"`
+	StripEncasing              bool             `json:"stripEncasing,omitempty"`
+	Strict                     bool             `json:"strict,omitempty"`
+	CollapseRuns               bool             `json:"collapseRuns,omitempty"`
+	WordBoundary               bool             `json:"wordBoundary,omitempty"`
+	NoMatch                    NoMatch          `json:"noMatch,omitempty"`
+}
+
+// Compile validates the given options and returns them as a Config.
+func Compile(ops ...any) (*Config, error) {
+	c := &Config{}
+	if err := c.Apply(ops...); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Apply applies the given options on top of the current settings. Escape and Encasing options add to the
+// existing ones, a *Config replaces all settings. Unrecognised options and a nil *Config are ignored.
+func (c *Config) Apply(ops ...any) error {
+	for _, op := range ops {
+		switch v := op.(type) {
+		case Inclusive:
+			c.Inclusive = bool(v)
+		case StartOffset:
+			if v < 0 {
+				return ErrNegativeStartOffset
+			}
+			c.StartOffset = int(v)
+		case Ignore0PositionMatch:
+			c.Ignore0PositionMatch = bool(v)
+		case MustBePrecededBy:
+			c.MustBePrecededBy = v
+		case MustBeFollowedBy:
+			c.MustBeFollowedBy = v
+		case MustBeAtEnd:
+			c.MustBeAtEnd = bool(v)
+		case CaseInsensitive:
+			c.CaseInsensitive = bool(v)
+		case Normalization:
+			if v < NoNormalization || v > NFKD {
+				return ErrUnknownNormalization
+			}
+			c.Normalization = v
+		case MustMatchWholeString:
+			c.MustMatchWholeString = bool(v)
+		case ConsumeRemainingIfNotFound:
+			c.ConsumeRemainingIfNotFound = bool(v)
+		case Escape:
+			if len(v) == 0 {
+				return ErrEmptyEscape
+			}
+			// The full slice expression makes append copy, so configs never share a backing array.
+			c.Escapes = append(c.Escapes[:len(c.Escapes):len(c.Escapes)], string(v))
+		case Encasing:
+			if len(v.Start) == 0 {
+				return ErrEmptyEncasingStart
+			}
+			c.Encasings = append(c.Encasings[:len(c.Encasings):len(c.Encasings)], v)
+		case EscapeBreaksEncasing:
+			c.EscapeBreaksEncasing = bool(v)
+		case Unescape:
+			c.Unescape = bool(v)
+		case StripEncasing:
+			c.StripEncasing = bool(v)
+		case Strict:
+			c.Strict = bool(v)
+		case CollapseRuns:
+			c.CollapseRuns = bool(v)
+		case WordBoundary:
+			c.WordBoundary = bool(v)
+		case NoMatch:
+			if v < NoMatchError || v > NoMatchToken {
+				return ErrUnknownNoMatch
+			}
+			c.NoMatch = v
+		case *Config:
+			if v == nil {
+				continue
+			}
+			if err := v.Validate(); err != nil {
+				return err
+			}
+			*c = *v
+			c.Escapes = c.Escapes[:len(c.Escapes):len(c.Escapes)]
+			c.Encasings = c.Encasings[:len(c.Encasings):len(c.Encasings)]
+		}
+	}
+	return nil
+}
+
+// Validate reports whether the settings are usable. It is only needed for a Config built by hand.
+func (c *Config) Validate() error {
+	for _, esc := range c.Escapes {
+		if len(esc) == 0 {
+			return ErrEmptyEscape
+		}
+	}
+	for _, enc := range c.Encasings {
+		if len(enc.Start) == 0 {
+			return ErrEmptyEncasingStart
+		}
+	}
+	if c.StartOffset < 0 {
+		return ErrNegativeStartOffset
+	}
+	if c.Normalization < NoNormalization || c.Normalization > NFKD {
+		return ErrUnknownNormalization
+	}
+	if c.NoMatch < NoMatchError || c.NoMatch > NoMatchToken {
+		return ErrUnknownNoMatch
+	}
+	return nil
+}
+
+// Options returns the settings which differ from the zero Config as option values.
+func (c *Config) Options() []any {
+	var ops []any
+	if c.Inclusive {
+		ops = append(ops, Inclusive(true))
+	}
+	if c.StartOffset != 0 {
+		ops = append(ops, StartOffset(c.StartOffset))
+	}
+	if c.Ignore0PositionMatch {
+		ops = append(ops, Ignore0PositionMatch(true))
+	}
+	if c.MustBePrecededBy != nil {
+		ops = append(ops, c.MustBePrecededBy)
+	}
+	if c.MustBeFollowedBy != nil {
+		ops = append(ops, c.MustBeFollowedBy)
+	}
+	if c.MustBeAtEnd {
+		ops = append(ops, MustBeAtEnd(true))
+	}
+	if c.CaseInsensitive {
+		ops = append(ops, CaseInsensitive(true))
+	}
+	if c.Normalization != NoNormalization {
+		ops = append(ops, c.Normalization)
+	}
+	if c.MustMatchWholeString {
+		ops = append(ops, MustMatchWholeString(true))
+	}
+	if c.ConsumeRemainingIfNotFound {
+		ops = append(ops, ConsumeRemainingIfNotFound(true))
+	}
+	for _, esc := range c.Escapes {
+		ops = append(ops, Escape(esc))
+	}
+	for _, enc := range c.Encasings {
+		ops = append(ops, enc)
+	}
+	if c.EscapeBreaksEncasing {
+		ops = append(ops, EscapeBreaksEncasing(true))
+	}
+	if c.Unescape {
+		ops = append(ops, Unescape(true))
+	}
+	if c.StripEncasing {
+		ops = append(ops, StripEncasing(true))
+	}
+	if c.Strict {
+		ops = append(ops, Strict(true))
+	}
+	if c.CollapseRuns {
+		ops = append(ops, CollapseRuns(true))
+	}
+	if c.WordBoundary {
+		ops = append(ops, WordBoundary(true))
+	}
+	if c.NoMatch != NoMatchError {
+		ops = append(ops, c.NoMatch)
+	}
+	return ops
+}
+
+// CheckSupported returns an *UnsupportedOptionError for the first option in ops which supported rejects.
+// The settings of a *Config are checked individually, and a nil *Config is skipped. consumer names the consumer
+// in the error.
+func CheckSupported(consumer string, supported func(op any) bool, ops ...any) error {
+	for _, op := range ops {
+		if c, ok := op.(*Config); ok {
+			if c == nil {
+				continue
+			}
+			if err := CheckSupported(consumer, supported, c.Options()...); err != nil {
+				return err
+			}
+			continue
+		}
+		if !supported(op) {
+			return &UnsupportedOptionError{Consumer: consumer, Option: op}
+		}
+	}
+	return nil
+}
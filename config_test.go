@@ -0,0 +1,139 @@
+package consume
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCompile(t *testing.T) {
+	t.Run("Options", func(t *testing.T) {
+		cfg, err := Compile(Inclusive(true), StartOffset(3), Escape("\\"), Encasing{Start: "(", End: ")"}, Escape("ESC"))
+		if err != nil {
+			t.Fatalf("Compile() error = %v", err)
+		}
+		if !cfg.Inclusive || cfg.StartOffset != 3 {
+			t.Errorf("Compile() = %+v, expected Inclusive and StartOffset 3", cfg)
+		}
+		if len(cfg.Escapes) != 2 || cfg.Escapes[0] != "\\" || cfg.Escapes[1] != "ESC" {
+			t.Errorf("Compile() Escapes = %q", cfg.Escapes)
+		}
+		if len(cfg.Encasings) != 1 {
+			t.Errorf("Compile() Encasings = %v", cfg.Encasings)
+		}
+	})
+
+	t.Run("Empty escape", func(t *testing.T) {
+		if _, err := Compile(Escape("")); !errors.Is(err, ErrEmptyEscape) {
+			t.Errorf("Compile() error = %v, expected %v", err, ErrEmptyEscape)
+		}
+	})
+
+	t.Run("Empty encasing start", func(t *testing.T) {
+		if _, err := Compile(Encasing{End: ")"}); !errors.Is(err, ErrEmptyEncasingStart) {
+			t.Errorf("Compile() error = %v, expected %v", err, ErrEmptyEncasingStart)
+		}
+	})
+
+	t.Run("Unknown normalization", func(t *testing.T) {
+		if _, err := Compile(Normalization(9)); !errors.Is(err, ErrUnknownNormalization) {
+			t.Errorf("Compile() error = %v, expected %v", err, ErrUnknownNormalization)
+		}
+		if _, err := Compile(&Config{Normalization: -1}); !errors.Is(err, ErrUnknownNormalization) {
+			t.Errorf("Compile() error = %v, expected %v", err, ErrUnknownNormalization)
+		}
+	})
+
+	t.Run("Negative StartOffset", func(t *testing.T) {
+		if _, err := Compile(StartOffset(-1)); !errors.Is(err, ErrNegativeStartOffset) {
+			t.Errorf("Compile() error = %v, expected %v", err, ErrNegativeStartOffset)
+		}
+		if _, err := Compile(&Config{StartOffset: -27}); !errors.Is(err, ErrNegativeStartOffset) {
+			t.Errorf("Compile() error = %v, expected %v", err, ErrNegativeStartOffset)
+		}
+	})
+
+	t.Run("Unknown NoMatch", func(t *testing.T) {
+		if _, err := Compile(NoMatch(7)); !errors.Is(err, ErrUnknownNoMatch) {
+			t.Errorf("Compile() error = %v, expected %v", err, ErrUnknownNoMatch)
+		}
+		if _, err := Compile(&Config{NoMatch: -1}); !errors.Is(err, ErrUnknownNoMatch) {
+			t.Errorf("Compile() error = %v, expected %v", err, ErrUnknownNoMatch)
+		}
+	})
+
+	t.Run("Config as option", func(t *testing.T) {
+		base, err := Compile(CaseInsensitive(true), Escape("\\"))
+		if err != nil {
+			t.Fatalf("Compile() error = %v", err)
+		}
+		cfg, err := Compile(Inclusive(true), base)
+		if err != nil {
+			t.Fatalf("Compile() error = %v", err)
+		}
+		if cfg.Inclusive || !cfg.CaseInsensitive {
+			t.Errorf("Compile() = %+v, expected the settings of base", cfg)
+		}
+	})
+
+	t.Run("Nil config as option", func(t *testing.T) {
+		var nilConfig *Config
+		cfg, err := Compile(Inclusive(true), nilConfig)
+		if err != nil {
+			t.Fatalf("Compile() error = %v", err)
+		}
+		if !cfg.Inclusive {
+			t.Errorf("Compile() = %+v, expected a nil config to be ignored", cfg)
+		}
+		if err := CheckSupported("Test", func(any) bool { return false }, nilConfig); err != nil {
+			t.Errorf("CheckSupported() error = %v, expected a nil config to be skipped", err)
+		}
+	})
+
+	t.Run("Invalid config as option", func(t *testing.T) {
+		if _, err := Compile(&Config{Escapes: []string{""}}); !errors.Is(err, ErrEmptyEscape) {
+			t.Errorf("Compile() error = %v, expected %v", err, ErrEmptyEscape)
+		}
+	})
+}
+
+func TestConfig_Apply_DoesNotShareEscapes(t *testing.T) {
+	base := &Config{Escapes: make([]string, 1, 4)}
+	base.Escapes[0] = "\\"
+	a, b := *base, *base
+	if err := a.Apply(Escape("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Apply(Escape("b")); err != nil {
+		t.Fatal(err)
+	}
+	if a.Escapes[1] != "a" || b.Escapes[1] != "b" {
+		t.Errorf("Apply() shared escapes: %q %q", a.Escapes, b.Escapes)
+	}
+}
+
+func TestCheckSupported(t *testing.T) {
+	onlyInclusive := func(op any) bool {
+		_, ok := op.(Inclusive)
+		return ok
+	}
+
+	if err := CheckSupported("Test", onlyInclusive, Inclusive(true)); err != nil {
+		t.Errorf("CheckSupported() error = %v", err)
+	}
+
+	err := CheckSupported("Test", onlyInclusive, Inclusive(true), MustBeAtEnd(true))
+	var unsupported *UnsupportedOptionError
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("CheckSupported() error = %v, expected an UnsupportedOptionError", err)
+	}
+	if unsupported.Option != MustBeAtEnd(true) {
+		t.Errorf("CheckSupported() option = %v", unsupported.Option)
+	}
+	if err.Error() != "consume: Test does not support option consume.MustBeAtEnd" {
+		t.Errorf("CheckSupported() message = %q", err.Error())
+	}
+
+	if err := CheckSupported("Test", onlyInclusive, &Config{Inclusive: true, CaseInsensitive: true}); err == nil {
+		t.Errorf("CheckSupported() accepted an unsupported Config setting")
+	}
+}
@@ -0,0 +1,96 @@
+package consume
+
+import (
+	"bufio"
+	"iter"
+	"strings"
+)
+
+// Consumer finds a match in a string. strconsume.UntilConsumer, *strconsume.PrefixConsumer and the types
+// written by consumegen all implement it, so helpers can be written once for any of them.
+//
+// Consume returns the text before the match, or up to the end of it with Inclusive, the match itself, the
+// remaining text and whether there was a match. Without Inclusive the remaining text starts at the match, and
+// with it, after the match. When there is no match it returns "", "", from and false.
+type Consumer interface {
+	Consume(from string, ops ...any) (string, string, string, bool)
+}
+
+// Iterator returns the pieces of from between the matches of c, each with the match which ends it. The last
+// piece is the text after the final match, with an empty match. It only uses Consume, so it yields the same
+// pieces for every kind of consumer, even one whose own Iterator method means something else, such as a
+// PrefixConsumer's, which yields the keys at the start of from.
+//
+// StartOffset only applies to the first call of Consume. Inclusive makes each piece include its match, but
+// only when passed in ops, as Iterator cannot see options already applied to c. Consume is always called
+// without Inclusive and the match added to the piece afterwards, so c need not support it.
+func Iterator(c Consumer, from string, ops ...any) iter.Seq2[string, string] {
+	inclusive, stepOps := stepOptions(ops)
+	return func(yield func(string, string) bool) {
+		step := stepOps
+		for {
+			matched, separator, remaining, found := c.Consume(from, step...)
+			// From the second call on, StartOffset no longer applies.
+			step = append(stepOps[:len(stepOps):len(stepOps)], StartOffset(0))
+			if found {
+				matched, remaining = splitMatch(matched, separator, remaining, inclusive)
+			}
+			// A match which consumes nothing would repeat forever.
+			if !found || len(remaining) == len(from) {
+				yield(from, "")
+				return
+			}
+			if !yield(matched, separator) {
+				return
+			}
+			from = remaining
+		}
+	}
+}
+
+// SplitFunc returns a bufio.SplitFunc which yields the text between the matches of c, as Iterator does. It
+// returns ErrNoProgress if c matches without consuming input.
+//
+// Each call converts the data to a string, so where a consumer's own SplitFunc yields the same tokens, such as
+// an UntilConsumer's, it is faster.
+func SplitFunc(c Consumer, ops ...any) bufio.SplitFunc {
+	inclusive, stepOps := stepOptions(ops)
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+		from := string(data)
+		matched, separator, remaining, found := c.Consume(from, stepOps...)
+		if found {
+			matched, remaining = splitMatch(matched, separator, remaining, inclusive)
+			advance = len(from) - len(remaining)
+			if advance == 0 {
+				return 0, nil, ErrNoProgress
+			}
+			return advance, []byte(matched), nil
+		}
+		if atEOF {
+			return len(data), data, nil
+		}
+		return 0, nil, nil
+	}
+}
+
+// splitMatch returns the piece which ends at the match Consume found, with the match if inclusive is set, and
+// the text after the match. Consume is asked not to be inclusive, which is also what a consumer ignoring
+// Inclusive does, so matched is the text before the match and remaining starts at it.
+func splitMatch(matched, separator, remaining string, inclusive bool) (string, string) {
+	if inclusive {
+		matched += separator
+	}
+	return matched, strings.TrimPrefix(remaining, separator)
+}
+
+// stepOptions returns whether ops ask for Inclusive, and ops with Inclusive unset, so that the remaining text
+// of each match starts at it whether or not the consumer supports Inclusive.
+func stepOptions(ops []any) (bool, []any) {
+	var cfg Config
+	// Invalid options are left for the consumer to report.
+	_ = cfg.Apply(ops...)
+	return cfg.Inclusive, append(ops[:len(ops):len(ops)], Inclusive(false))
+}
@@ -0,0 +1,155 @@
+package consume
+
+import (
+	"bufio"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// indexConsumer is a Consumer with neither Iterator nor SplitFunc, which finds sep with strings.Index.
+type indexConsumer struct {
+	sep string
+}
+
+func (ic indexConsumer) Consume(from string, ops ...any) (string, string, string, bool) {
+	cfg, err := Compile(ops...)
+	if err != nil {
+		panic(err)
+	}
+	offset := cfg.StartOffset
+	if cfg.Ignore0PositionMatch && offset == 0 && len(from) > 0 {
+		offset = 1
+	}
+	i := strings.Index(from[offset:], ic.sep)
+	if i < 0 {
+		return "", "", from, false
+	}
+	i += offset
+	if cfg.Inclusive {
+		return from[:i+len(ic.sep)], ic.sep, from[i+len(ic.sep):], true
+	}
+	return from[:i], ic.sep, from[i:], true
+}
+
+// exclusiveConsumer is an indexConsumer which ignores Inclusive, so remaining always starts at the match.
+type exclusiveConsumer struct {
+	indexConsumer
+}
+
+func (ec exclusiveConsumer) Consume(from string, ops ...any) (string, string, string, bool) {
+	return ec.indexConsumer.Consume(from, append(ops[:len(ops):len(ops)], Inclusive(false))...)
+}
+
+type piece struct {
+	matched, separator string
+}
+
+func collectPieces(seq func(yield func(string, string) bool)) []piece {
+	var pieces []piece
+	for m, s := range seq {
+		pieces = append(pieces, piece{m, s})
+	}
+	return pieces
+}
+
+func TestIterator(t *testing.T) {
+	tests := []struct {
+		name     string
+		sep      string
+		input    string
+		ops      []any
+		expected []piece
+	}{
+		{name: "Split", sep: ",", input: "a,b,,c", expected: []piece{{"a", ","}, {"b", ","}, {"", ","}, {"c", ""}}},
+		{name: "Trailing separator", sep: "::", input: "a::", expected: []piece{{"a", "::"}, {"", ""}}},
+		{name: "No match", sep: ",", input: "abc", expected: []piece{{"abc", ""}}},
+		{name: "Inclusive", sep: ",", input: "a,b", ops: []any{Inclusive(true)}, expected: []piece{{"a,", ","}, {"b", ""}}},
+		{name: "StartOffset applies once", sep: ",", input: "a,b,c", ops: []any{StartOffset(2)}, expected: []piece{{"a,b", ","}, {"c", ""}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := collectPieces(Iterator(indexConsumer{tt.sep}, tt.input, tt.ops...))
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("Iterator(%q) = %q, expected %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+
+	t.Run("Consumer which ignores Inclusive", func(t *testing.T) {
+		c := exclusiveConsumer{indexConsumer{"::"}}
+		got := collectPieces(Iterator(c, "a::b:::c"))
+		if expected := []piece{{"a", "::"}, {"b", "::"}, {":c", ""}}; !slices.Equal(got, expected) {
+			t.Errorf("Iterator() = %q, expected %q", got, expected)
+		}
+		got = collectPieces(Iterator(c, "a::b", Inclusive(true)))
+		if expected := []piece{{"a::", "::"}, {"b", ""}}; !slices.Equal(got, expected) {
+			t.Errorf("Inclusive Iterator() = %q, expected %q", got, expected)
+		}
+		// The piece before the match is the same as the match, which must not be mistaken for an inclusive one.
+		got = collectPieces(Iterator(exclusiveConsumer{indexConsumer{"/"}}, "//b", Ignore0PositionMatch(true)))
+		if expected := []piece{{"/", "/"}, {"b", ""}}; !slices.Equal(got, expected) {
+			t.Errorf("Ignore0PositionMatch Iterator() = %q, expected %q", got, expected)
+		}
+	})
+
+	t.Run("Stops", func(t *testing.T) {
+		count := 0
+		for range Iterator(indexConsumer{","}, "a,b,c") {
+			count++
+			break
+		}
+		if count != 1 {
+			t.Errorf("Iterator yielded %d times after break", count)
+		}
+	})
+}
+
+func TestSplitFunc(t *testing.T) {
+	scan := func(input string, split bufio.SplitFunc) ([]string, error) {
+		s := bufio.NewScanner(strings.NewReader(input))
+		s.Buffer(make([]byte, 2), 64)
+		s.Split(split)
+		var tokens []string
+		for s.Scan() {
+			tokens = append(tokens, s.Text())
+		}
+		return tokens, s.Err()
+	}
+
+	tokens, err := scan("ab\r\ncd\r\n\r\nef", SplitFunc(indexConsumer{"\r\n"}))
+	if err != nil {
+		t.Fatalf("Scan error = %v", err)
+	}
+	if expected := []string{"ab", "cd", "", "ef"}; !slices.Equal(tokens, expected) {
+		t.Errorf("tokens = %q, expected %q", tokens, expected)
+	}
+
+	tokens, _ = scan("ab,cd", SplitFunc(indexConsumer{","}, Inclusive(true)))
+	if expected := []string{"ab,", "cd"}; !slices.Equal(tokens, expected) {
+		t.Errorf("Inclusive tokens = %q, expected %q", tokens, expected)
+	}
+
+	// The token before a separator longer than it must not be cut from the wrong end.
+	tokens, err = scan("a::b::", SplitFunc(exclusiveConsumer{indexConsumer{"::"}}))
+	if err != nil {
+		t.Fatalf("Scan error = %v", err)
+	}
+	if expected := []string{"a", "b"}; !slices.Equal(tokens, expected) {
+		t.Errorf("tokens of a consumer which ignores Inclusive = %q, expected %q", tokens, expected)
+	}
+	tokens, _ = scan("a::b", SplitFunc(exclusiveConsumer{indexConsumer{"::"}}, Inclusive(true)))
+	if expected := []string{"a::", "b"}; !slices.Equal(tokens, expected) {
+		t.Errorf("Inclusive tokens of a consumer which ignores Inclusive = %q, expected %q", tokens, expected)
+	}
+	tokens, _ = scan("//b", SplitFunc(exclusiveConsumer{indexConsumer{"/"}}, Ignore0PositionMatch(true), Inclusive(true)))
+	if expected := []string{"//", "b"}; !slices.Equal(tokens, expected) {
+		t.Errorf("Ignore0PositionMatch tokens = %q, expected %q", tokens, expected)
+	}
+
+	_, err = scan("abc", SplitFunc(indexConsumer{""}))
+	if !errors.Is(err, ErrNoProgress) {
+		t.Errorf("empty separator error = %v, expected ErrNoProgress", err)
+	}
+}
@@ -0,0 +1,59 @@
+package consume
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrEmptyEscape        = errors.New("consume: escape string cannot be empty")
+	ErrEmptyEncasingStart = errors.New("consume: encasing start cannot be empty")
+	// ErrUnknownNormalization is returned for a Normalization which is not one of the forms defined here.
+	ErrUnknownNormalization = errors.New("consume: unknown normalization form")
+	// ErrNegativeStartOffset is returned for a StartOffset below zero.
+	ErrNegativeStartOffset = errors.New("consume: start offset cannot be negative")
+	// ErrUnknownNoMatch is returned for a NoMatch which is not one of the behaviours defined here.
+	ErrUnknownNoMatch = errors.New("consume: unknown no-match behaviour")
+	// ErrNoProgress is returned by a scanner when an empty separator matches at the start of a token, which
+	// would otherwise produce empty tokens forever.
+	ErrNoProgress = errors.New("consume: separator matched without consuming input")
+	// ErrNoMatch is returned by a split function when the input does not start with a match.
+	ErrNoMatch = errors.New("consume: input does not start with a match")
+	// ErrNotEncodable is returned when marshalling a consumer which holds a function, such as a
+	// MustBeFollowedBy option or a rune separator, as a function cannot be encoded.
+	ErrNotEncodable = errors.New("consume: consumer holding a function cannot be encoded")
+	// ErrBadEncoding is returned when unmarshalling data which is not a consumer encoded by MarshalBinary.
+	ErrBadEncoding = errors.New("consume: invalid encoded consumer")
+)
+
+// UnsupportedOptionError reports an option passed to a consumer which does not support it.
+type UnsupportedOptionError struct {
+	Consumer string
+	Option   any
+}
+
+func (e *UnsupportedOptionError) Error() string {
+	return fmt.Sprintf("consume: %s does not support option %T", e.Consumer, e.Option)
+}
+
+// UnterminatedEncasingError reports input which ends inside an encasing. Offset is the byte offset of the
+// start of the innermost encasing left open.
+type UnterminatedEncasingError struct {
+	Encasing Encasing
+	Offset   int
+}
+
+func (e *UnterminatedEncasingError) Error() string {
+	return fmt.Sprintf("consume: unterminated encasing %q starting at offset %d", e.Encasing.Start, e.Offset)
+}
+
+// DanglingEscapeError reports input which ends with an escape string that has nothing to escape. Offset is
+// the byte offset of the escape string.
+type DanglingEscapeError struct {
+	Escape string
+	Offset int
+}
+
+func (e *DanglingEscapeError) Error() string {
+	return fmt.Sprintf("consume: dangling escape %q at offset %d", e.Escape, e.Offset)
+}
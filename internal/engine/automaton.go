@@ -0,0 +1,170 @@
+package engine
+
+import (
+	"cmp"
+	"slices"
+	"unicode/utf8"
+)
+
+// maxDenseEntries is the largest transition table built for an automaton. Larger key sets, such as big prefix
+// sets, use sparse transitions instead.
+const maxDenseEntries = 1 << 20
+
+// acAutomaton is an Aho-Corasick automaton over bytes. Transitions are stored as a dense table indexed by
+// byte class, where every byte that does not appear in any key shares class 0, so that each input byte costs
+// a single table lookup. When that table would be too large, each state keeps only its own transitions and
+// the rest are found through its failure link.
+type acAutomaton struct {
+	classes [256]uint8
+	stride  int
+	trans   []int32 // the dense table, or nil for sparse transitions
+	depth   []int32
+	term    []bool
+	// dict links each state to the nearest state on its failure chain which ends a key, or 0 if there is none.
+	dict []int32
+	// live holds, for each state, the depth of the longest suffix of it, the state itself included, which a
+	// longer key carries on from, or 0 if there is none.
+	live   []int32
+	maxLen int
+	// begins holds the input bytes which may begin a key. Other runes can be passed over in the start state.
+	begins [256]bool
+
+	// Sparse transitions: the edges of state s are edges[first[s]:first[s+1]], sorted by byte, and root holds
+	// every transition of the start state.
+	first []int32
+	edges []acEdge
+	fail  []int32
+	root  [256]int32
+}
+
+type acEdge struct {
+	to int32
+	b  byte
+}
+
+func newACAutomaton(keys []string) *acAutomaton {
+	ac := &acAutomaton{}
+
+	var used [256]bool
+	for _, k := range keys {
+		for i := 0; i < len(k); i++ {
+			used[k[i]] = true
+		}
+		if len(k) > ac.maxLen {
+			ac.maxLen = len(k)
+		}
+	}
+	ac.stride = 1
+	for b, ok := range used {
+		if ok {
+			ac.classes[b] = uint8(ac.stride)
+			ac.stride++
+		}
+	}
+
+	// Build the trie of keys.
+	kids := [][]acEdge{nil}
+	ac.depth, ac.term, ac.dict = []int32{0}, []bool{false}, []int32{0}
+	for _, k := range keys {
+		s := int32(0)
+		for i := 0; i < len(k); i++ {
+			j := slices.IndexFunc(kids[s], func(e acEdge) bool { return e.b == k[i] })
+			if j < 0 {
+				t := int32(len(ac.depth))
+				kids = append(kids, nil)
+				ac.depth = append(ac.depth, ac.depth[s]+1)
+				ac.term = append(ac.term, false)
+				ac.dict = append(ac.dict, 0)
+				kids[s] = append(kids[s], acEdge{to: t, b: k[i]})
+				j = len(kids[s]) - 1
+			}
+			s = kids[s][j].to
+		}
+		ac.term[s] = true
+	}
+
+	dense := len(ac.depth)*ac.stride <= maxDenseEntries
+	if dense {
+		ac.trans = make([]int32, len(ac.depth)*ac.stride)
+	} else {
+		ac.first = make([]int32, len(ac.depth)+1)
+		for s, e := range kids {
+			slices.SortFunc(e, func(a, b acEdge) int { return cmp.Compare(a.b, b.b) })
+			ac.edges = append(ac.edges, e...)
+			ac.first[s+1] = int32(len(ac.edges))
+		}
+		for _, e := range kids[0] {
+			ac.root[e.b] = e.to
+		}
+	}
+
+	// Breadth first, find the failure link of each state. With a dense table the missing transitions are
+	// filled in from it, so the table becomes a complete DFA.
+	fail := make([]int32, len(ac.depth))
+	if !dense {
+		ac.fail = fail
+	}
+	ac.live = make([]int32, len(ac.depth))
+	queue := make([]int32, 0, len(ac.depth))
+	for _, e := range kids[0] {
+		ac.begins[e.b] = true
+		queue = append(queue, e.to)
+		if dense {
+			ac.trans[ac.classes[e.b]] = e.to
+		}
+	}
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		f := fail[s]
+		if len(kids[s]) > 0 {
+			ac.live[s] = ac.depth[s]
+		} else {
+			ac.live[s] = ac.live[f]
+		}
+		if ac.term[f] && f != 0 {
+			ac.dict[s] = f
+		} else {
+			ac.dict[s] = ac.dict[f]
+		}
+		if dense {
+			copy(ac.trans[int(s)*ac.stride:int(s+1)*ac.stride], ac.trans[int(f)*ac.stride:int(f+1)*ac.stride])
+		}
+		for _, e := range kids[s] {
+			// The failure state is shallower, so its transitions are already complete.
+			fail[e.to] = ac.next(f, e.b)
+			if dense {
+				ac.trans[int(s)*ac.stride+int(ac.classes[e.b])] = e.to
+			}
+			queue = append(queue, e.to)
+		}
+	}
+	return ac
+}
+
+// foldBegins sets begins for an automaton of case folded keys, which is fed the case folded input. An ASCII
+// byte may begin a key if its folded form does, and any other byte may.
+func (ac *acAutomaton) foldBegins() {
+	folded := ac.begins
+	for b := range ac.begins {
+		ac.begins[b] = b >= utf8.RuneSelf || folded[foldRune(rune(b))]
+	}
+}
+
+func (ac *acAutomaton) next(s int32, b byte) int32 {
+	if ac.trans != nil {
+		return ac.trans[int(s)*ac.stride+int(ac.classes[b])]
+	}
+	for s != 0 {
+		for _, e := range ac.edges[ac.first[s]:ac.first[s+1]] {
+			if e.b >= b {
+				if e.b == b {
+					return e.to
+				}
+				break
+			}
+		}
+		s = ac.fail[s]
+	}
+	return ac.root[b]
+}
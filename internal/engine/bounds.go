@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/arran4/go-consume"
+)
+
+// NoRune stands for the rune before the start of the input, or after its end, which no MustBePrecededBy or
+// MustBeFollowedBy predicate is asked about.
+const NoRune rune = -1
+
+// verdict is the outcome of checking a match against the MustBePrecededBy, MustBeFollowedBy, MustBeAtEnd and
+// WordBoundary settings.
+type verdict int
+
+const (
+	accepted verdict = iota
+	rejected
+	// undecided means the check needs input which has not been read yet.
+	undecided
+)
+
+// hasBounds reports whether cfg places any condition on the input around a match.
+func hasBounds(cfg *consume.Config) bool {
+	return cfg.MustBePrecededBy != nil || cfg.MustBeFollowedBy != nil || cfg.MustBeAtEnd || cfg.WordBoundary
+}
+
+// checkBounds checks the match data[start:end] against the conditions cfg places on the input around it. prev
+// is the rune before data, or NoRune at the start of the input. Before EOF, a condition on what follows a match
+// at the end of data is undecided. The start and end of the input satisfy MustBePrecededBy and
+// MustBeFollowedBy.
+func checkBounds[T string | []byte](data T, start, end int, cfg *consume.Config, prev rune, atEOF bool) verdict {
+	if cfg.MustBeAtEnd && end < len(data) {
+		return rejected
+	}
+	after := NoRune
+	if cfg.MustBeFollowedBy != nil || cfg.WordBoundary || cfg.MustBeAtEnd {
+		if !atEOF && !fullRune(data[end:]) {
+			return undecided
+		}
+		if end < len(data) {
+			after, _ = decodeRune(data[end:])
+		}
+	}
+	before := prev
+	if start > 0 {
+		before = PrevRune(data, start)
+	}
+	if cfg.MustBePrecededBy != nil && before != NoRune && !cfg.MustBePrecededBy(before) {
+		return rejected
+	}
+	if cfg.MustBeFollowedBy != nil && after != NoRune && !cfg.MustBeFollowedBy(after) {
+		return rejected
+	}
+	if cfg.WordBoundary {
+		first, last := after, before
+		if start < end {
+			first, _ = decodeRune(data[start:end])
+			last = PrevRune(data, end)
+		}
+		if isWordRune(before) == isWordRune(first) || isWordRune(last) == isWordRune(after) {
+			return rejected
+		}
+	}
+	return accepted
+}
+
+// PrevRune returns the rune which ends data[:i], or NoRune if i is 0.
+func PrevRune[T string | []byte](data T, i int) rune {
+	if i == 0 {
+		return NoRune
+	}
+	r, _ := utf8.DecodeLastRuneInString(string(data[max(0, i-utf8.UTFMax):i]))
+	return r
+}
+
+// isWordRune reports whether r is a letter, digit or underscore, as consume.WordBoundary counts them.
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
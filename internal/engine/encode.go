@@ -0,0 +1,475 @@
+package engine
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"regexp"
+
+	"github.com/arran4/go-consume"
+)
+
+// Encoded consumers start with magic, a kind byte and a version byte.
+const (
+	magic         = "gcon"
+	kindPrefix    = 'p'
+	kindUntil     = 'u'
+	encodeVersion = 1
+)
+
+// AppendPrefix appends the binary encoding of a prefix consumer to b. Keys are stored in order along with
+// the shape of the trie, so DecodePrefix rebuilds it without sorting or comparing keys.
+func AppendPrefix[V any](b []byte, t *Trie[V], cfg *consume.Config) ([]byte, error) {
+	b = append(b, magic...)
+	b = append(b, kindPrefix, encodeVersion)
+	b, err := appendConfig(b, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	st := t.load()
+	nodes, size := 0, 0
+	var keys []string
+	var count func(n *trieNode[V])
+	count = func(n *trieNode[V]) {
+		nodes++
+		if n.isEnd {
+			keys = append(keys, n.fullPath)
+			size += len(n.fullPath)
+		}
+		for _, c := range n.children {
+			count(c)
+		}
+	}
+	count(st.root)
+
+	b = binary.AppendUvarint(b, uint64(len(keys)))
+	for _, k := range keys {
+		b = binary.AppendUvarint(b, uint64(len(k)))
+	}
+	b = binary.AppendUvarint(b, uint64(size))
+	for _, k := range keys {
+		b = append(b, k...)
+	}
+
+	// Each node is its segment length, whether a key ends there and its number of children, in pre-order,
+	// which visits the keys in order. A segment is read from the next key to end at or below its node.
+	b = binary.AppendUvarint(b, uint64(nodes))
+	var appendNode func(n *trieNode[V])
+	appendNode = func(n *trieNode[V]) {
+		b = binary.AppendUvarint(b, uint64(len(n.segment)))
+		end := byte(0)
+		if n.isEnd {
+			end = 1
+		}
+		b = append(b, end)
+		b = binary.AppendUvarint(b, uint64(len(n.children)))
+		for _, c := range n.children {
+			appendNode(c)
+		}
+	}
+	appendNode(st.root)
+	return b, nil
+}
+
+// DecodePrefix decodes a prefix consumer encoded by AppendPrefix. Every value is the zero V.
+func DecodePrefix[V any](data []byte) (*Trie[V], consume.Config, error) {
+	d := decoder{data: data}
+	d.header(kindPrefix)
+	cfg := d.config()
+
+	lens := make([]int, d.count(1))
+	for i := range lens {
+		lens[i] = d.length()
+	}
+	// The keys share the memory of one string.
+	blob := d.str(d.length())
+	keys := make([]string, len(lens))
+	for i, n := range lens {
+		if n > len(blob) {
+			d.fail()
+			break
+		}
+		keys[i], blob = blob[:n], blob[n:]
+	}
+	if len(blob) != 0 {
+		d.fail()
+	}
+
+	nodeCount := d.count(3)
+	if d.err != nil || nodeCount == 0 {
+		return nil, cfg, consume.ErrBadEncoding
+	}
+	nodes := make([]trieNode[V], nodeCount)
+	children := make([]*trieNode[V], nodeCount-1)
+	used, next := 0, 0 // nodes and keys used so far
+
+	// readNode reads a node whose segment starts depth bytes into its keys, below the node whose path is
+	// parent. Segments and paths are substrings of the keys.
+	var readNode func(depth int, parent string) *trieNode[V]
+	readNode = func(depth int, parent string) *trieNode[V] {
+		// Every node but the root has a non-empty segment, so a key at or below it.
+		isRoot := used == 0
+		if d.err != nil || used == len(nodes) || (!isRoot && next == len(keys)) {
+			d.fail()
+			return nil
+		}
+		n := &nodes[used]
+		used++
+		// Only the root of an empty trie has no key at or below it.
+		key := ""
+		if next < len(keys) {
+			key = keys[next]
+		}
+		l := d.uvarint()
+		if (!isRoot && l == 0) || l > uint64(len(key)-depth) || !hasPrefix(key, parent) {
+			d.fail()
+			return nil
+		}
+		n.segment = key[depth : depth+int(l)]
+		path := key[:depth+int(l)]
+		if end := d.readByte(); end > 1 {
+			d.fail()
+		} else if end == 1 {
+			if len(key) != len(path) {
+				d.fail()
+			}
+			n.isEnd, n.fullPath = true, key
+			next++
+		}
+		c := d.length()
+		if c > len(children) || (!isRoot && !n.isEnd && c < 2) {
+			d.fail()
+			return nil
+		}
+		kids := children[:c:c]
+		children = children[c:]
+		for i := range kids {
+			if kids[i] = readNode(len(path), path); kids[i] == nil {
+				return nil
+			}
+			if i > 0 && kids[i].segment[0] <= kids[i-1].segment[0] {
+				d.fail()
+				return nil
+			}
+		}
+		if c > 0 {
+			n.setChildren(kids)
+		}
+		return n
+	}
+	root := readNode(0, "")
+	if d.err != nil || used != len(nodes) || next != len(keys) || len(d.data) != 0 {
+		return nil, cfg, consume.ErrBadEncoding
+	}
+
+	t := &Trie[V]{}
+	t.state.Store(&trieState[V]{root: root, size: len(keys)})
+	return t, cfg, nil
+}
+
+// AppendUntil appends the binary encoding of an until consumer to b.
+func AppendUntil(b []byte, s *Separators, cfg *consume.Config) ([]byte, error) {
+	b = append(b, magic...)
+	b = append(b, kindUntil, encodeVersion)
+	b, err := appendConfig(b, cfg)
+	if err != nil {
+		return nil, err
+	}
+	b = binary.AppendUvarint(b, uint64(len(s.Keys())))
+	for _, k := range s.Keys() {
+		b = appendString(b, k)
+	}
+	exprs, err := regexpSources(s)
+	if err != nil {
+		return nil, err
+	}
+	b = binary.AppendUvarint(b, uint64(len(exprs)))
+	for _, expr := range exprs {
+		b = appendString(b, expr)
+	}
+	return b, nil
+}
+
+// DecodeUntil decodes an until consumer encoded by AppendUntil.
+func DecodeUntil(data []byte) (*Separators, consume.Config, error) {
+	d := decoder{data: data}
+	d.header(kindUntil)
+	cfg := d.config()
+	keys := make([]string, d.count(1))
+	for i := range keys {
+		keys[i] = d.str(d.length())
+	}
+	exprs := make([]string, d.count(1))
+	for i := range exprs {
+		exprs[i] = d.str(d.length())
+	}
+	if d.err != nil || len(d.data) != 0 {
+		return nil, cfg, consume.ErrBadEncoding
+	}
+	patterns, err := compileRegexps(exprs)
+	if err != nil {
+		return nil, cfg, consume.ErrBadEncoding
+	}
+	return NewSeparators(keys, patterns...), cfg, nil
+}
+
+// encodedPrefix and encodedUntil are the JSON forms of the consumers.
+type encodedPrefix struct {
+	Paths   []string        `json:"paths"`
+	Options *consume.Config `json:"options,omitempty"`
+}
+
+type encodedUntil struct {
+	Separators []string        `json:"separators"`
+	Regexps    []string        `json:"regexps,omitempty"`
+	Options    *consume.Config `json:"options,omitempty"`
+}
+
+// MarshalPrefixJSON returns the JSON form of a prefix consumer, its paths in order and its options.
+func MarshalPrefixJSON[V any](t *Trie[V], cfg *consume.Config) ([]byte, error) {
+	e := encodedPrefix{Paths: []string{}}
+	walk(t.load().root, func(n *trieNode[V]) bool {
+		e.Paths = append(e.Paths, n.fullPath)
+		return true
+	})
+	var err error
+	if e.Options, err = jsonConfig(cfg); err != nil {
+		return nil, err
+	}
+	return json.Marshal(e)
+}
+
+// UnmarshalPrefixJSON decodes the JSON form of a prefix consumer.
+func UnmarshalPrefixJSON[V any](data []byte) (*Trie[V], consume.Config, error) {
+	var e encodedPrefix
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, consume.Config{}, err
+	}
+	cfg, err := fromJSONConfig(e.Options)
+	if err != nil {
+		return nil, cfg, err
+	}
+	return NewTrie[V](e.Paths, nil), cfg, nil
+}
+
+// MarshalUntilJSON returns the JSON form of an until consumer, its separators and its options.
+func MarshalUntilJSON(s *Separators, cfg *consume.Config) ([]byte, error) {
+	e := encodedUntil{Separators: append([]string{}, s.Keys()...)}
+	var err error
+	if e.Regexps, err = regexpSources(s); err != nil {
+		return nil, err
+	}
+	if e.Options, err = jsonConfig(cfg); err != nil {
+		return nil, err
+	}
+	return json.Marshal(e)
+}
+
+// UnmarshalUntilJSON decodes the JSON form of an until consumer.
+func UnmarshalUntilJSON(data []byte) (*Separators, consume.Config, error) {
+	var e encodedUntil
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, consume.Config{}, err
+	}
+	cfg, err := fromJSONConfig(e.Options)
+	if err != nil {
+		return nil, cfg, err
+	}
+	patterns, err := compileRegexps(e.Regexps)
+	if err != nil {
+		return nil, cfg, err
+	}
+	return NewSeparators(e.Separators, patterns...), cfg, nil
+}
+
+// regexpSources returns the source of each pattern of s, which must all be regular expressions. A
+// regexp.Regexp does not say whether Longest was called on it, so that is lost.
+func regexpSources(s *Separators) ([]string, error) {
+	var exprs []string
+	for _, p := range s.Patterns() {
+		re, ok := p.(RegexpPattern)
+		if !ok {
+			return nil, consume.ErrNotEncodable
+		}
+		exprs = append(exprs, re.Regexp.String())
+	}
+	return exprs, nil
+}
+
+func compileRegexps(exprs []string) ([]Pattern, error) {
+	var patterns []Pattern
+	for _, expr := range exprs {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, RegexpPattern{Regexp: re})
+	}
+	return patterns, nil
+}
+
+// jsonConfig returns cfg for encoding, or nil if it has no settings.
+func jsonConfig(cfg *consume.Config) (*consume.Config, error) {
+	if cfg.MustBePrecededBy != nil || cfg.MustBeFollowedBy != nil {
+		return nil, consume.ErrNotEncodable
+	}
+	if len(cfg.Options()) == 0 {
+		return nil, nil
+	}
+	return cfg, nil
+}
+
+func fromJSONConfig(cfg *consume.Config) (consume.Config, error) {
+	if cfg == nil {
+		return consume.Config{}, nil
+	}
+	return *cfg, cfg.Validate()
+}
+
+func appendConfig(b []byte, c *consume.Config) ([]byte, error) {
+	if c.MustBePrecededBy != nil || c.MustBeFollowedBy != nil {
+		return nil, consume.ErrNotEncodable
+	}
+	// The boolean settings are bits of one number, in the order they appear in Config, and Normalization takes
+	// the bits above them.
+	bools := []bool{c.Inclusive, c.Ignore0PositionMatch, c.MustBeAtEnd, c.CaseInsensitive,
+		c.MustMatchWholeString, c.ConsumeRemainingIfNotFound, c.EscapeBreaksEncasing, c.Unescape,
+		c.StripEncasing, c.Strict, c.CollapseRuns, c.WordBoundary}
+	var flags uint64
+	for bit, set := range bools {
+		if set {
+			flags |= 1 << bit
+		}
+	}
+	flags |= uint64(c.Normalization) << len(bools)
+	b = binary.AppendUvarint(b, flags)
+	b = binary.AppendVarint(b, int64(c.StartOffset))
+	b = binary.AppendUvarint(b, uint64(c.NoMatch))
+	b = binary.AppendUvarint(b, uint64(len(c.Escapes)))
+	for _, esc := range c.Escapes {
+		b = appendString(b, esc)
+	}
+	b = binary.AppendUvarint(b, uint64(len(c.Encasings)))
+	for _, enc := range c.Encasings {
+		b = appendString(b, enc.Start)
+		b = appendString(b, enc.End)
+	}
+	return b, nil
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.AppendUvarint(b, uint64(len(s)))
+	return append(b, s...)
+}
+
+// decoder reads an encoded consumer. After the first error every read returns a zero value, so a decode
+// only needs to check err at the end.
+type decoder struct {
+	data []byte
+	err  error
+}
+
+func (d *decoder) fail() {
+	d.err, d.data = consume.ErrBadEncoding, nil
+}
+
+func (d *decoder) header(kind byte) {
+	if len(d.data) < len(magic)+2 || string(d.data[:len(magic)]) != magic || d.data[len(magic)] != kind ||
+		d.data[len(magic)+1] != encodeVersion {
+		d.fail()
+		return
+	}
+	d.data = d.data[len(magic)+2:]
+}
+
+func (d *decoder) uvarint() uint64 {
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *decoder) varint() int64 {
+	v, n := binary.Varint(d.data)
+	if n <= 0 {
+		d.fail()
+		return 0
+	}
+	d.data = d.data[n:]
+	return v
+}
+
+func (d *decoder) readByte() byte {
+	if len(d.data) == 0 {
+		d.fail()
+		return 0
+	}
+	c := d.data[0]
+	d.data = d.data[1:]
+	return c
+}
+
+// count reads the number of items which follow, each taking at least size bytes.
+func (d *decoder) count(size int) int {
+	n := d.uvarint()
+	if n > uint64(len(d.data)/size) {
+		d.fail()
+		return 0
+	}
+	return int(n)
+}
+
+// length reads a length or count, which can be no more than the number of bytes left.
+func (d *decoder) length() int {
+	n := d.uvarint()
+	if n > uint64(len(d.data)) {
+		d.fail()
+		return 0
+	}
+	return int(n)
+}
+
+// str reads a string of n bytes.
+func (d *decoder) str(n int) string {
+	if n > len(d.data) {
+		d.fail()
+		return ""
+	}
+	s := string(d.data[:n])
+	d.data = d.data[n:]
+	return s
+}
+
+func (d *decoder) config() consume.Config {
+	var c consume.Config
+	flags := d.uvarint()
+	bools := []*bool{&c.Inclusive, &c.Ignore0PositionMatch, &c.MustBeAtEnd, &c.CaseInsensitive,
+		&c.MustMatchWholeString, &c.ConsumeRemainingIfNotFound, &c.EscapeBreaksEncasing, &c.Unescape,
+		&c.StripEncasing, &c.Strict, &c.CollapseRuns, &c.WordBoundary}
+	for bit, set := range bools {
+		*set = flags&(1<<bit) != 0
+	}
+	c.Normalization = consume.Normalization(flags >> len(bools))
+	c.StartOffset = int(d.varint())
+	c.NoMatch = consume.NoMatch(d.uvarint())
+	if n := d.count(1); n > 0 {
+		c.Escapes = make([]string, n)
+		for i := range c.Escapes {
+			c.Escapes[i] = d.str(d.length())
+		}
+	}
+	if n := d.count(2); n > 0 {
+		c.Encasings = make([]consume.Encasing, n)
+		for i := range c.Encasings {
+			c.Encasings[i].Start = d.str(d.length())
+			c.Encasings[i].End = d.str(d.length())
+		}
+	}
+	if d.err == nil && c.Validate() != nil {
+		d.fail()
+	}
+	return c
+}
@@ -0,0 +1,43 @@
+package engine
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// foldRune maps r to the smallest rune in its Unicode simple case folding orbit, so that all runes which
+// are equal under simple case folding map to the same rune.
+func foldRune(r rune) rune {
+	m := r
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if f < m {
+			m = f
+		}
+	}
+	return m
+}
+
+// appendFoldedRune appends the case folded encoding of the rune at the start of s to b and returns the
+// extended buffer along with the number of bytes of s consumed. Invalid UTF-8 bytes are appended as is.
+func appendFoldedRune[T string | []byte](b []byte, s T) ([]byte, int) {
+	r, w := decodeRune(s)
+	if r == utf8.RuneError && w <= 1 {
+		return append(b, s[:w]...), w
+	}
+	return utf8.AppendRune(b, foldRune(r)), w
+}
+
+// FoldString returns s with every rune replaced by its case folded form.
+func FoldString(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s))
+	var buf []byte
+	for i := 0; i < len(s); {
+		var w int
+		buf, w = appendFoldedRune(buf[:0], s[i:])
+		sb.Write(buf)
+		i += w
+	}
+	return sb.String()
+}
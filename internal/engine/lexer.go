@@ -0,0 +1,157 @@
+package engine
+
+import (
+	"github.com/arran4/go-consume"
+)
+
+// lexKind classifies a step through the input.
+type lexKind int
+
+const (
+	lexPlain   lexKind = iota // a rune outside any encasing, where a separator may start
+	lexEncased                // a rune inside an encasing
+	lexEscape                 // an escape string and the rune it escapes
+	lexOpen                   // the start of an encasing
+	lexClose                  // the end of an encasing
+)
+
+// lexStep returns the kind of the step at i and the position after it, given the stack of open encasings.
+// For an escape it also returns the length of the escape string, and for lexOpen the encasing opened. The
+// caller pushes and pops the stack, which keeps it off the heap.
+func lexStep[T string | []byte](cfg *consume.Config, stack []consume.Encasing, data T, i int) (lexKind, int, int, consume.Encasing) {
+	if len(stack) > 0 {
+		current := stack[len(stack)-1]
+		if cfg.EscapeBreaksEncasing {
+			if next, n, ok := lexEscapeAt(cfg, data, i); ok {
+				return lexEscape, next, n, consume.Encasing{}
+			}
+		}
+		if hasPrefix(data[i:], current.End) {
+			return lexClose, i + len(current.End), 0, consume.Encasing{}
+		}
+		if current.Start != current.End {
+			if enc, ok := lexOpenAt(cfg, data, i); ok {
+				return lexOpen, i + len(enc.Start), 0, enc
+			}
+		}
+		return lexEncased, i + runeWidth(data[i:]), 0, consume.Encasing{}
+	}
+
+	if next, n, ok := lexEscapeAt(cfg, data, i); ok {
+		return lexEscape, next, n, consume.Encasing{}
+	}
+	if enc, ok := lexOpenAt(cfg, data, i); ok {
+		return lexOpen, i + len(enc.Start), 0, enc
+	}
+	return lexPlain, i + runeWidth(data[i:]), 0, consume.Encasing{}
+}
+
+// updateStack pushes or pops the encasing stack after a step of the given kind.
+func updateStack(stack []consume.Encasing, kind lexKind, enc consume.Encasing) []consume.Encasing {
+	switch kind {
+	case lexOpen:
+		return append(stack, enc)
+	case lexClose:
+		return stack[:len(stack)-1]
+	}
+	return stack
+}
+
+func lexEscapeAt[T string | []byte](cfg *consume.Config, data T, i int) (int, int, bool) {
+	for _, esc := range cfg.Escapes {
+		if hasPrefix(data[i:], esc) {
+			next := i + len(esc)
+			if next < len(data) {
+				next += runeWidth(data[next:])
+			}
+			return next, len(esc), true
+		}
+	}
+	return i, 0, false
+}
+
+func lexOpenAt[T string | []byte](cfg *consume.Config, data T, i int) (consume.Encasing, bool) {
+	for _, enc := range cfg.Encasings {
+		if hasPrefix(data[i:], enc.Start) {
+			return enc, true
+		}
+	}
+	return consume.Encasing{}, false
+}
+
+// DecodeToken returns data[:end] with escapes resolved when cfg.Unescape is set, and the delimiters of
+// outermost encasings removed when cfg.StripEncasing is set. Input before cfg.StartOffset is kept as is.
+// data[:end] is returned unchanged when there is nothing to remove.
+func DecodeToken[T string | []byte](data T, end int, cfg *consume.Config) T {
+	if !cfg.Unescape && !cfg.StripEncasing || len(cfg.Escapes) == 0 && len(cfg.Encasings) == 0 {
+		return data[:end]
+	}
+	var stackBuf [8]consume.Encasing
+	stack := stackBuf[:0]
+	var out []byte
+	copied := 0 // data[:copied] has been accounted for in out
+	skip := func(from, to int) {
+		if out == nil {
+			out = make([]byte, 0, end)
+		}
+		out = append(out, data[copied:from]...)
+		copied = to
+	}
+	for i := min(cfg.StartOffset, end); i < end; {
+		kind, next, n, enc := lexStep(cfg, stack, data, i)
+		stack = updateStack(stack, kind, enc)
+		next = min(next, end)
+		switch kind {
+		case lexEscape:
+			// A trailing escape has nothing to escape, so it is kept.
+			if cfg.Unescape && i+n < next {
+				skip(i, i+n)
+			}
+		case lexOpen:
+			if cfg.StripEncasing && len(stack) == 1 {
+				skip(i, next)
+			}
+		case lexClose:
+			if cfg.StripEncasing && len(stack) == 0 {
+				skip(i, next)
+			}
+		}
+		i = next
+	}
+	if out == nil {
+		return data[:end]
+	}
+	out = append(out, data[copied:end]...)
+	return T(out)
+}
+
+// CheckTerminated lexes data from cfg.StartOffset and returns a *consume.UnterminatedEncasingError if it ends
+// inside an encasing, or a *consume.DanglingEscapeError if it ends with an escape that has nothing to escape.
+func CheckTerminated[T string | []byte](data T, cfg *consume.Config) error {
+	var stackBuf [8]consume.Encasing
+	var offsetsBuf [8]int
+	stack, offsets := stackBuf[:0], offsetsBuf[:0]
+	danglingAt, danglingLen := -1, 0
+	for i := cfg.StartOffset; i < len(data); {
+		kind, next, n, enc := lexStep(cfg, stack, data, i)
+		stack = updateStack(stack, kind, enc)
+		switch kind {
+		case lexOpen:
+			offsets = append(offsets, i)
+		case lexClose:
+			offsets = offsets[:len(offsets)-1]
+		case lexEscape:
+			if i+n == len(data) {
+				danglingAt, danglingLen = i, n
+			}
+		}
+		i = next
+	}
+	if len(stack) > 0 {
+		return &consume.UnterminatedEncasingError{Encasing: stack[len(stack)-1], Offset: offsets[len(offsets)-1]}
+	}
+	if danglingAt >= 0 {
+		return &consume.DanglingEscapeError{Escape: string(data[danglingAt : danglingAt+danglingLen]), Offset: danglingAt}
+	}
+	return nil
+}
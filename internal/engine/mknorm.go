@@ -0,0 +1,185 @@
+//go:build ignore
+
+// mknorm writes normtables.go from a copy of UnicodeData.txt, which can be downloaded from
+// https://www.unicode.org/Public/<version>/ucd/. It keeps the decompositions of the blocks listed below, and
+// the combining classes of every rune.
+//
+//	go run mknorm.go -ucd UnicodeData.txt -version 14.0.0
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// blocks are the ranges of runes whose decompositions are kept.
+var blocks = []struct {
+	lo, hi rune
+	name   string
+}{
+	{0x00A0, 0x00FF, "Latin-1 Supplement"},
+	{0x0100, 0x017F, "Latin Extended-A"},
+	{0x0180, 0x024F, "Latin Extended-B"},
+	{0x02B0, 0x02FF, "Spacing Modifier Letters"},
+	{0x0300, 0x036F, "Combining Diacritical Marks"},
+	{0x0370, 0x03FF, "Greek and Coptic"},
+	{0x0400, 0x04FF, "Cyrillic"},
+	{0x1E00, 0x1EFF, "Latin Extended Additional"},
+	{0x1F00, 0x1FFF, "Greek Extended"},
+	{0x2000, 0x206F, "General Punctuation"},
+	{0x2070, 0x209F, "Superscripts and Subscripts"},
+	{0x2100, 0x214F, "Letterlike Symbols"},
+	{0x2150, 0x218F, "Number Forms"},
+	{0xFB00, 0xFB4F, "Alphabetic Presentation Forms"},
+	{0xFF00, 0xFFEF, "Halfwidth and Fullwidth Forms"},
+}
+
+func main() {
+	ucd := flag.String("ucd", "UnicodeData.txt", "path to UnicodeData.txt")
+	version := flag.String("version", "", "Unicode version of the data")
+	out := flag.String("o", "normtables.go", "output file")
+	flag.Parse()
+	if *version == "" {
+		log.Fatal("mknorm: -version is required")
+	}
+
+	f, err := os.Open(*ucd)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	ccc := map[rune]int{}
+	canon, compat := map[rune][]rune{}, map[rune][]rune{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Split(sc.Text(), ";")
+		if len(fields) < 6 {
+			continue
+		}
+		r := parseRune(fields[0])
+		if c, _ := strconv.Atoi(fields[3]); c != 0 {
+			ccc[r] = c
+		}
+		if fields[5] == "" {
+			continue
+		}
+		parts := strings.Fields(fields[5])
+		m := canon
+		if strings.HasPrefix(parts[0], "<") {
+			m, parts = compat, parts[1:]
+		}
+		for _, p := range parts {
+			m[r] = append(m[r], parseRune(p))
+		}
+	}
+	if err := sc.Err(); err != nil {
+		log.Fatal(err)
+	}
+
+	var full func(r rune, withCompat bool) []rune
+	full = func(r rune, withCompat bool) []rune {
+		d, ok := canon[r]
+		if !ok && withCompat {
+			d, ok = compat[r]
+		}
+		if !ok {
+			return []rune{r}
+		}
+		var out []rune
+		for _, c := range d {
+			out = append(out, full(c, withCompat)...)
+		}
+		return out
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by mknorm from UnicodeData.txt %s; DO NOT EDIT.\n\n", *version)
+	fmt.Fprintf(&b, "package engine\n\n")
+	fmt.Fprintf(&b, "// unicodeVersion is the version of the Unicode data the tables were generated from.\n")
+	fmt.Fprintf(&b, "const unicodeVersion = %q\n\n", *version)
+
+	fmt.Fprintf(&b, "// decompositions holds the full canonical and compatibility decompositions of the runes in these blocks:\n")
+	for _, bl := range blocks {
+		fmt.Fprintf(&b, "//   - %s, U+%04X to U+%04X\n", bl.name, bl.lo, bl.hi)
+	}
+	fmt.Fprintf(&b, "var decompositions = [...]decomposition{\n")
+	longest := 0
+	for _, bl := range blocks {
+		for r := bl.lo; r <= bl.hi; r++ {
+			_, hasCanon := canon[r]
+			_, hasCompat := compat[r]
+			if !hasCanon && !hasCompat {
+				continue
+			}
+			d := full(r, true)
+			longest = max(longest, len(d))
+			c, k := "", string(d)
+			if hasCanon {
+				c = string(full(r, false))
+			}
+			if k == c {
+				k = ""
+			}
+			fmt.Fprintf(&b, "\t{0x%04X, %s, %s},\n", r, strconv.QuoteToASCII(c), strconv.QuoteToASCII(k))
+		}
+	}
+	fmt.Fprintf(&b, "}\n\n")
+	fmt.Fprintf(&b, "// maxDecomposition is the most runes a rune in decompositions decomposes to.\n")
+	fmt.Fprintf(&b, "const maxDecomposition = %d\n\n", longest)
+
+	runes := make([]rune, 0, len(ccc))
+	for r := range ccc {
+		runes = append(runes, r)
+	}
+	slices.Sort(runes)
+	fmt.Fprintf(&b, "// combiningClasses holds the runs of runes with the same non-zero canonical combining class.\n")
+	fmt.Fprintf(&b, "var combiningClasses = [...]combiningClass{\n")
+	for i := 0; i < len(runes); {
+		j := i + 1
+		for j < len(runes) && runes[j] == runes[j-1]+1 && ccc[runes[j]] == ccc[runes[i]] {
+			j++
+		}
+		fmt.Fprintf(&b, "\t{0x%04X, 0x%04X, %d},\n", runes[i], runes[j-1], ccc[runes[i]])
+		i = j
+	}
+	fmt.Fprintf(&b, "}\n\n")
+
+	// A starter which is the second rune of a canonical pair combines with the rune before it.
+	var backward []rune
+	for _, d := range canon {
+		if len(d) == 2 && ccc[d[1]] == 0 && !slices.Contains(backward, d[1]) {
+			backward = append(backward, d[1])
+		}
+	}
+	slices.Sort(backward)
+	fmt.Fprintf(&b, "// combinesBackward holds the starters, other than Hangul jamo, which compose with the rune before them.\n")
+	fmt.Fprintf(&b, "var combinesBackward = [...]rune{\n")
+	for _, r := range backward {
+		fmt.Fprintf(&b, "\t0x%04X,\n", r)
+	}
+	fmt.Fprintf(&b, "}\n")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func parseRune(s string) rune {
+	r, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return rune(r)
+}
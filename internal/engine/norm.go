@@ -0,0 +1,313 @@
+package engine
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/arran4/go-consume"
+)
+
+//go:generate go run mknorm.go -ucd UnicodeData.txt -version 14.0.0
+
+// Mapping says how keys and input are compared: byte for byte, or after Unicode simple case folding,
+// decomposition or both. Keys and input are mapped the same way, and matches are reported in the input.
+type Mapping uint8
+
+const (
+	mapFold Mapping = 1 << iota
+	// mapCanonical decomposes runes canonically and puts combining marks in canonical order. Two strings are
+	// equal in NFC exactly when they are equal in NFD, so this matches under either form.
+	mapCanonical
+	// mapCompat also applies compatibility decompositions, for NFKC and NFKD.
+	mapCompat
+
+	numMappings = 1 << iota
+)
+
+// MappingOf returns the mapping cfg asks for with CaseInsensitive and Normalization.
+func MappingOf(cfg *consume.Config) Mapping {
+	var m Mapping
+	if cfg.CaseInsensitive {
+		m |= mapFold
+	}
+	switch cfg.Normalization {
+	case consume.NFC, consume.NFD:
+		m |= mapCanonical
+	case consume.NFKC, consume.NFKD:
+		m |= mapCanonical | mapCompat
+	}
+	return m
+}
+
+func (m Mapping) normalizes() bool {
+	return m&mapCanonical != 0
+}
+
+// MapString returns s as keys are compared under m.
+func MapString(s string, m Mapping) string {
+	if m == 0 {
+		return s
+	}
+	if !m.normalizes() {
+		return FoldString(s)
+	}
+	var sb strings.Builder
+	sb.Grow(len(s))
+	var sg segmenter
+	emit := func(b byte, _, _ bool) { sb.WriteByte(b) }
+	for i := 0; i < len(s); {
+		r, w := decodeSegmentRune(s[i:])
+		if sg.breaksBefore(r, m) {
+			sg.flush(m, emit)
+		}
+		sg.add(r, w, i, false, m)
+		i += w
+	}
+	sg.flush(m, emit)
+	return sb.String()
+}
+
+// segmenter gathers the input one normalization segment at a time, as a segment is mapped as a whole: a
+// starter and the runes after it which decompose to non-starters, as canonical ordering can move those. Keys
+// only start and end at the edges of segments, so a match never takes part of a rune, or a base rune without
+// its combining marks. As in the stream-safe text format, a segment is cut short if its marks would not fit.
+type segmenter struct {
+	buf        [maxSegment]rune
+	n          int  // the decomposed runes of the segment are buf[:n]
+	start, end int  // the input the segment came from
+	candidate  bool // whether a key may start at start
+}
+
+// maxSegment is the most decomposed runes a segment holds, which is more than the 30 non-starters of a
+// stream-safe segment.
+const maxSegment = 32 + maxDecomposition
+
+// decodeSegmentRune returns the rune at the start of s and its width. A byte which is not valid UTF-8 is
+// returned as a negative rune, so that it is compared as it is.
+func decodeSegmentRune[T string | []byte](s T) (rune, int) {
+	r, w := decodeRune(s)
+	if r == utf8.RuneError && w <= 1 {
+		return -1 - rune(s[0]), 1
+	}
+	return r, w
+}
+
+// startsSegment reports whether a new segment starts at r, a rune from decodeSegmentRune.
+func startsSegment(r rune, m Mapping) bool {
+	if r < utf8.RuneSelf {
+		return true
+	}
+	first := firstDecomposed(r, m)
+	return combiningClassOf(first) == 0 && !composesBackward(first)
+}
+
+// breaksBefore reports whether the segment must be flushed before r is added.
+func (sg *segmenter) breaksBefore(r rune, m Mapping) bool {
+	return sg.n > 0 && (startsSegment(r, m) || sg.n > len(sg.buf)-maxDecomposition)
+}
+
+// add adds r, a rune from decodeSegmentRune of width w at input position i, to the segment. candidate says
+// whether a key may start at i, which only counts at the start of a segment.
+func (sg *segmenter) add(r rune, w, i int, candidate bool, m Mapping) {
+	if sg.n == 0 {
+		sg.start, sg.candidate = i, candidate
+	}
+	sg.end = i + w
+	if r < 0 {
+		sg.buf[sg.n] = r
+		sg.n++
+		return
+	}
+	sg.n += len(appendDecomposed(sg.buf[sg.n:sg.n], r, m))
+}
+
+// flush puts the runes of the segment in canonical order and calls emit with each byte of their encoding,
+// whether it is the first byte and whether it is the last, then empties the segment.
+func (sg *segmenter) flush(m Mapping, emit func(b byte, first, last bool)) {
+	runes := sg.buf[:sg.n]
+	sg.n = 0
+	orderCanonically(runes)
+	var buf [utf8.UTFMax]byte
+	for k, r := range runes {
+		enc := appendMappedRune(buf[:0], r, m)
+		for j, b := range enc {
+			emit(b, k == 0 && j == 0, k == len(runes)-1 && j == len(enc)-1)
+		}
+	}
+}
+
+// leadLen returns the length of the mapped encoding of the starter which begins the segment, or 0 if it
+// begins with a non-starter. Combining marks added to the segment are ordered after it.
+func (sg *segmenter) leadLen(m Mapping) int {
+	if r := sg.buf[0]; r < 0 || combiningClassOf(r) == 0 {
+		var buf [utf8.UTFMax]byte
+		return len(appendMappedRune(buf[:0], r, m))
+	}
+	return 0
+}
+
+// appendMappedRune appends the encoding of r, a decomposed rune of a segment, to b, folding it if m says to.
+func appendMappedRune(b []byte, r rune, m Mapping) []byte {
+	switch {
+	case r < 0:
+		return append(b, byte(-1-r))
+	case m&mapFold != 0:
+		return utf8.AppendRune(b, foldRune(r))
+	}
+	return utf8.AppendRune(b, r)
+}
+
+// keyStart returns the position after i of the next rune a key may start at: the next rune, or when
+// normalizing, the start of the next segment. It reports false if the segment at i runs to the end of data,
+// as more data could carry it on.
+func keyStart[T string | []byte](data T, i int, m Mapping) (int, bool) {
+	i += runeWidth(data[i:])
+	if !m.normalizes() {
+		return i, true
+	}
+	for i < len(data) && fullRune(data[i:]) {
+		r, w := decodeSegmentRune(data[i:])
+		if startsSegment(r, m) {
+			return i, true
+		}
+		i += w
+	}
+	return len(data), false
+}
+
+// mayStartAt reports whether a key may start at i in data: at the start of a rune, or when normalizing, of a
+// segment. The end of data counts as a start.
+func mayStartAt[T string | []byte](data T, i int, m Mapping) bool {
+	if i >= len(data) {
+		return true
+	}
+	if !utf8.RuneStart(data[i]) {
+		return false
+	}
+	if !m.normalizes() {
+		return true
+	}
+	r, _ := decodeSegmentRune(data[i:])
+	return startsSegment(r, m)
+}
+
+// decomposition is an entry of the generated decompositions table. compat is empty if it is the same as canon,
+// and canon is empty if the rune has only a compatibility decomposition.
+type decomposition struct {
+	r             rune
+	canon, compat string
+}
+
+// combiningClass is a run of runes with the same canonical combining class.
+type combiningClass struct {
+	lo, hi rune
+	class  uint8
+}
+
+// Hangul syllables decompose algorithmically into leading consonant, vowel and optional trailing consonant
+// jamo.
+const (
+	hangulBase  = 0xAC00
+	hangulCount = 11172
+	jamoLBase   = 0x1100
+	jamoVBase   = 0x1161
+	jamoTBase   = 0x11A7
+	jamoVCount  = 21
+	jamoTCount  = 28
+)
+
+// appendDecomposed appends the full decomposition of r under m to runes.
+func appendDecomposed(runes []rune, r rune, m Mapping) []rune {
+	if r < 0xA0 {
+		return append(runes, r)
+	}
+	if s := r - hangulBase; s >= 0 && s < hangulCount {
+		runes = append(runes, jamoLBase+s/(jamoVCount*jamoTCount), jamoVBase+s%(jamoVCount*jamoTCount)/jamoTCount)
+		if t := s % jamoTCount; t != 0 {
+			runes = append(runes, jamoTBase+t)
+		}
+		return runes
+	}
+	d := lookupDecomposition(r, m)
+	if d == "" {
+		return append(runes, r)
+	}
+	for _, c := range d {
+		runes = append(runes, c)
+	}
+	return runes
+}
+
+// firstDecomposed returns the first rune of the decomposition of r under m.
+func firstDecomposed(r rune, m Mapping) rune {
+	if s := r - hangulBase; s >= 0 && s < hangulCount {
+		return jamoLBase + s/(jamoVCount*jamoTCount)
+	}
+	if d := lookupDecomposition(r, m); d != "" {
+		r, _ = utf8.DecodeRuneInString(d)
+	}
+	return r
+}
+
+// lookupDecomposition returns the full decomposition of r under m, or "" if it is r itself or not in the
+// table.
+func lookupDecomposition(r rune, m Mapping) string {
+	if r < 0xA0 {
+		return ""
+	}
+	i, found := slices.BinarySearchFunc(decompositions[:], r, func(d decomposition, r rune) int {
+		return cmp.Compare(d.r, r)
+	})
+	if !found {
+		return ""
+	}
+	d := decompositions[i]
+	if m&mapCompat != 0 && d.compat != "" {
+		return d.compat
+	}
+	return d.canon
+}
+
+// combiningClassOf returns the canonical combining class of r. Starters have class 0.
+func combiningClassOf(r rune) uint8 {
+	if r < 0x300 {
+		return 0
+	}
+	i, _ := slices.BinarySearchFunc(combiningClasses[:], r, func(c combiningClass, r rune) int {
+		return cmp.Compare(c.hi, r)
+	})
+	if i < len(combiningClasses) && combiningClasses[i].lo <= r {
+		return combiningClasses[i].class
+	}
+	return 0
+}
+
+// composesBackward reports whether the starter r composes with the rune before it, as a Hangul vowel or
+// trailing consonant does, so that the two are one segment.
+func composesBackward(r rune) bool {
+	if r >= jamoVBase && r < jamoVBase+jamoVCount || r > jamoTBase && r < jamoTBase+jamoTCount {
+		return true
+	}
+	_, found := slices.BinarySearch(combinesBackward[:], r)
+	return found
+}
+
+// orderCanonically sorts each run of non-starters in runes by combining class, keeping runes of the same
+// class in order.
+func orderCanonically(runes []rune) {
+	for i := 1; i < len(runes); i++ {
+		c := combiningClassOf(runes[i])
+		if c == 0 {
+			continue
+		}
+		for j := i; j > 0; j-- {
+			p := combiningClassOf(runes[j-1])
+			if p == 0 || p <= c {
+				break
+			}
+			runes[j-1], runes[j] = runes[j], runes[j-1]
+		}
+	}
+}
@@ -0,0 +1,1779 @@
+// Code generated by mknorm from UnicodeData.txt 14.0.0; DO NOT EDIT.
+
+package engine
+
+// unicodeVersion is the version of the Unicode data the tables were generated from.
+const unicodeVersion = "14.0.0"
+
+// decompositions holds the full canonical and compatibility decompositions of the runes in these blocks:
+//   - Latin-1 Supplement, U+00A0 to U+00FF
+//   - Latin Extended-A, U+0100 to U+017F
+//   - Latin Extended-B, U+0180 to U+024F
+//   - Spacing Modifier Letters, U+02B0 to U+02FF
+//   - Combining Diacritical Marks, U+0300 to U+036F
+//   - Greek and Coptic, U+0370 to U+03FF
+//   - Cyrillic, U+0400 to U+04FF
+//   - Latin Extended Additional, U+1E00 to U+1EFF
+//   - Greek Extended, U+1F00 to U+1FFF
+//   - General Punctuation, U+2000 to U+206F
+//   - Superscripts and Subscripts, U+2070 to U+209F
+//   - Letterlike Symbols, U+2100 to U+214F
+//   - Number Forms, U+2150 to U+218F
+//   - Alphabetic Presentation Forms, U+FB00 to U+FB4F
+//   - Halfwidth and Fullwidth Forms, U+FF00 to U+FFEF
+var decompositions = [...]decomposition{
+	{0x00A0, "", " "},
+	{0x00A8, "", " \u0308"},
+	{0x00AA, "", "a"},
+	{0x00AF, "", " \u0304"},
+	{0x00B2, "", "2"},
+	{0x00B3, "", "3"},
+	{0x00B4, "", " \u0301"},
+	{0x00B5, "", "\u03bc"},
+	{0x00B8, "", " \u0327"},
+	{0x00B9, "", "1"},
+	{0x00BA, "", "o"},
+	{0x00BC, "", "1\u20444"},
+	{0x00BD, "", "1\u20442"},
+	{0x00BE, "", "3\u20444"},
+	{0x00C0, "A\u0300", ""},
+	{0x00C1, "A\u0301", ""},
+	{0x00C2, "A\u0302", ""},
+	{0x00C3, "A\u0303", ""},
+	{0x00C4, "A\u0308", ""},
+	{0x00C5, "A\u030a", ""},
+	{0x00C7, "C\u0327", ""},
+	{0x00C8, "E\u0300", ""},
+	{0x00C9, "E\u0301", ""},
+	{0x00CA, "E\u0302", ""},
+	{0x00CB, "E\u0308", ""},
+	{0x00CC, "I\u0300", ""},
+	{0x00CD, "I\u0301", ""},
+	{0x00CE, "I\u0302", ""},
+	{0x00CF, "I\u0308", ""},
+	{0x00D1, "N\u0303", ""},
+	{0x00D2, "O\u0300", ""},
+	{0x00D3, "O\u0301", ""},
+	{0x00D4, "O\u0302", ""},
+	{0x00D5, "O\u0303", ""},
+	{0x00D6, "O\u0308", ""},
+	{0x00D9, "U\u0300", ""},
+	{0x00DA, "U\u0301", ""},
+	{0x00DB, "U\u0302", ""},
+	{0x00DC, "U\u0308", ""},
+	{0x00DD, "Y\u0301", ""},
+	{0x00E0, "a\u0300", ""},
+	{0x00E1, "a\u0301", ""},
+	{0x00E2, "a\u0302", ""},
+	{0x00E3, "a\u0303", ""},
+	{0x00E4, "a\u0308", ""},
+	{0x00E5, "a\u030a", ""},
+	{0x00E7, "c\u0327", ""},
+	{0x00E8, "e\u0300", ""},
+	{0x00E9, "e\u0301", ""},
+	{0x00EA, "e\u0302", ""},
+	{0x00EB, "e\u0308", ""},
+	{0x00EC, "i\u0300", ""},
+	{0x00ED, "i\u0301", ""},
+	{0x00EE, "i\u0302", ""},
+	{0x00EF, "i\u0308", ""},
+	{0x00F1, "n\u0303", ""},
+	{0x00F2, "o\u0300", ""},
+	{0x00F3, "o\u0301", ""},
+	{0x00F4, "o\u0302", ""},
+	{0x00F5, "o\u0303", ""},
+	{0x00F6, "o\u0308", ""},
+	{0x00F9, "u\u0300", ""},
+	{0x00FA, "u\u0301", ""},
+	{0x00FB, "u\u0302", ""},
+	{0x00FC, "u\u0308", ""},
+	{0x00FD, "y\u0301", ""},
+	{0x00FF, "y\u0308", ""},
+	{0x0100, "A\u0304", ""},
+	{0x0101, "a\u0304", ""},
+	{0x0102, "A\u0306", ""},
+	{0x0103, "a\u0306", ""},
+	{0x0104, "A\u0328", ""},
+	{0x0105, "a\u0328", ""},
+	{0x0106, "C\u0301", ""},
+	{0x0107, "c\u0301", ""},
+	{0x0108, "C\u0302", ""},
+	{0x0109, "c\u0302", ""},
+	{0x010A, "C\u0307", ""},
+	{0x010B, "c\u0307", ""},
+	{0x010C, "C\u030c", ""},
+	{0x010D, "c\u030c", ""},
+	{0x010E, "D\u030c", ""},
+	{0x010F, "d\u030c", ""},
+	{0x0112, "E\u0304", ""},
+	{0x0113, "e\u0304", ""},
+	{0x0114, "E\u0306", ""},
+	{0x0115, "e\u0306", ""},
+	{0x0116, "E\u0307", ""},
+	{0x0117, "e\u0307", ""},
+	{0x0118, "E\u0328", ""},
+	{0x0119, "e\u0328", ""},
+	{0x011A, "E\u030c", ""},
+	{0x011B, "e\u030c", ""},
+	{0x011C, "G\u0302", ""},
+	{0x011D, "g\u0302", ""},
+	{0x011E, "G\u0306", ""},
+	{0x011F, "g\u0306", ""},
+	{0x0120, "G\u0307", ""},
+	{0x0121, "g\u0307", ""},
+	{0x0122, "G\u0327", ""},
+	{0x0123, "g\u0327", ""},
+	{0x0124, "H\u0302", ""},
+	{0x0125, "h\u0302", ""},
+	{0x0128, "I\u0303", ""},
+	{0x0129, "i\u0303", ""},
+	{0x012A, "I\u0304", ""},
+	{0x012B, "i\u0304", ""},
+	{0x012C, "I\u0306", ""},
+	{0x012D, "i\u0306", ""},
+	{0x012E, "I\u0328", ""},
+	{0x012F, "i\u0328", ""},
+	{0x0130, "I\u0307", ""},
+	{0x0132, "", "IJ"},
+	{0x0133, "", "ij"},
+	{0x0134, "J\u0302", ""},
+	{0x0135, "j\u0302", ""},
+	{0x0136, "K\u0327", ""},
+	{0x0137, "k\u0327", ""},
+	{0x0139, "L\u0301", ""},
+	{0x013A, "l\u0301", ""},
+	{0x013B, "L\u0327", ""},
+	{0x013C, "l\u0327", ""},
+	{0x013D, "L\u030c", ""},
+	{0x013E, "l\u030c", ""},
+	{0x013F, "", "L\u00b7"},
+	{0x0140, "", "l\u00b7"},
+	{0x0143, "N\u0301", ""},
+	{0x0144, "n\u0301", ""},
+	{0x0145, "N\u0327", ""},
+	{0x0146, "n\u0327", ""},
+	{0x0147, "N\u030c", ""},
+	{0x0148, "n\u030c", ""},
+	{0x0149, "", "\u02bcn"},
+	{0x014C, "O\u0304", ""},
+	{0x014D, "o\u0304", ""},
+	{0x014E, "O\u0306", ""},
+	{0x014F, "o\u0306", ""},
+	{0x0150, "O\u030b", ""},
+	{0x0151, "o\u030b", ""},
+	{0x0154, "R\u0301", ""},
+	{0x0155, "r\u0301", ""},
+	{0x0156, "R\u0327", ""},
+	{0x0157, "r\u0327", ""},
+	{0x0158, "R\u030c", ""},
+	{0x0159, "r\u030c", ""},
+	{0x015A, "S\u0301", ""},
+	{0x015B, "s\u0301", ""},
+	{0x015C, "S\u0302", ""},
+	{0x015D, "s\u0302", ""},
+	{0x015E, "S\u0327", ""},
+	{0x015F, "s\u0327", ""},
+	{0x0160, "S\u030c", ""},
+	{0x0161, "s\u030c", ""},
+	{0x0162, "T\u0327", ""},
+	{0x0163, "t\u0327", ""},
+	{0x0164, "T\u030c", ""},
+	{0x0165, "t\u030c", ""},
+	{0x0168, "U\u0303", ""},
+	{0x0169, "u\u0303", ""},
+	{0x016A, "U\u0304", ""},
+	{0x016B, "u\u0304", ""},
+	{0x016C, "U\u0306", ""},
+	{0x016D, "u\u0306", ""},
+	{0x016E, "U\u030a", ""},
+	{0x016F, "u\u030a", ""},
+	{0x0170, "U\u030b", ""},
+	{0x0171, "u\u030b", ""},
+	{0x0172, "U\u0328", ""},
+	{0x0173, "u\u0328", ""},
+	{0x0174, "W\u0302", ""},
+	{0x0175, "w\u0302", ""},
+	{0x0176, "Y\u0302", ""},
+	{0x0177, "y\u0302", ""},
+	{0x0178, "Y\u0308", ""},
+	{0x0179, "Z\u0301", ""},
+	{0x017A, "z\u0301", ""},
+	{0x017B, "Z\u0307", ""},
+	{0x017C, "z\u0307", ""},
+	{0x017D, "Z\u030c", ""},
+	{0x017E, "z\u030c", ""},
+	{0x017F, "", "s"},
+	{0x01A0, "O\u031b", ""},
+	{0x01A1, "o\u031b", ""},
+	{0x01AF, "U\u031b", ""},
+	{0x01B0, "u\u031b", ""},
+	{0x01C4, "", "DZ\u030c"},
+	{0x01C5, "", "Dz\u030c"},
+	{0x01C6, "", "dz\u030c"},
+	{0x01C7, "", "LJ"},
+	{0x01C8, "", "Lj"},
+	{0x01C9, "", "lj"},
+	{0x01CA, "", "NJ"},
+	{0x01CB, "", "Nj"},
+	{0x01CC, "", "nj"},
+	{0x01CD, "A\u030c", ""},
+	{0x01CE, "a\u030c", ""},
+	{0x01CF, "I\u030c", ""},
+	{0x01D0, "i\u030c", ""},
+	{0x01D1, "O\u030c", ""},
+	{0x01D2, "o\u030c", ""},
+	{0x01D3, "U\u030c", ""},
+	{0x01D4, "u\u030c", ""},
+	{0x01D5, "U\u0308\u0304", ""},
+	{0x01D6, "u\u0308\u0304", ""},
+	{0x01D7, "U\u0308\u0301", ""},
+	{0x01D8, "u\u0308\u0301", ""},
+	{0x01D9, "U\u0308\u030c", ""},
+	{0x01DA, "u\u0308\u030c", ""},
+	{0x01DB, "U\u0308\u0300", ""},
+	{0x01DC, "u\u0308\u0300", ""},
+	{0x01DE, "A\u0308\u0304", ""},
+	{0x01DF, "a\u0308\u0304", ""},
+	{0x01E0, "A\u0307\u0304", ""},
+	{0x01E1, "a\u0307\u0304", ""},
+	{0x01E2, "\u00c6\u0304", ""},
+	{0x01E3, "\u00e6\u0304", ""},
+	{0x01E6, "G\u030c", ""},
+	{0x01E7, "g\u030c", ""},
+	{0x01E8, "K\u030c", ""},
+	{0x01E9, "k\u030c", ""},
+	{0x01EA, "O\u0328", ""},
+	{0x01EB, "o\u0328", ""},
+	{0x01EC, "O\u0328\u0304", ""},
+	{0x01ED, "o\u0328\u0304", ""},
+	{0x01EE, "\u01b7\u030c", ""},
+	{0x01EF, "\u0292\u030c", ""},
+	{0x01F0, "j\u030c", ""},
+	{0x01F1, "", "DZ"},
+	{0x01F2, "", "Dz"},
+	{0x01F3, "", "dz"},
+	{0x01F4, "G\u0301", ""},
+	{0x01F5, "g\u0301", ""},
+	{0x01F8, "N\u0300", ""},
+	{0x01F9, "n\u0300", ""},
+	{0x01FA, "A\u030a\u0301", ""},
+	{0x01FB, "a\u030a\u0301", ""},
+	{0x01FC, "\u00c6\u0301", ""},
+	{0x01FD, "\u00e6\u0301", ""},
+	{0x01FE, "\u00d8\u0301", ""},
+	{0x01FF, "\u00f8\u0301", ""},
+	{0x0200, "A\u030f", ""},
+	{0x0201, "a\u030f", ""},
+	{0x0202, "A\u0311", ""},
+	{0x0203, "a\u0311", ""},
+	{0x0204, "E\u030f", ""},
+	{0x0205, "e\u030f", ""},
+	{0x0206, "E\u0311", ""},
+	{0x0207, "e\u0311", ""},
+	{0x0208, "I\u030f", ""},
+	{0x0209, "i\u030f", ""},
+	{0x020A, "I\u0311", ""},
+	{0x020B, "i\u0311", ""},
+	{0x020C, "O\u030f", ""},
+	{0x020D, "o\u030f", ""},
+	{0x020E, "O\u0311", ""},
+	{0x020F, "o\u0311", ""},
+	{0x0210, "R\u030f", ""},
+	{0x0211, "r\u030f", ""},
+	{0x0212, "R\u0311", ""},
+	{0x0213, "r\u0311", ""},
+	{0x0214, "U\u030f", ""},
+	{0x0215, "u\u030f", ""},
+	{0x0216, "U\u0311", ""},
+	{0x0217, "u\u0311", ""},
+	{0x0218, "S\u0326", ""},
+	{0x0219, "s\u0326", ""},
+	{0x021A, "T\u0326", ""},
+	{0x021B, "t\u0326", ""},
+	{0x021E, "H\u030c", ""},
+	{0x021F, "h\u030c", ""},
+	{0x0226, "A\u0307", ""},
+	{0x0227, "a\u0307", ""},
+	{0x0228, "E\u0327", ""},
+	{0x0229, "e\u0327", ""},
+	{0x022A, "O\u0308\u0304", ""},
+	{0x022B, "o\u0308\u0304", ""},
+	{0x022C, "O\u0303\u0304", ""},
+	{0x022D, "o\u0303\u0304", ""},
+	{0x022E, "O\u0307", ""},
+	{0x022F, "o\u0307", ""},
+	{0x0230, "O\u0307\u0304", ""},
+	{0x0231, "o\u0307\u0304", ""},
+	{0x0232, "Y\u0304", ""},
+	{0x0233, "y\u0304", ""},
+	{0x02B0, "", "h"},
+	{0x02B1, "", "\u0266"},
+	{0x02B2, "", "j"},
+	{0x02B3, "", "r"},
+	{0x02B4, "", "\u0279"},
+	{0x02B5, "", "\u027b"},
+	{0x02B6, "", "\u0281"},
+	{0x02B7, "", "w"},
+	{0x02B8, "", "y"},
+	{0x02D8, "", " \u0306"},
+	{0x02D9, "", " \u0307"},
+	{0x02DA, "", " \u030a"},
+	{0x02DB, "", " \u0328"},
+	{0x02DC, "", " \u0303"},
+	{0x02DD, "", " \u030b"},
+	{0x02E0, "", "\u0263"},
+	{0x02E1, "", "l"},
+	{0x02E2, "", "s"},
+	{0x02E3, "", "x"},
+	{0x02E4, "", "\u0295"},
+	{0x0340, "\u0300", ""},
+	{0x0341, "\u0301", ""},
+	{0x0343, "\u0313", ""},
+	{0x0344, "\u0308\u0301", ""},
+	{0x0374, "\u02b9", ""},
+	{0x037A, "", " \u0345"},
+	{0x037E, ";", ""},
+	{0x0384, "", " \u0301"},
+	{0x0385, "\u00a8\u0301", " \u0308\u0301"},
+	{0x0386, "\u0391\u0301", ""},
+	{0x0387, "\u00b7", ""},
+	{0x0388, "\u0395\u0301", ""},
+	{0x0389, "\u0397\u0301", ""},
+	{0x038A, "\u0399\u0301", ""},
+	{0x038C, "\u039f\u0301", ""},
+	{0x038E, "\u03a5\u0301", ""},
+	{0x038F, "\u03a9\u0301", ""},
+	{0x0390, "\u03b9\u0308\u0301", ""},
+	{0x03AA, "\u0399\u0308", ""},
+	{0x03AB, "\u03a5\u0308", ""},
+	{0x03AC, "\u03b1\u0301", ""},
+	{0x03AD, "\u03b5\u0301", ""},
+	{0x03AE, "\u03b7\u0301", ""},
+	{0x03AF, "\u03b9\u0301", ""},
+	{0x03B0, "\u03c5\u0308\u0301", ""},
+	{0x03CA, "\u03b9\u0308", ""},
+	{0x03CB, "\u03c5\u0308", ""},
+	{0x03CC, "\u03bf\u0301", ""},
+	{0x03CD, "\u03c5\u0301", ""},
+	{0x03CE, "\u03c9\u0301", ""},
+	{0x03D0, "", "\u03b2"},
+	{0x03D1, "", "\u03b8"},
+	{0x03D2, "", "\u03a5"},
+	{0x03D3, "\u03d2\u0301", "\u03a5\u0301"},
+	{0x03D4, "\u03d2\u0308", "\u03a5\u0308"},
+	{0x03D5, "", "\u03c6"},
+	{0x03D6, "", "\u03c0"},
+	{0x03F0, "", "\u03ba"},
+	{0x03F1, "", "\u03c1"},
+	{0x03F2, "", "\u03c2"},
+	{0x03F4, "", "\u0398"},
+	{0x03F5, "", "\u03b5"},
+	{0x03F9, "", "\u03a3"},
+	{0x0400, "\u0415\u0300", ""},
+	{0x0401, "\u0415\u0308", ""},
+	{0x0403, "\u0413\u0301", ""},
+	{0x0407, "\u0406\u0308", ""},
+	{0x040C, "\u041a\u0301", ""},
+	{0x040D, "\u0418\u0300", ""},
+	{0x040E, "\u0423\u0306", ""},
+	{0x0419, "\u0418\u0306", ""},
+	{0x0439, "\u0438\u0306", ""},
+	{0x0450, "\u0435\u0300", ""},
+	{0x0451, "\u0435\u0308", ""},
+	{0x0453, "\u0433\u0301", ""},
+	{0x0457, "\u0456\u0308", ""},
+	{0x045C, "\u043a\u0301", ""},
+	{0x045D, "\u0438\u0300", ""},
+	{0x045E, "\u0443\u0306", ""},
+	{0x0476, "\u0474\u030f", ""},
+	{0x0477, "\u0475\u030f", ""},
+	{0x04C1, "\u0416\u0306", ""},
+	{0x04C2, "\u0436\u0306", ""},
+	{0x04D0, "\u0410\u0306", ""},
+	{0x04D1, "\u0430\u0306", ""},
+	{0x04D2, "\u0410\u0308", ""},
+	{0x04D3, "\u0430\u0308", ""},
+	{0x04D6, "\u0415\u0306", ""},
+	{0x04D7, "\u0435\u0306", ""},
+	{0x04DA, "\u04d8\u0308", ""},
+	{0x04DB, "\u04d9\u0308", ""},
+	{0x04DC, "\u0416\u0308", ""},
+	{0x04DD, "\u0436\u0308", ""},
+	{0x04DE, "\u0417\u0308", ""},
+	{0x04DF, "\u0437\u0308", ""},
+	{0x04E2, "\u0418\u0304", ""},
+	{0x04E3, "\u0438\u0304", ""},
+	{0x04E4, "\u0418\u0308", ""},
+	{0x04E5, "\u0438\u0308", ""},
+	{0x04E6, "\u041e\u0308", ""},
+	{0x04E7, "\u043e\u0308", ""},
+	{0x04EA, "\u04e8\u0308", ""},
+	{0x04EB, "\u04e9\u0308", ""},
+	{0x04EC, "\u042d\u0308", ""},
+	{0x04ED, "\u044d\u0308", ""},
+	{0x04EE, "\u0423\u0304", ""},
+	{0x04EF, "\u0443\u0304", ""},
+	{0x04F0, "\u0423\u0308", ""},
+	{0x04F1, "\u0443\u0308", ""},
+	{0x04F2, "\u0423\u030b", ""},
+	{0x04F3, "\u0443\u030b", ""},
+	{0x04F4, "\u0427\u0308", ""},
+	{0x04F5, "\u0447\u0308", ""},
+	{0x04F8, "\u042b\u0308", ""},
+	{0x04F9, "\u044b\u0308", ""},
+	{0x1E00, "A\u0325", ""},
+	{0x1E01, "a\u0325", ""},
+	{0x1E02, "B\u0307", ""},
+	{0x1E03, "b\u0307", ""},
+	{0x1E04, "B\u0323", ""},
+	{0x1E05, "b\u0323", ""},
+	{0x1E06, "B\u0331", ""},
+	{0x1E07, "b\u0331", ""},
+	{0x1E08, "C\u0327\u0301", ""},
+	{0x1E09, "c\u0327\u0301", ""},
+	{0x1E0A, "D\u0307", ""},
+	{0x1E0B, "d\u0307", ""},
+	{0x1E0C, "D\u0323", ""},
+	{0x1E0D, "d\u0323", ""},
+	{0x1E0E, "D\u0331", ""},
+	{0x1E0F, "d\u0331", ""},
+	{0x1E10, "D\u0327", ""},
+	{0x1E11, "d\u0327", ""},
+	{0x1E12, "D\u032d", ""},
+	{0x1E13, "d\u032d", ""},
+	{0x1E14, "E\u0304\u0300", ""},
+	{0x1E15, "e\u0304\u0300", ""},
+	{0x1E16, "E\u0304\u0301", ""},
+	{0x1E17, "e\u0304\u0301", ""},
+	{0x1E18, "E\u032d", ""},
+	{0x1E19, "e\u032d", ""},
+	{0x1E1A, "E\u0330", ""},
+	{0x1E1B, "e\u0330", ""},
+	{0x1E1C, "E\u0327\u0306", ""},
+	{0x1E1D, "e\u0327\u0306", ""},
+	{0x1E1E, "F\u0307", ""},
+	{0x1E1F, "f\u0307", ""},
+	{0x1E20, "G\u0304", ""},
+	{0x1E21, "g\u0304", ""},
+	{0x1E22, "H\u0307", ""},
+	{0x1E23, "h\u0307", ""},
+	{0x1E24, "H\u0323", ""},
+	{0x1E25, "h\u0323", ""},
+	{0x1E26, "H\u0308", ""},
+	{0x1E27, "h\u0308", ""},
+	{0x1E28, "H\u0327", ""},
+	{0x1E29, "h\u0327", ""},
+	{0x1E2A, "H\u032e", ""},
+	{0x1E2B, "h\u032e", ""},
+	{0x1E2C, "I\u0330", ""},
+	{0x1E2D, "i\u0330", ""},
+	{0x1E2E, "I\u0308\u0301", ""},
+	{0x1E2F, "i\u0308\u0301", ""},
+	{0x1E30, "K\u0301", ""},
+	{0x1E31, "k\u0301", ""},
+	{0x1E32, "K\u0323", ""},
+	{0x1E33, "k\u0323", ""},
+	{0x1E34, "K\u0331", ""},
+	{0x1E35, "k\u0331", ""},
+	{0x1E36, "L\u0323", ""},
+	{0x1E37, "l\u0323", ""},
+	{0x1E38, "L\u0323\u0304", ""},
+	{0x1E39, "l\u0323\u0304", ""},
+	{0x1E3A, "L\u0331", ""},
+	{0x1E3B, "l\u0331", ""},
+	{0x1E3C, "L\u032d", ""},
+	{0x1E3D, "l\u032d", ""},
+	{0x1E3E, "M\u0301", ""},
+	{0x1E3F, "m\u0301", ""},
+	{0x1E40, "M\u0307", ""},
+	{0x1E41, "m\u0307", ""},
+	{0x1E42, "M\u0323", ""},
+	{0x1E43, "m\u0323", ""},
+	{0x1E44, "N\u0307", ""},
+	{0x1E45, "n\u0307", ""},
+	{0x1E46, "N\u0323", ""},
+	{0x1E47, "n\u0323", ""},
+	{0x1E48, "N\u0331", ""},
+	{0x1E49, "n\u0331", ""},
+	{0x1E4A, "N\u032d", ""},
+	{0x1E4B, "n\u032d", ""},
+	{0x1E4C, "O\u0303\u0301", ""},
+	{0x1E4D, "o\u0303\u0301", ""},
+	{0x1E4E, "O\u0303\u0308", ""},
+	{0x1E4F, "o\u0303\u0308", ""},
+	{0x1E50, "O\u0304\u0300", ""},
+	{0x1E51, "o\u0304\u0300", ""},
+	{0x1E52, "O\u0304\u0301", ""},
+	{0x1E53, "o\u0304\u0301", ""},
+	{0x1E54, "P\u0301", ""},
+	{0x1E55, "p\u0301", ""},
+	{0x1E56, "P\u0307", ""},
+	{0x1E57, "p\u0307", ""},
+	{0x1E58, "R\u0307", ""},
+	{0x1E59, "r\u0307", ""},
+	{0x1E5A, "R\u0323", ""},
+	{0x1E5B, "r\u0323", ""},
+	{0x1E5C, "R\u0323\u0304", ""},
+	{0x1E5D, "r\u0323\u0304", ""},
+	{0x1E5E, "R\u0331", ""},
+	{0x1E5F, "r\u0331", ""},
+	{0x1E60, "S\u0307", ""},
+	{0x1E61, "s\u0307", ""},
+	{0x1E62, "S\u0323", ""},
+	{0x1E63, "s\u0323", ""},
+	{0x1E64, "S\u0301\u0307", ""},
+	{0x1E65, "s\u0301\u0307", ""},
+	{0x1E66, "S\u030c\u0307", ""},
+	{0x1E67, "s\u030c\u0307", ""},
+	{0x1E68, "S\u0323\u0307", ""},
+	{0x1E69, "s\u0323\u0307", ""},
+	{0x1E6A, "T\u0307", ""},
+	{0x1E6B, "t\u0307", ""},
+	{0x1E6C, "T\u0323", ""},
+	{0x1E6D, "t\u0323", ""},
+	{0x1E6E, "T\u0331", ""},
+	{0x1E6F, "t\u0331", ""},
+	{0x1E70, "T\u032d", ""},
+	{0x1E71, "t\u032d", ""},
+	{0x1E72, "U\u0324", ""},
+	{0x1E73, "u\u0324", ""},
+	{0x1E74, "U\u0330", ""},
+	{0x1E75, "u\u0330", ""},
+	{0x1E76, "U\u032d", ""},
+	{0x1E77, "u\u032d", ""},
+	{0x1E78, "U\u0303\u0301", ""},
+	{0x1E79, "u\u0303\u0301", ""},
+	{0x1E7A, "U\u0304\u0308", ""},
+	{0x1E7B, "u\u0304\u0308", ""},
+	{0x1E7C, "V\u0303", ""},
+	{0x1E7D, "v\u0303", ""},
+	{0x1E7E, "V\u0323", ""},
+	{0x1E7F, "v\u0323", ""},
+	{0x1E80, "W\u0300", ""},
+	{0x1E81, "w\u0300", ""},
+	{0x1E82, "W\u0301", ""},
+	{0x1E83, "w\u0301", ""},
+	{0x1E84, "W\u0308", ""},
+	{0x1E85, "w\u0308", ""},
+	{0x1E86, "W\u0307", ""},
+	{0x1E87, "w\u0307", ""},
+	{0x1E88, "W\u0323", ""},
+	{0x1E89, "w\u0323", ""},
+	{0x1E8A, "X\u0307", ""},
+	{0x1E8B, "x\u0307", ""},
+	{0x1E8C, "X\u0308", ""},
+	{0x1E8D, "x\u0308", ""},
+	{0x1E8E, "Y\u0307", ""},
+	{0x1E8F, "y\u0307", ""},
+	{0x1E90, "Z\u0302", ""},
+	{0x1E91, "z\u0302", ""},
+	{0x1E92, "Z\u0323", ""},
+	{0x1E93, "z\u0323", ""},
+	{0x1E94, "Z\u0331", ""},
+	{0x1E95, "z\u0331", ""},
+	{0x1E96, "h\u0331", ""},
+	{0x1E97, "t\u0308", ""},
+	{0x1E98, "w\u030a", ""},
+	{0x1E99, "y\u030a", ""},
+	{0x1E9A, "", "a\u02be"},
+	{0x1E9B, "\u017f\u0307", "s\u0307"},
+	{0x1EA0, "A\u0323", ""},
+	{0x1EA1, "a\u0323", ""},
+	{0x1EA2, "A\u0309", ""},
+	{0x1EA3, "a\u0309", ""},
+	{0x1EA4, "A\u0302\u0301", ""},
+	{0x1EA5, "a\u0302\u0301", ""},
+	{0x1EA6, "A\u0302\u0300", ""},
+	{0x1EA7, "a\u0302\u0300", ""},
+	{0x1EA8, "A\u0302\u0309", ""},
+	{0x1EA9, "a\u0302\u0309", ""},
+	{0x1EAA, "A\u0302\u0303", ""},
+	{0x1EAB, "a\u0302\u0303", ""},
+	{0x1EAC, "A\u0323\u0302", ""},
+	{0x1EAD, "a\u0323\u0302", ""},
+	{0x1EAE, "A\u0306\u0301", ""},
+	{0x1EAF, "a\u0306\u0301", ""},
+	{0x1EB0, "A\u0306\u0300", ""},
+	{0x1EB1, "a\u0306\u0300", ""},
+	{0x1EB2, "A\u0306\u0309", ""},
+	{0x1EB3, "a\u0306\u0309", ""},
+	{0x1EB4, "A\u0306\u0303", ""},
+	{0x1EB5, "a\u0306\u0303", ""},
+	{0x1EB6, "A\u0323\u0306", ""},
+	{0x1EB7, "a\u0323\u0306", ""},
+	{0x1EB8, "E\u0323", ""},
+	{0x1EB9, "e\u0323", ""},
+	{0x1EBA, "E\u0309", ""},
+	{0x1EBB, "e\u0309", ""},
+	{0x1EBC, "E\u0303", ""},
+	{0x1EBD, "e\u0303", ""},
+	{0x1EBE, "E\u0302\u0301", ""},
+	{0x1EBF, "e\u0302\u0301", ""},
+	{0x1EC0, "E\u0302\u0300", ""},
+	{0x1EC1, "e\u0302\u0300", ""},
+	{0x1EC2, "E\u0302\u0309", ""},
+	{0x1EC3, "e\u0302\u0309", ""},
+	{0x1EC4, "E\u0302\u0303", ""},
+	{0x1EC5, "e\u0302\u0303", ""},
+	{0x1EC6, "E\u0323\u0302", ""},
+	{0x1EC7, "e\u0323\u0302", ""},
+	{0x1EC8, "I\u0309", ""},
+	{0x1EC9, "i\u0309", ""},
+	{0x1ECA, "I\u0323", ""},
+	{0x1ECB, "i\u0323", ""},
+	{0x1ECC, "O\u0323", ""},
+	{0x1ECD, "o\u0323", ""},
+	{0x1ECE, "O\u0309", ""},
+	{0x1ECF, "o\u0309", ""},
+	{0x1ED0, "O\u0302\u0301", ""},
+	{0x1ED1, "o\u0302\u0301", ""},
+	{0x1ED2, "O\u0302\u0300", ""},
+	{0x1ED3, "o\u0302\u0300", ""},
+	{0x1ED4, "O\u0302\u0309", ""},
+	{0x1ED5, "o\u0302\u0309", ""},
+	{0x1ED6, "O\u0302\u0303", ""},
+	{0x1ED7, "o\u0302\u0303", ""},
+	{0x1ED8, "O\u0323\u0302", ""},
+	{0x1ED9, "o\u0323\u0302", ""},
+	{0x1EDA, "O\u031b\u0301", ""},
+	{0x1EDB, "o\u031b\u0301", ""},
+	{0x1EDC, "O\u031b\u0300", ""},
+	{0x1EDD, "o\u031b\u0300", ""},
+	{0x1EDE, "O\u031b\u0309", ""},
+	{0x1EDF, "o\u031b\u0309", ""},
+	{0x1EE0, "O\u031b\u0303", ""},
+	{0x1EE1, "o\u031b\u0303", ""},
+	{0x1EE2, "O\u031b\u0323", ""},
+	{0x1EE3, "o\u031b\u0323", ""},
+	{0x1EE4, "U\u0323", ""},
+	{0x1EE5, "u\u0323", ""},
+	{0x1EE6, "U\u0309", ""},
+	{0x1EE7, "u\u0309", ""},
+	{0x1EE8, "U\u031b\u0301", ""},
+	{0x1EE9, "u\u031b\u0301", ""},
+	{0x1EEA, "U\u031b\u0300", ""},
+	{0x1EEB, "u\u031b\u0300", ""},
+	{0x1EEC, "U\u031b\u0309", ""},
+	{0x1EED, "u\u031b\u0309", ""},
+	{0x1EEE, "U\u031b\u0303", ""},
+	{0x1EEF, "u\u031b\u0303", ""},
+	{0x1EF0, "U\u031b\u0323", ""},
+	{0x1EF1, "u\u031b\u0323", ""},
+	{0x1EF2, "Y\u0300", ""},
+	{0x1EF3, "y\u0300", ""},
+	{0x1EF4, "Y\u0323", ""},
+	{0x1EF5, "y\u0323", ""},
+	{0x1EF6, "Y\u0309", ""},
+	{0x1EF7, "y\u0309", ""},
+	{0x1EF8, "Y\u0303", ""},
+	{0x1EF9, "y\u0303", ""},
+	{0x1F00, "\u03b1\u0313", ""},
+	{0x1F01, "\u03b1\u0314", ""},
+	{0x1F02, "\u03b1\u0313\u0300", ""},
+	{0x1F03, "\u03b1\u0314\u0300", ""},
+	{0x1F04, "\u03b1\u0313\u0301", ""},
+	{0x1F05, "\u03b1\u0314\u0301", ""},
+	{0x1F06, "\u03b1\u0313\u0342", ""},
+	{0x1F07, "\u03b1\u0314\u0342", ""},
+	{0x1F08, "\u0391\u0313", ""},
+	{0x1F09, "\u0391\u0314", ""},
+	{0x1F0A, "\u0391\u0313\u0300", ""},
+	{0x1F0B, "\u0391\u0314\u0300", ""},
+	{0x1F0C, "\u0391\u0313\u0301", ""},
+	{0x1F0D, "\u0391\u0314\u0301", ""},
+	{0x1F0E, "\u0391\u0313\u0342", ""},
+	{0x1F0F, "\u0391\u0314\u0342", ""},
+	{0x1F10, "\u03b5\u0313", ""},
+	{0x1F11, "\u03b5\u0314", ""},
+	{0x1F12, "\u03b5\u0313\u0300", ""},
+	{0x1F13, "\u03b5\u0314\u0300", ""},
+	{0x1F14, "\u03b5\u0313\u0301", ""},
+	{0x1F15, "\u03b5\u0314\u0301", ""},
+	{0x1F18, "\u0395\u0313", ""},
+	{0x1F19, "\u0395\u0314", ""},
+	{0x1F1A, "\u0395\u0313\u0300", ""},
+	{0x1F1B, "\u0395\u0314\u0300", ""},
+	{0x1F1C, "\u0395\u0313\u0301", ""},
+	{0x1F1D, "\u0395\u0314\u0301", ""},
+	{0x1F20, "\u03b7\u0313", ""},
+	{0x1F21, "\u03b7\u0314", ""},
+	{0x1F22, "\u03b7\u0313\u0300", ""},
+	{0x1F23, "\u03b7\u0314\u0300", ""},
+	{0x1F24, "\u03b7\u0313\u0301", ""},
+	{0x1F25, "\u03b7\u0314\u0301", ""},
+	{0x1F26, "\u03b7\u0313\u0342", ""},
+	{0x1F27, "\u03b7\u0314\u0342", ""},
+	{0x1F28, "\u0397\u0313", ""},
+	{0x1F29, "\u0397\u0314", ""},
+	{0x1F2A, "\u0397\u0313\u0300", ""},
+	{0x1F2B, "\u0397\u0314\u0300", ""},
+	{0x1F2C, "\u0397\u0313\u0301", ""},
+	{0x1F2D, "\u0397\u0314\u0301", ""},
+	{0x1F2E, "\u0397\u0313\u0342", ""},
+	{0x1F2F, "\u0397\u0314\u0342", ""},
+	{0x1F30, "\u03b9\u0313", ""},
+	{0x1F31, "\u03b9\u0314", ""},
+	{0x1F32, "\u03b9\u0313\u0300", ""},
+	{0x1F33, "\u03b9\u0314\u0300", ""},
+	{0x1F34, "\u03b9\u0313\u0301", ""},
+	{0x1F35, "\u03b9\u0314\u0301", ""},
+	{0x1F36, "\u03b9\u0313\u0342", ""},
+	{0x1F37, "\u03b9\u0314\u0342", ""},
+	{0x1F38, "\u0399\u0313", ""},
+	{0x1F39, "\u0399\u0314", ""},
+	{0x1F3A, "\u0399\u0313\u0300", ""},
+	{0x1F3B, "\u0399\u0314\u0300", ""},
+	{0x1F3C, "\u0399\u0313\u0301", ""},
+	{0x1F3D, "\u0399\u0314\u0301", ""},
+	{0x1F3E, "\u0399\u0313\u0342", ""},
+	{0x1F3F, "\u0399\u0314\u0342", ""},
+	{0x1F40, "\u03bf\u0313", ""},
+	{0x1F41, "\u03bf\u0314", ""},
+	{0x1F42, "\u03bf\u0313\u0300", ""},
+	{0x1F43, "\u03bf\u0314\u0300", ""},
+	{0x1F44, "\u03bf\u0313\u0301", ""},
+	{0x1F45, "\u03bf\u0314\u0301", ""},
+	{0x1F48, "\u039f\u0313", ""},
+	{0x1F49, "\u039f\u0314", ""},
+	{0x1F4A, "\u039f\u0313\u0300", ""},
+	{0x1F4B, "\u039f\u0314\u0300", ""},
+	{0x1F4C, "\u039f\u0313\u0301", ""},
+	{0x1F4D, "\u039f\u0314\u0301", ""},
+	{0x1F50, "\u03c5\u0313", ""},
+	{0x1F51, "\u03c5\u0314", ""},
+	{0x1F52, "\u03c5\u0313\u0300", ""},
+	{0x1F53, "\u03c5\u0314\u0300", ""},
+	{0x1F54, "\u03c5\u0313\u0301", ""},
+	{0x1F55, "\u03c5\u0314\u0301", ""},
+	{0x1F56, "\u03c5\u0313\u0342", ""},
+	{0x1F57, "\u03c5\u0314\u0342", ""},
+	{0x1F59, "\u03a5\u0314", ""},
+	{0x1F5B, "\u03a5\u0314\u0300", ""},
+	{0x1F5D, "\u03a5\u0314\u0301", ""},
+	{0x1F5F, "\u03a5\u0314\u0342", ""},
+	{0x1F60, "\u03c9\u0313", ""},
+	{0x1F61, "\u03c9\u0314", ""},
+	{0x1F62, "\u03c9\u0313\u0300", ""},
+	{0x1F63, "\u03c9\u0314\u0300", ""},
+	{0x1F64, "\u03c9\u0313\u0301", ""},
+	{0x1F65, "\u03c9\u0314\u0301", ""},
+	{0x1F66, "\u03c9\u0313\u0342", ""},
+	{0x1F67, "\u03c9\u0314\u0342", ""},
+	{0x1F68, "\u03a9\u0313", ""},
+	{0x1F69, "\u03a9\u0314", ""},
+	{0x1F6A, "\u03a9\u0313\u0300", ""},
+	{0x1F6B, "\u03a9\u0314\u0300", ""},
+	{0x1F6C, "\u03a9\u0313\u0301", ""},
+	{0x1F6D, "\u03a9\u0314\u0301", ""},
+	{0x1F6E, "\u03a9\u0313\u0342", ""},
+	{0x1F6F, "\u03a9\u0314\u0342", ""},
+	{0x1F70, "\u03b1\u0300", ""},
+	{0x1F71, "\u03b1\u0301", ""},
+	{0x1F72, "\u03b5\u0300", ""},
+	{0x1F73, "\u03b5\u0301", ""},
+	{0x1F74, "\u03b7\u0300", ""},
+	{0x1F75, "\u03b7\u0301", ""},
+	{0x1F76, "\u03b9\u0300", ""},
+	{0x1F77, "\u03b9\u0301", ""},
+	{0x1F78, "\u03bf\u0300", ""},
+	{0x1F79, "\u03bf\u0301", ""},
+	{0x1F7A, "\u03c5\u0300", ""},
+	{0x1F7B, "\u03c5\u0301", ""},
+	{0x1F7C, "\u03c9\u0300", ""},
+	{0x1F7D, "\u03c9\u0301", ""},
+	{0x1F80, "\u03b1\u0313\u0345", ""},
+	{0x1F81, "\u03b1\u0314\u0345", ""},
+	{0x1F82, "\u03b1\u0313\u0300\u0345", ""},
+	{0x1F83, "\u03b1\u0314\u0300\u0345", ""},
+	{0x1F84, "\u03b1\u0313\u0301\u0345", ""},
+	{0x1F85, "\u03b1\u0314\u0301\u0345", ""},
+	{0x1F86, "\u03b1\u0313\u0342\u0345", ""},
+	{0x1F87, "\u03b1\u0314\u0342\u0345", ""},
+	{0x1F88, "\u0391\u0313\u0345", ""},
+	{0x1F89, "\u0391\u0314\u0345", ""},
+	{0x1F8A, "\u0391\u0313\u0300\u0345", ""},
+	{0x1F8B, "\u0391\u0314\u0300\u0345", ""},
+	{0x1F8C, "\u0391\u0313\u0301\u0345", ""},
+	{0x1F8D, "\u0391\u0314\u0301\u0345", ""},
+	{0x1F8E, "\u0391\u0313\u0342\u0345", ""},
+	{0x1F8F, "\u0391\u0314\u0342\u0345", ""},
+	{0x1F90, "\u03b7\u0313\u0345", ""},
+	{0x1F91, "\u03b7\u0314\u0345", ""},
+	{0x1F92, "\u03b7\u0313\u0300\u0345", ""},
+	{0x1F93, "\u03b7\u0314\u0300\u0345", ""},
+	{0x1F94, "\u03b7\u0313\u0301\u0345", ""},
+	{0x1F95, "\u03b7\u0314\u0301\u0345", ""},
+	{0x1F96, "\u03b7\u0313\u0342\u0345", ""},
+	{0x1F97, "\u03b7\u0314\u0342\u0345", ""},
+	{0x1F98, "\u0397\u0313\u0345", ""},
+	{0x1F99, "\u0397\u0314\u0345", ""},
+	{0x1F9A, "\u0397\u0313\u0300\u0345", ""},
+	{0x1F9B, "\u0397\u0314\u0300\u0345", ""},
+	{0x1F9C, "\u0397\u0313\u0301\u0345", ""},
+	{0x1F9D, "\u0397\u0314\u0301\u0345", ""},
+	{0x1F9E, "\u0397\u0313\u0342\u0345", ""},
+	{0x1F9F, "\u0397\u0314\u0342\u0345", ""},
+	{0x1FA0, "\u03c9\u0313\u0345", ""},
+	{0x1FA1, "\u03c9\u0314\u0345", ""},
+	{0x1FA2, "\u03c9\u0313\u0300\u0345", ""},
+	{0x1FA3, "\u03c9\u0314\u0300\u0345", ""},
+	{0x1FA4, "\u03c9\u0313\u0301\u0345", ""},
+	{0x1FA5, "\u03c9\u0314\u0301\u0345", ""},
+	{0x1FA6, "\u03c9\u0313\u0342\u0345", ""},
+	{0x1FA7, "\u03c9\u0314\u0342\u0345", ""},
+	{0x1FA8, "\u03a9\u0313\u0345", ""},
+	{0x1FA9, "\u03a9\u0314\u0345", ""},
+	{0x1FAA, "\u03a9\u0313\u0300\u0345", ""},
+	{0x1FAB, "\u03a9\u0314\u0300\u0345", ""},
+	{0x1FAC, "\u03a9\u0313\u0301\u0345", ""},
+	{0x1FAD, "\u03a9\u0314\u0301\u0345", ""},
+	{0x1FAE, "\u03a9\u0313\u0342\u0345", ""},
+	{0x1FAF, "\u03a9\u0314\u0342\u0345", ""},
+	{0x1FB0, "\u03b1\u0306", ""},
+	{0x1FB1, "\u03b1\u0304", ""},
+	{0x1FB2, "\u03b1\u0300\u0345", ""},
+	{0x1FB3, "\u03b1\u0345", ""},
+	{0x1FB4, "\u03b1\u0301\u0345", ""},
+	{0x1FB6, "\u03b1\u0342", ""},
+	{0x1FB7, "\u03b1\u0342\u0345", ""},
+	{0x1FB8, "\u0391\u0306", ""},
+	{0x1FB9, "\u0391\u0304", ""},
+	{0x1FBA, "\u0391\u0300", ""},
+	{0x1FBB, "\u0391\u0301", ""},
+	{0x1FBC, "\u0391\u0345", ""},
+	{0x1FBD, "", " \u0313"},
+	{0x1FBE, "\u03b9", ""},
+	{0x1FBF, "", " \u0313"},
+	{0x1FC0, "", " \u0342"},
+	{0x1FC1, "\u00a8\u0342", " \u0308\u0342"},
+	{0x1FC2, "\u03b7\u0300\u0345", ""},
+	{0x1FC3, "\u03b7\u0345", ""},
+	{0x1FC4, "\u03b7\u0301\u0345", ""},
+	{0x1FC6, "\u03b7\u0342", ""},
+	{0x1FC7, "\u03b7\u0342\u0345", ""},
+	{0x1FC8, "\u0395\u0300", ""},
+	{0x1FC9, "\u0395\u0301", ""},
+	{0x1FCA, "\u0397\u0300", ""},
+	{0x1FCB, "\u0397\u0301", ""},
+	{0x1FCC, "\u0397\u0345", ""},
+	{0x1FCD, "\u1fbf\u0300", " \u0313\u0300"},
+	{0x1FCE, "\u1fbf\u0301", " \u0313\u0301"},
+	{0x1FCF, "\u1fbf\u0342", " \u0313\u0342"},
+	{0x1FD0, "\u03b9\u0306", ""},
+	{0x1FD1, "\u03b9\u0304", ""},
+	{0x1FD2, "\u03b9\u0308\u0300", ""},
+	{0x1FD3, "\u03b9\u0308\u0301", ""},
+	{0x1FD6, "\u03b9\u0342", ""},
+	{0x1FD7, "\u03b9\u0308\u0342", ""},
+	{0x1FD8, "\u0399\u0306", ""},
+	{0x1FD9, "\u0399\u0304", ""},
+	{0x1FDA, "\u0399\u0300", ""},
+	{0x1FDB, "\u0399\u0301", ""},
+	{0x1FDD, "\u1ffe\u0300", " \u0314\u0300"},
+	{0x1FDE, "\u1ffe\u0301", " \u0314\u0301"},
+	{0x1FDF, "\u1ffe\u0342", " \u0314\u0342"},
+	{0x1FE0, "\u03c5\u0306", ""},
+	{0x1FE1, "\u03c5\u0304", ""},
+	{0x1FE2, "\u03c5\u0308\u0300", ""},
+	{0x1FE3, "\u03c5\u0308\u0301", ""},
+	{0x1FE4, "\u03c1\u0313", ""},
+	{0x1FE5, "\u03c1\u0314", ""},
+	{0x1FE6, "\u03c5\u0342", ""},
+	{0x1FE7, "\u03c5\u0308\u0342", ""},
+	{0x1FE8, "\u03a5\u0306", ""},
+	{0x1FE9, "\u03a5\u0304", ""},
+	{0x1FEA, "\u03a5\u0300", ""},
+	{0x1FEB, "\u03a5\u0301", ""},
+	{0x1FEC, "\u03a1\u0314", ""},
+	{0x1FED, "\u00a8\u0300", " \u0308\u0300"},
+	{0x1FEE, "\u00a8\u0301", " \u0308\u0301"},
+	{0x1FEF, "`", ""},
+	{0x1FF2, "\u03c9\u0300\u0345", ""},
+	{0x1FF3, "\u03c9\u0345", ""},
+	{0x1FF4, "\u03c9\u0301\u0345", ""},
+	{0x1FF6, "\u03c9\u0342", ""},
+	{0x1FF7, "\u03c9\u0342\u0345", ""},
+	{0x1FF8, "\u039f\u0300", ""},
+	{0x1FF9, "\u039f\u0301", ""},
+	{0x1FFA, "\u03a9\u0300", ""},
+	{0x1FFB, "\u03a9\u0301", ""},
+	{0x1FFC, "\u03a9\u0345", ""},
+	{0x1FFD, "\u00b4", " \u0301"},
+	{0x1FFE, "", " \u0314"},
+	{0x2000, "\u2002", " "},
+	{0x2001, "\u2003", " "},
+	{0x2002, "", " "},
+	{0x2003, "", " "},
+	{0x2004, "", " "},
+	{0x2005, "", " "},
+	{0x2006, "", " "},
+	{0x2007, "", " "},
+	{0x2008, "", " "},
+	{0x2009, "", " "},
+	{0x200A, "", " "},
+	{0x2011, "", "\u2010"},
+	{0x2017, "", " \u0333"},
+	{0x2024, "", "."},
+	{0x2025, "", ".."},
+	{0x2026, "", "..."},
+	{0x202F, "", " "},
+	{0x2033, "", "\u2032\u2032"},
+	{0x2034, "", "\u2032\u2032\u2032"},
+	{0x2036, "", "\u2035\u2035"},
+	{0x2037, "", "\u2035\u2035\u2035"},
+	{0x203C, "", "!!"},
+	{0x203E, "", " \u0305"},
+	{0x2047, "", "??"},
+	{0x2048, "", "?!"},
+	{0x2049, "", "!?"},
+	{0x2057, "", "\u2032\u2032\u2032\u2032"},
+	{0x205F, "", " "},
+	{0x2070, "", "0"},
+	{0x2071, "", "i"},
+	{0x2074, "", "4"},
+	{0x2075, "", "5"},
+	{0x2076, "", "6"},
+	{0x2077, "", "7"},
+	{0x2078, "", "8"},
+	{0x2079, "", "9"},
+	{0x207A, "", "+"},
+	{0x207B, "", "\u2212"},
+	{0x207C, "", "="},
+	{0x207D, "", "("},
+	{0x207E, "", ")"},
+	{0x207F, "", "n"},
+	{0x2080, "", "0"},
+	{0x2081, "", "1"},
+	{0x2082, "", "2"},
+	{0x2083, "", "3"},
+	{0x2084, "", "4"},
+	{0x2085, "", "5"},
+	{0x2086, "", "6"},
+	{0x2087, "", "7"},
+	{0x2088, "", "8"},
+	{0x2089, "", "9"},
+	{0x208A, "", "+"},
+	{0x208B, "", "\u2212"},
+	{0x208C, "", "="},
+	{0x208D, "", "("},
+	{0x208E, "", ")"},
+	{0x2090, "", "a"},
+	{0x2091, "", "e"},
+	{0x2092, "", "o"},
+	{0x2093, "", "x"},
+	{0x2094, "", "\u0259"},
+	{0x2095, "", "h"},
+	{0x2096, "", "k"},
+	{0x2097, "", "l"},
+	{0x2098, "", "m"},
+	{0x2099, "", "n"},
+	{0x209A, "", "p"},
+	{0x209B, "", "s"},
+	{0x209C, "", "t"},
+	{0x2100, "", "a/c"},
+	{0x2101, "", "a/s"},
+	{0x2102, "", "C"},
+	{0x2103, "", "\u00b0C"},
+	{0x2105, "", "c/o"},
+	{0x2106, "", "c/u"},
+	{0x2107, "", "\u0190"},
+	{0x2109, "", "\u00b0F"},
+	{0x210A, "", "g"},
+	{0x210B, "", "H"},
+	{0x210C, "", "H"},
+	{0x210D, "", "H"},
+	{0x210E, "", "h"},
+	{0x210F, "", "\u0127"},
+	{0x2110, "", "I"},
+	{0x2111, "", "I"},
+	{0x2112, "", "L"},
+	{0x2113, "", "l"},
+	{0x2115, "", "N"},
+	{0x2116, "", "No"},
+	{0x2119, "", "P"},
+	{0x211A, "", "Q"},
+	{0x211B, "", "R"},
+	{0x211C, "", "R"},
+	{0x211D, "", "R"},
+	{0x2120, "", "SM"},
+	{0x2121, "", "TEL"},
+	{0x2122, "", "TM"},
+	{0x2124, "", "Z"},
+	{0x2126, "\u03a9", ""},
+	{0x2128, "", "Z"},
+	{0x212A, "K", ""},
+	{0x212B, "A\u030a", ""},
+	{0x212C, "", "B"},
+	{0x212D, "", "C"},
+	{0x212F, "", "e"},
+	{0x2130, "", "E"},
+	{0x2131, "", "F"},
+	{0x2133, "", "M"},
+	{0x2134, "", "o"},
+	{0x2135, "", "\u05d0"},
+	{0x2136, "", "\u05d1"},
+	{0x2137, "", "\u05d2"},
+	{0x2138, "", "\u05d3"},
+	{0x2139, "", "i"},
+	{0x213B, "", "FAX"},
+	{0x213C, "", "\u03c0"},
+	{0x213D, "", "\u03b3"},
+	{0x213E, "", "\u0393"},
+	{0x213F, "", "\u03a0"},
+	{0x2140, "", "\u2211"},
+	{0x2145, "", "D"},
+	{0x2146, "", "d"},
+	{0x2147, "", "e"},
+	{0x2148, "", "i"},
+	{0x2149, "", "j"},
+	{0x2150, "", "1\u20447"},
+	{0x2151, "", "1\u20449"},
+	{0x2152, "", "1\u204410"},
+	{0x2153, "", "1\u20443"},
+	{0x2154, "", "2\u20443"},
+	{0x2155, "", "1\u20445"},
+	{0x2156, "", "2\u20445"},
+	{0x2157, "", "3\u20445"},
+	{0x2158, "", "4\u20445"},
+	{0x2159, "", "1\u20446"},
+	{0x215A, "", "5\u20446"},
+	{0x215B, "", "1\u20448"},
+	{0x215C, "", "3\u20448"},
+	{0x215D, "", "5\u20448"},
+	{0x215E, "", "7\u20448"},
+	{0x215F, "", "1\u2044"},
+	{0x2160, "", "I"},
+	{0x2161, "", "II"},
+	{0x2162, "", "III"},
+	{0x2163, "", "IV"},
+	{0x2164, "", "V"},
+	{0x2165, "", "VI"},
+	{0x2166, "", "VII"},
+	{0x2167, "", "VIII"},
+	{0x2168, "", "IX"},
+	{0x2169, "", "X"},
+	{0x216A, "", "XI"},
+	{0x216B, "", "XII"},
+	{0x216C, "", "L"},
+	{0x216D, "", "C"},
+	{0x216E, "", "D"},
+	{0x216F, "", "M"},
+	{0x2170, "", "i"},
+	{0x2171, "", "ii"},
+	{0x2172, "", "iii"},
+	{0x2173, "", "iv"},
+	{0x2174, "", "v"},
+	{0x2175, "", "vi"},
+	{0x2176, "", "vii"},
+	{0x2177, "", "viii"},
+	{0x2178, "", "ix"},
+	{0x2179, "", "x"},
+	{0x217A, "", "xi"},
+	{0x217B, "", "xii"},
+	{0x217C, "", "l"},
+	{0x217D, "", "c"},
+	{0x217E, "", "d"},
+	{0x217F, "", "m"},
+	{0x2189, "", "0\u20443"},
+	{0xFB00, "", "ff"},
+	{0xFB01, "", "fi"},
+	{0xFB02, "", "fl"},
+	{0xFB03, "", "ffi"},
+	{0xFB04, "", "ffl"},
+	{0xFB05, "", "st"},
+	{0xFB06, "", "st"},
+	{0xFB13, "", "\u0574\u0576"},
+	{0xFB14, "", "\u0574\u0565"},
+	{0xFB15, "", "\u0574\u056b"},
+	{0xFB16, "", "\u057e\u0576"},
+	{0xFB17, "", "\u0574\u056d"},
+	{0xFB1D, "\u05d9\u05b4", ""},
+	{0xFB1F, "\u05f2\u05b7", ""},
+	{0xFB20, "", "\u05e2"},
+	{0xFB21, "", "\u05d0"},
+	{0xFB22, "", "\u05d3"},
+	{0xFB23, "", "\u05d4"},
+	{0xFB24, "", "\u05db"},
+	{0xFB25, "", "\u05dc"},
+	{0xFB26, "", "\u05dd"},
+	{0xFB27, "", "\u05e8"},
+	{0xFB28, "", "\u05ea"},
+	{0xFB29, "", "+"},
+	{0xFB2A, "\u05e9\u05c1", ""},
+	{0xFB2B, "\u05e9\u05c2", ""},
+	{0xFB2C, "\u05e9\u05bc\u05c1", ""},
+	{0xFB2D, "\u05e9\u05bc\u05c2", ""},
+	{0xFB2E, "\u05d0\u05b7", ""},
+	{0xFB2F, "\u05d0\u05b8", ""},
+	{0xFB30, "\u05d0\u05bc", ""},
+	{0xFB31, "\u05d1\u05bc", ""},
+	{0xFB32, "\u05d2\u05bc", ""},
+	{0xFB33, "\u05d3\u05bc", ""},
+	{0xFB34, "\u05d4\u05bc", ""},
+	{0xFB35, "\u05d5\u05bc", ""},
+	{0xFB36, "\u05d6\u05bc", ""},
+	{0xFB38, "\u05d8\u05bc", ""},
+	{0xFB39, "\u05d9\u05bc", ""},
+	{0xFB3A, "\u05da\u05bc", ""},
+	{0xFB3B, "\u05db\u05bc", ""},
+	{0xFB3C, "\u05dc\u05bc", ""},
+	{0xFB3E, "\u05de\u05bc", ""},
+	{0xFB40, "\u05e0\u05bc", ""},
+	{0xFB41, "\u05e1\u05bc", ""},
+	{0xFB43, "\u05e3\u05bc", ""},
+	{0xFB44, "\u05e4\u05bc", ""},
+	{0xFB46, "\u05e6\u05bc", ""},
+	{0xFB47, "\u05e7\u05bc", ""},
+	{0xFB48, "\u05e8\u05bc", ""},
+	{0xFB49, "\u05e9\u05bc", ""},
+	{0xFB4A, "\u05ea\u05bc", ""},
+	{0xFB4B, "\u05d5\u05b9", ""},
+	{0xFB4C, "\u05d1\u05bf", ""},
+	{0xFB4D, "\u05db\u05bf", ""},
+	{0xFB4E, "\u05e4\u05bf", ""},
+	{0xFB4F, "", "\u05d0\u05dc"},
+	{0xFF01, "", "!"},
+	{0xFF02, "", "\""},
+	{0xFF03, "", "#"},
+	{0xFF04, "", "$"},
+	{0xFF05, "", "%"},
+	{0xFF06, "", "&"},
+	{0xFF07, "", "'"},
+	{0xFF08, "", "("},
+	{0xFF09, "", ")"},
+	{0xFF0A, "", "*"},
+	{0xFF0B, "", "+"},
+	{0xFF0C, "", ","},
+	{0xFF0D, "", "-"},
+	{0xFF0E, "", "."},
+	{0xFF0F, "", "/"},
+	{0xFF10, "", "0"},
+	{0xFF11, "", "1"},
+	{0xFF12, "", "2"},
+	{0xFF13, "", "3"},
+	{0xFF14, "", "4"},
+	{0xFF15, "", "5"},
+	{0xFF16, "", "6"},
+	{0xFF17, "", "7"},
+	{0xFF18, "", "8"},
+	{0xFF19, "", "9"},
+	{0xFF1A, "", ":"},
+	{0xFF1B, "", ";"},
+	{0xFF1C, "", "<"},
+	{0xFF1D, "", "="},
+	{0xFF1E, "", ">"},
+	{0xFF1F, "", "?"},
+	{0xFF20, "", "@"},
+	{0xFF21, "", "A"},
+	{0xFF22, "", "B"},
+	{0xFF23, "", "C"},
+	{0xFF24, "", "D"},
+	{0xFF25, "", "E"},
+	{0xFF26, "", "F"},
+	{0xFF27, "", "G"},
+	{0xFF28, "", "H"},
+	{0xFF29, "", "I"},
+	{0xFF2A, "", "J"},
+	{0xFF2B, "", "K"},
+	{0xFF2C, "", "L"},
+	{0xFF2D, "", "M"},
+	{0xFF2E, "", "N"},
+	{0xFF2F, "", "O"},
+	{0xFF30, "", "P"},
+	{0xFF31, "", "Q"},
+	{0xFF32, "", "R"},
+	{0xFF33, "", "S"},
+	{0xFF34, "", "T"},
+	{0xFF35, "", "U"},
+	{0xFF36, "", "V"},
+	{0xFF37, "", "W"},
+	{0xFF38, "", "X"},
+	{0xFF39, "", "Y"},
+	{0xFF3A, "", "Z"},
+	{0xFF3B, "", "["},
+	{0xFF3C, "", "\\"},
+	{0xFF3D, "", "]"},
+	{0xFF3E, "", "^"},
+	{0xFF3F, "", "_"},
+	{0xFF40, "", "`"},
+	{0xFF41, "", "a"},
+	{0xFF42, "", "b"},
+	{0xFF43, "", "c"},
+	{0xFF44, "", "d"},
+	{0xFF45, "", "e"},
+	{0xFF46, "", "f"},
+	{0xFF47, "", "g"},
+	{0xFF48, "", "h"},
+	{0xFF49, "", "i"},
+	{0xFF4A, "", "j"},
+	{0xFF4B, "", "k"},
+	{0xFF4C, "", "l"},
+	{0xFF4D, "", "m"},
+	{0xFF4E, "", "n"},
+	{0xFF4F, "", "o"},
+	{0xFF50, "", "p"},
+	{0xFF51, "", "q"},
+	{0xFF52, "", "r"},
+	{0xFF53, "", "s"},
+	{0xFF54, "", "t"},
+	{0xFF55, "", "u"},
+	{0xFF56, "", "v"},
+	{0xFF57, "", "w"},
+	{0xFF58, "", "x"},
+	{0xFF59, "", "y"},
+	{0xFF5A, "", "z"},
+	{0xFF5B, "", "{"},
+	{0xFF5C, "", "|"},
+	{0xFF5D, "", "}"},
+	{0xFF5E, "", "~"},
+	{0xFF5F, "", "\u2985"},
+	{0xFF60, "", "\u2986"},
+	{0xFF61, "", "\u3002"},
+	{0xFF62, "", "\u300c"},
+	{0xFF63, "", "\u300d"},
+	{0xFF64, "", "\u3001"},
+	{0xFF65, "", "\u30fb"},
+	{0xFF66, "", "\u30f2"},
+	{0xFF67, "", "\u30a1"},
+	{0xFF68, "", "\u30a3"},
+	{0xFF69, "", "\u30a5"},
+	{0xFF6A, "", "\u30a7"},
+	{0xFF6B, "", "\u30a9"},
+	{0xFF6C, "", "\u30e3"},
+	{0xFF6D, "", "\u30e5"},
+	{0xFF6E, "", "\u30e7"},
+	{0xFF6F, "", "\u30c3"},
+	{0xFF70, "", "\u30fc"},
+	{0xFF71, "", "\u30a2"},
+	{0xFF72, "", "\u30a4"},
+	{0xFF73, "", "\u30a6"},
+	{0xFF74, "", "\u30a8"},
+	{0xFF75, "", "\u30aa"},
+	{0xFF76, "", "\u30ab"},
+	{0xFF77, "", "\u30ad"},
+	{0xFF78, "", "\u30af"},
+	{0xFF79, "", "\u30b1"},
+	{0xFF7A, "", "\u30b3"},
+	{0xFF7B, "", "\u30b5"},
+	{0xFF7C, "", "\u30b7"},
+	{0xFF7D, "", "\u30b9"},
+	{0xFF7E, "", "\u30bb"},
+	{0xFF7F, "", "\u30bd"},
+	{0xFF80, "", "\u30bf"},
+	{0xFF81, "", "\u30c1"},
+	{0xFF82, "", "\u30c4"},
+	{0xFF83, "", "\u30c6"},
+	{0xFF84, "", "\u30c8"},
+	{0xFF85, "", "\u30ca"},
+	{0xFF86, "", "\u30cb"},
+	{0xFF87, "", "\u30cc"},
+	{0xFF88, "", "\u30cd"},
+	{0xFF89, "", "\u30ce"},
+	{0xFF8A, "", "\u30cf"},
+	{0xFF8B, "", "\u30d2"},
+	{0xFF8C, "", "\u30d5"},
+	{0xFF8D, "", "\u30d8"},
+	{0xFF8E, "", "\u30db"},
+	{0xFF8F, "", "\u30de"},
+	{0xFF90, "", "\u30df"},
+	{0xFF91, "", "\u30e0"},
+	{0xFF92, "", "\u30e1"},
+	{0xFF93, "", "\u30e2"},
+	{0xFF94, "", "\u30e4"},
+	{0xFF95, "", "\u30e6"},
+	{0xFF96, "", "\u30e8"},
+	{0xFF97, "", "\u30e9"},
+	{0xFF98, "", "\u30ea"},
+	{0xFF99, "", "\u30eb"},
+	{0xFF9A, "", "\u30ec"},
+	{0xFF9B, "", "\u30ed"},
+	{0xFF9C, "", "\u30ef"},
+	{0xFF9D, "", "\u30f3"},
+	{0xFF9E, "", "\u3099"},
+	{0xFF9F, "", "\u309a"},
+	{0xFFA0, "", "\u1160"},
+	{0xFFA1, "", "\u1100"},
+	{0xFFA2, "", "\u1101"},
+	{0xFFA3, "", "\u11aa"},
+	{0xFFA4, "", "\u1102"},
+	{0xFFA5, "", "\u11ac"},
+	{0xFFA6, "", "\u11ad"},
+	{0xFFA7, "", "\u1103"},
+	{0xFFA8, "", "\u1104"},
+	{0xFFA9, "", "\u1105"},
+	{0xFFAA, "", "\u11b0"},
+	{0xFFAB, "", "\u11b1"},
+	{0xFFAC, "", "\u11b2"},
+	{0xFFAD, "", "\u11b3"},
+	{0xFFAE, "", "\u11b4"},
+	{0xFFAF, "", "\u11b5"},
+	{0xFFB0, "", "\u111a"},
+	{0xFFB1, "", "\u1106"},
+	{0xFFB2, "", "\u1107"},
+	{0xFFB3, "", "\u1108"},
+	{0xFFB4, "", "\u1121"},
+	{0xFFB5, "", "\u1109"},
+	{0xFFB6, "", "\u110a"},
+	{0xFFB7, "", "\u110b"},
+	{0xFFB8, "", "\u110c"},
+	{0xFFB9, "", "\u110d"},
+	{0xFFBA, "", "\u110e"},
+	{0xFFBB, "", "\u110f"},
+	{0xFFBC, "", "\u1110"},
+	{0xFFBD, "", "\u1111"},
+	{0xFFBE, "", "\u1112"},
+	{0xFFC2, "", "\u1161"},
+	{0xFFC3, "", "\u1162"},
+	{0xFFC4, "", "\u1163"},
+	{0xFFC5, "", "\u1164"},
+	{0xFFC6, "", "\u1165"},
+	{0xFFC7, "", "\u1166"},
+	{0xFFCA, "", "\u1167"},
+	{0xFFCB, "", "\u1168"},
+	{0xFFCC, "", "\u1169"},
+	{0xFFCD, "", "\u116a"},
+	{0xFFCE, "", "\u116b"},
+	{0xFFCF, "", "\u116c"},
+	{0xFFD2, "", "\u116d"},
+	{0xFFD3, "", "\u116e"},
+	{0xFFD4, "", "\u116f"},
+	{0xFFD5, "", "\u1170"},
+	{0xFFD6, "", "\u1171"},
+	{0xFFD7, "", "\u1172"},
+	{0xFFDA, "", "\u1173"},
+	{0xFFDB, "", "\u1174"},
+	{0xFFDC, "", "\u1175"},
+	{0xFFE0, "", "\u00a2"},
+	{0xFFE1, "", "\u00a3"},
+	{0xFFE2, "", "\u00ac"},
+	{0xFFE3, "", " \u0304"},
+	{0xFFE4, "", "\u00a6"},
+	{0xFFE5, "", "\u00a5"},
+	{0xFFE6, "", "\u20a9"},
+	{0xFFE8, "", "\u2502"},
+	{0xFFE9, "", "\u2190"},
+	{0xFFEA, "", "\u2191"},
+	{0xFFEB, "", "\u2192"},
+	{0xFFEC, "", "\u2193"},
+	{0xFFED, "", "\u25a0"},
+	{0xFFEE, "", "\u25cb"},
+}
+
+// maxDecomposition is the most runes a rune in decompositions decomposes to.
+const maxDecomposition = 4
+
+// combiningClasses holds the runs of runes with the same non-zero canonical combining class.
+var combiningClasses = [...]combiningClass{
+	{0x0300, 0x0314, 230},
+	{0x0315, 0x0315, 232},
+	{0x0316, 0x0319, 220},
+	{0x031A, 0x031A, 232},
+	{0x031B, 0x031B, 216},
+	{0x031C, 0x0320, 220},
+	{0x0321, 0x0322, 202},
+	{0x0323, 0x0326, 220},
+	{0x0327, 0x0328, 202},
+	{0x0329, 0x0333, 220},
+	{0x0334, 0x0338, 1},
+	{0x0339, 0x033C, 220},
+	{0x033D, 0x0344, 230},
+	{0x0345, 0x0345, 240},
+	{0x0346, 0x0346, 230},
+	{0x0347, 0x0349, 220},
+	{0x034A, 0x034C, 230},
+	{0x034D, 0x034E, 220},
+	{0x0350, 0x0352, 230},
+	{0x0353, 0x0356, 220},
+	{0x0357, 0x0357, 230},
+	{0x0358, 0x0358, 232},
+	{0x0359, 0x035A, 220},
+	{0x035B, 0x035B, 230},
+	{0x035C, 0x035C, 233},
+	{0x035D, 0x035E, 234},
+	{0x035F, 0x035F, 233},
+	{0x0360, 0x0361, 234},
+	{0x0362, 0x0362, 233},
+	{0x0363, 0x036F, 230},
+	{0x0483, 0x0487, 230},
+	{0x0591, 0x0591, 220},
+	{0x0592, 0x0595, 230},
+	{0x0596, 0x0596, 220},
+	{0x0597, 0x0599, 230},
+	{0x059A, 0x059A, 222},
+	{0x059B, 0x059B, 220},
+	{0x059C, 0x05A1, 230},
+	{0x05A2, 0x05A7, 220},
+	{0x05A8, 0x05A9, 230},
+	{0x05AA, 0x05AA, 220},
+	{0x05AB, 0x05AC, 230},
+	{0x05AD, 0x05AD, 222},
+	{0x05AE, 0x05AE, 228},
+	{0x05AF, 0x05AF, 230},
+	{0x05B0, 0x05B0, 10},
+	{0x05B1, 0x05B1, 11},
+	{0x05B2, 0x05B2, 12},
+	{0x05B3, 0x05B3, 13},
+	{0x05B4, 0x05B4, 14},
+	{0x05B5, 0x05B5, 15},
+	{0x05B6, 0x05B6, 16},
+	{0x05B7, 0x05B7, 17},
+	{0x05B8, 0x05B8, 18},
+	{0x05B9, 0x05BA, 19},
+	{0x05BB, 0x05BB, 20},
+	{0x05BC, 0x05BC, 21},
+	{0x05BD, 0x05BD, 22},
+	{0x05BF, 0x05BF, 23},
+	{0x05C1, 0x05C1, 24},
+	{0x05C2, 0x05C2, 25},
+	{0x05C4, 0x05C4, 230},
+	{0x05C5, 0x05C5, 220},
+	{0x05C7, 0x05C7, 18},
+	{0x0610, 0x0617, 230},
+	{0x0618, 0x0618, 30},
+	{0x0619, 0x0619, 31},
+	{0x061A, 0x061A, 32},
+	{0x064B, 0x064B, 27},
+	{0x064C, 0x064C, 28},
+	{0x064D, 0x064D, 29},
+	{0x064E, 0x064E, 30},
+	{0x064F, 0x064F, 31},
+	{0x0650, 0x0650, 32},
+	{0x0651, 0x0651, 33},
+	{0x0652, 0x0652, 34},
+	{0x0653, 0x0654, 230},
+	{0x0655, 0x0656, 220},
+	{0x0657, 0x065B, 230},
+	{0x065C, 0x065C, 220},
+	{0x065D, 0x065E, 230},
+	{0x065F, 0x065F, 220},
+	{0x0670, 0x0670, 35},
+	{0x06D6, 0x06DC, 230},
+	{0x06DF, 0x06E2, 230},
+	{0x06E3, 0x06E3, 220},
+	{0x06E4, 0x06E4, 230},
+	{0x06E7, 0x06E8, 230},
+	{0x06EA, 0x06EA, 220},
+	{0x06EB, 0x06EC, 230},
+	{0x06ED, 0x06ED, 220},
+	{0x0711, 0x0711, 36},
+	{0x0730, 0x0730, 230},
+	{0x0731, 0x0731, 220},
+	{0x0732, 0x0733, 230},
+	{0x0734, 0x0734, 220},
+	{0x0735, 0x0736, 230},
+	{0x0737, 0x0739, 220},
+	{0x073A, 0x073A, 230},
+	{0x073B, 0x073C, 220},
+	{0x073D, 0x073D, 230},
+	{0x073E, 0x073E, 220},
+	{0x073F, 0x0741, 230},
+	{0x0742, 0x0742, 220},
+	{0x0743, 0x0743, 230},
+	{0x0744, 0x0744, 220},
+	{0x0745, 0x0745, 230},
+	{0x0746, 0x0746, 220},
+	{0x0747, 0x0747, 230},
+	{0x0748, 0x0748, 220},
+	{0x0749, 0x074A, 230},
+	{0x07EB, 0x07F1, 230},
+	{0x07F2, 0x07F2, 220},
+	{0x07F3, 0x07F3, 230},
+	{0x07FD, 0x07FD, 220},
+	{0x0816, 0x0819, 230},
+	{0x081B, 0x0823, 230},
+	{0x0825, 0x0827, 230},
+	{0x0829, 0x082D, 230},
+	{0x0859, 0x085B, 220},
+	{0x0898, 0x0898, 230},
+	{0x0899, 0x089B, 220},
+	{0x089C, 0x089F, 230},
+	{0x08CA, 0x08CE, 230},
+	{0x08CF, 0x08D3, 220},
+	{0x08D4, 0x08E1, 230},
+	{0x08E3, 0x08E3, 220},
+	{0x08E4, 0x08E5, 230},
+	{0x08E6, 0x08E6, 220},
+	{0x08E7, 0x08E8, 230},
+	{0x08E9, 0x08E9, 220},
+	{0x08EA, 0x08EC, 230},
+	{0x08ED, 0x08EF, 220},
+	{0x08F0, 0x08F0, 27},
+	{0x08F1, 0x08F1, 28},
+	{0x08F2, 0x08F2, 29},
+	{0x08F3, 0x08F5, 230},
+	{0x08F6, 0x08F6, 220},
+	{0x08F7, 0x08F8, 230},
+	{0x08F9, 0x08FA, 220},
+	{0x08FB, 0x08FF, 230},
+	{0x093C, 0x093C, 7},
+	{0x094D, 0x094D, 9},
+	{0x0951, 0x0951, 230},
+	{0x0952, 0x0952, 220},
+	{0x0953, 0x0954, 230},
+	{0x09BC, 0x09BC, 7},
+	{0x09CD, 0x09CD, 9},
+	{0x09FE, 0x09FE, 230},
+	{0x0A3C, 0x0A3C, 7},
+	{0x0A4D, 0x0A4D, 9},
+	{0x0ABC, 0x0ABC, 7},
+	{0x0ACD, 0x0ACD, 9},
+	{0x0B3C, 0x0B3C, 7},
+	{0x0B4D, 0x0B4D, 9},
+	{0x0BCD, 0x0BCD, 9},
+	{0x0C3C, 0x0C3C, 7},
+	{0x0C4D, 0x0C4D, 9},
+	{0x0C55, 0x0C55, 84},
+	{0x0C56, 0x0C56, 91},
+	{0x0CBC, 0x0CBC, 7},
+	{0x0CCD, 0x0CCD, 9},
+	{0x0D3B, 0x0D3C, 9},
+	{0x0D4D, 0x0D4D, 9},
+	{0x0DCA, 0x0DCA, 9},
+	{0x0E38, 0x0E39, 103},
+	{0x0E3A, 0x0E3A, 9},
+	{0x0E48, 0x0E4B, 107},
+	{0x0EB8, 0x0EB9, 118},
+	{0x0EBA, 0x0EBA, 9},
+	{0x0EC8, 0x0ECB, 122},
+	{0x0F18, 0x0F19, 220},
+	{0x0F35, 0x0F35, 220},
+	{0x0F37, 0x0F37, 220},
+	{0x0F39, 0x0F39, 216},
+	{0x0F71, 0x0F71, 129},
+	{0x0F72, 0x0F72, 130},
+	{0x0F74, 0x0F74, 132},
+	{0x0F7A, 0x0F7D, 130},
+	{0x0F80, 0x0F80, 130},
+	{0x0F82, 0x0F83, 230},
+	{0x0F84, 0x0F84, 9},
+	{0x0F86, 0x0F87, 230},
+	{0x0FC6, 0x0FC6, 220},
+	{0x1037, 0x1037, 7},
+	{0x1039, 0x103A, 9},
+	{0x108D, 0x108D, 220},
+	{0x135D, 0x135F, 230},
+	{0x1714, 0x1715, 9},
+	{0x1734, 0x1734, 9},
+	{0x17D2, 0x17D2, 9},
+	{0x17DD, 0x17DD, 230},
+	{0x18A9, 0x18A9, 228},
+	{0x1939, 0x1939, 222},
+	{0x193A, 0x193A, 230},
+	{0x193B, 0x193B, 220},
+	{0x1A17, 0x1A17, 230},
+	{0x1A18, 0x1A18, 220},
+	{0x1A60, 0x1A60, 9},
+	{0x1A75, 0x1A7C, 230},
+	{0x1A7F, 0x1A7F, 220},
+	{0x1AB0, 0x1AB4, 230},
+	{0x1AB5, 0x1ABA, 220},
+	{0x1ABB, 0x1ABC, 230},
+	{0x1ABD, 0x1ABD, 220},
+	{0x1ABF, 0x1AC0, 220},
+	{0x1AC1, 0x1AC2, 230},
+	{0x1AC3, 0x1AC4, 220},
+	{0x1AC5, 0x1AC9, 230},
+	{0x1ACA, 0x1ACA, 220},
+	{0x1ACB, 0x1ACE, 230},
+	{0x1B34, 0x1B34, 7},
+	{0x1B44, 0x1B44, 9},
+	{0x1B6B, 0x1B6B, 230},
+	{0x1B6C, 0x1B6C, 220},
+	{0x1B6D, 0x1B73, 230},
+	{0x1BAA, 0x1BAB, 9},
+	{0x1BE6, 0x1BE6, 7},
+	{0x1BF2, 0x1BF3, 9},
+	{0x1C37, 0x1C37, 7},
+	{0x1CD0, 0x1CD2, 230},
+	{0x1CD4, 0x1CD4, 1},
+	{0x1CD5, 0x1CD9, 220},
+	{0x1CDA, 0x1CDB, 230},
+	{0x1CDC, 0x1CDF, 220},
+	{0x1CE0, 0x1CE0, 230},
+	{0x1CE2, 0x1CE8, 1},
+	{0x1CED, 0x1CED, 220},
+	{0x1CF4, 0x1CF4, 230},
+	{0x1CF8, 0x1CF9, 230},
+	{0x1DC0, 0x1DC1, 230},
+	{0x1DC2, 0x1DC2, 220},
+	{0x1DC3, 0x1DC9, 230},
+	{0x1DCA, 0x1DCA, 220},
+	{0x1DCB, 0x1DCC, 230},
+	{0x1DCD, 0x1DCD, 234},
+	{0x1DCE, 0x1DCE, 214},
+	{0x1DCF, 0x1DCF, 220},
+	{0x1DD0, 0x1DD0, 202},
+	{0x1DD1, 0x1DF5, 230},
+	{0x1DF6, 0x1DF6, 232},
+	{0x1DF7, 0x1DF8, 228},
+	{0x1DF9, 0x1DF9, 220},
+	{0x1DFA, 0x1DFA, 218},
+	{0x1DFB, 0x1DFB, 230},
+	{0x1DFC, 0x1DFC, 233},
+	{0x1DFD, 0x1DFD, 220},
+	{0x1DFE, 0x1DFE, 230},
+	{0x1DFF, 0x1DFF, 220},
+	{0x20D0, 0x20D1, 230},
+	{0x20D2, 0x20D3, 1},
+	{0x20D4, 0x20D7, 230},
+	{0x20D8, 0x20DA, 1},
+	{0x20DB, 0x20DC, 230},
+	{0x20E1, 0x20E1, 230},
+	{0x20E5, 0x20E6, 1},
+	{0x20E7, 0x20E7, 230},
+	{0x20E8, 0x20E8, 220},
+	{0x20E9, 0x20E9, 230},
+	{0x20EA, 0x20EB, 1},
+	{0x20EC, 0x20EF, 220},
+	{0x20F0, 0x20F0, 230},
+	{0x2CEF, 0x2CF1, 230},
+	{0x2D7F, 0x2D7F, 9},
+	{0x2DE0, 0x2DFF, 230},
+	{0x302A, 0x302A, 218},
+	{0x302B, 0x302B, 228},
+	{0x302C, 0x302C, 232},
+	{0x302D, 0x302D, 222},
+	{0x302E, 0x302F, 224},
+	{0x3099, 0x309A, 8},
+	{0xA66F, 0xA66F, 230},
+	{0xA674, 0xA67D, 230},
+	{0xA69E, 0xA69F, 230},
+	{0xA6F0, 0xA6F1, 230},
+	{0xA806, 0xA806, 9},
+	{0xA82C, 0xA82C, 9},
+	{0xA8C4, 0xA8C4, 9},
+	{0xA8E0, 0xA8F1, 230},
+	{0xA92B, 0xA92D, 220},
+	{0xA953, 0xA953, 9},
+	{0xA9B3, 0xA9B3, 7},
+	{0xA9C0, 0xA9C0, 9},
+	{0xAAB0, 0xAAB0, 230},
+	{0xAAB2, 0xAAB3, 230},
+	{0xAAB4, 0xAAB4, 220},
+	{0xAAB7, 0xAAB8, 230},
+	{0xAABE, 0xAABF, 230},
+	{0xAAC1, 0xAAC1, 230},
+	{0xAAF6, 0xAAF6, 9},
+	{0xABED, 0xABED, 9},
+	{0xFB1E, 0xFB1E, 26},
+	{0xFE20, 0xFE26, 230},
+	{0xFE27, 0xFE2D, 220},
+	{0xFE2E, 0xFE2F, 230},
+	{0x101FD, 0x101FD, 220},
+	{0x102E0, 0x102E0, 220},
+	{0x10376, 0x1037A, 230},
+	{0x10A0D, 0x10A0D, 220},
+	{0x10A0F, 0x10A0F, 230},
+	{0x10A38, 0x10A38, 230},
+	{0x10A39, 0x10A39, 1},
+	{0x10A3A, 0x10A3A, 220},
+	{0x10A3F, 0x10A3F, 9},
+	{0x10AE5, 0x10AE5, 230},
+	{0x10AE6, 0x10AE6, 220},
+	{0x10D24, 0x10D27, 230},
+	{0x10EAB, 0x10EAC, 230},
+	{0x10F46, 0x10F47, 220},
+	{0x10F48, 0x10F4A, 230},
+	{0x10F4B, 0x10F4B, 220},
+	{0x10F4C, 0x10F4C, 230},
+	{0x10F4D, 0x10F50, 220},
+	{0x10F82, 0x10F82, 230},
+	{0x10F83, 0x10F83, 220},
+	{0x10F84, 0x10F84, 230},
+	{0x10F85, 0x10F85, 220},
+	{0x11046, 0x11046, 9},
+	{0x11070, 0x11070, 9},
+	{0x1107F, 0x1107F, 9},
+	{0x110B9, 0x110B9, 9},
+	{0x110BA, 0x110BA, 7},
+	{0x11100, 0x11102, 230},
+	{0x11133, 0x11134, 9},
+	{0x11173, 0x11173, 7},
+	{0x111C0, 0x111C0, 9},
+	{0x111CA, 0x111CA, 7},
+	{0x11235, 0x11235, 9},
+	{0x11236, 0x11236, 7},
+	{0x112E9, 0x112E9, 7},
+	{0x112EA, 0x112EA, 9},
+	{0x1133B, 0x1133C, 7},
+	{0x1134D, 0x1134D, 9},
+	{0x11366, 0x1136C, 230},
+	{0x11370, 0x11374, 230},
+	{0x11442, 0x11442, 9},
+	{0x11446, 0x11446, 7},
+	{0x1145E, 0x1145E, 230},
+	{0x114C2, 0x114C2, 9},
+	{0x114C3, 0x114C3, 7},
+	{0x115BF, 0x115BF, 9},
+	{0x115C0, 0x115C0, 7},
+	{0x1163F, 0x1163F, 9},
+	{0x116B6, 0x116B6, 9},
+	{0x116B7, 0x116B7, 7},
+	{0x1172B, 0x1172B, 9},
+	{0x11839, 0x11839, 9},
+	{0x1183A, 0x1183A, 7},
+	{0x1193D, 0x1193E, 9},
+	{0x11943, 0x11943, 7},
+	{0x119E0, 0x119E0, 9},
+	{0x11A34, 0x11A34, 9},
+	{0x11A47, 0x11A47, 9},
+	{0x11A99, 0x11A99, 9},
+	{0x11C3F, 0x11C3F, 9},
+	{0x11D42, 0x11D42, 7},
+	{0x11D44, 0x11D45, 9},
+	{0x11D97, 0x11D97, 9},
+	{0x16AF0, 0x16AF4, 1},
+	{0x16B30, 0x16B36, 230},
+	{0x16FF0, 0x16FF1, 6},
+	{0x1BC9E, 0x1BC9E, 1},
+	{0x1D165, 0x1D166, 216},
+	{0x1D167, 0x1D169, 1},
+	{0x1D16D, 0x1D16D, 226},
+	{0x1D16E, 0x1D172, 216},
+	{0x1D17B, 0x1D182, 220},
+	{0x1D185, 0x1D189, 230},
+	{0x1D18A, 0x1D18B, 220},
+	{0x1D1AA, 0x1D1AD, 230},
+	{0x1D242, 0x1D244, 230},
+	{0x1E000, 0x1E006, 230},
+	{0x1E008, 0x1E018, 230},
+	{0x1E01B, 0x1E021, 230},
+	{0x1E023, 0x1E024, 230},
+	{0x1E026, 0x1E02A, 230},
+	{0x1E130, 0x1E136, 230},
+	{0x1E2AE, 0x1E2AE, 230},
+	{0x1E2EC, 0x1E2EF, 230},
+	{0x1E8D0, 0x1E8D6, 220},
+	{0x1E944, 0x1E949, 230},
+	{0x1E94A, 0x1E94A, 7},
+}
+
+// combinesBackward holds the starters, other than Hangul jamo, which compose with the rune before them.
+var combinesBackward = [...]rune{
+	0x09BE,
+	0x09D7,
+	0x0B3E,
+	0x0B56,
+	0x0B57,
+	0x0BBE,
+	0x0BD7,
+	0x0CC2,
+	0x0CD5,
+	0x0CD6,
+	0x0D3E,
+	0x0D57,
+	0x0DCF,
+	0x0DDF,
+	0x0FB5,
+	0x0FB7,
+	0x102E,
+	0x1B35,
+	0x11127,
+	0x1133E,
+	0x11357,
+	0x114B0,
+	0x114BA,
+	0x114BD,
+	0x115AF,
+	0x11930,
+}
@@ -0,0 +1,114 @@
+// Package engine implements the matching shared by strconsume and byteconsume. It is generic over string
+// and []byte, so that byte slices are matched in place and results are subslices of the input.
+package engine
+
+import (
+	"bufio"
+
+	"github.com/arran4/go-consume"
+)
+
+// ApplyOptions applies ops on top of base. In strict mode every option must also be accepted by supported,
+// consumer names the method in the resulting error.
+func ApplyOptions(base consume.Config, consumer string, supported func(op any) bool, ops []any) (consume.Config, error) {
+	cfg := base
+	if err := cfg.Apply(ops...); err != nil {
+		return cfg, err
+	}
+	if cfg.Strict {
+		if err := consume.CheckSupported(consumer, supported, ops...); err != nil {
+			return cfg, err
+		}
+	}
+	return cfg, nil
+}
+
+// MustApplyOptions applies ops on top of base for methods which cannot return an error. It panics if an
+// option is invalid, such as an empty escape. Unsupported options are ignored even in strict mode, which is
+// only honoured where the problem can be returned.
+func MustApplyOptions(base consume.Config, ops []any) consume.Config {
+	if len(ops) == 0 {
+		// base was checked when it was built.
+		return base
+	}
+	cfg := base
+	if err := cfg.Apply(ops...); err != nil {
+		panic(err)
+	}
+	return cfg
+}
+
+// SplitOptions is like MustApplyOptions for split functions and scanners, which return the error of strict mode
+// from their first call rather than when they are made.
+func SplitOptions(base consume.Config, consumer string, supported func(op any) bool, ops []any) (consume.Config, error) {
+	cfg := MustApplyOptions(base, ops)
+	if cfg.Strict {
+		return cfg, consume.CheckSupported(consumer, supported, ops...)
+	}
+	return cfg, nil
+}
+
+// FailingSplitFunc returns a bufio.SplitFunc which stops the scan with err.
+func FailingSplitFunc(err error) bufio.SplitFunc {
+	return func([]byte, bool) (int, []byte, error) {
+		return 0, nil, err
+	}
+}
+
+// UntilConsumeSupports reports whether UntilConsumer.Consume and Iterator support op.
+func UntilConsumeSupports(op any) bool {
+	switch op.(type) {
+	case consume.Inclusive, consume.StartOffset, consume.Ignore0PositionMatch, consume.MustBePrecededBy,
+		consume.MustBeFollowedBy, consume.MustBeAtEnd, consume.WordBoundary, consume.CaseInsensitive,
+		consume.Normalization, consume.ConsumeRemainingIfNotFound, consume.Escape, consume.Encasing, consume.EscapeBreaksEncasing,
+		consume.MustMatchWholeString, consume.Unescape, consume.StripEncasing, consume.CollapseRuns,
+		consume.Strict:
+		return true
+	}
+	return false
+}
+
+// UntilSplitFuncSupports reports whether UntilConsumer.SplitFunc supports op.
+func UntilSplitFuncSupports(op any) bool {
+	switch op.(type) {
+	case consume.ConsumeRemainingIfNotFound, consume.MustMatchWholeString:
+		return false
+	}
+	return UntilConsumeSupports(op)
+}
+
+// PrefixConsumeSupports reports whether PrefixConsumer.Consume supports op.
+func PrefixConsumeSupports(op any) bool {
+	switch op.(type) {
+	case consume.Inclusive, consume.StartOffset, consume.Ignore0PositionMatch, consume.MustBePrecededBy,
+		consume.MustBeFollowedBy, consume.MustBeAtEnd, consume.WordBoundary, consume.CaseInsensitive,
+		consume.Normalization, consume.MustMatchWholeString, consume.Strict:
+		return true
+	}
+	return false
+}
+
+// PrefixSplitFuncSupports reports whether PrefixConsumer.SplitFunc supports op.
+func PrefixSplitFuncSupports(op any) bool {
+	switch op.(type) {
+	case consume.CaseInsensitive, consume.Normalization, consume.MustBePrecededBy, consume.MustBeFollowedBy,
+		consume.WordBoundary, consume.NoMatch, consume.Strict:
+		return true
+	}
+	return false
+}
+
+// PrefixWithSupports reports whether PrefixConsumer.With supports op, which it does if any method does.
+func PrefixWithSupports(op any) bool {
+	return PrefixConsumeSupports(op) || PrefixSplitFuncSupports(op)
+}
+
+// PrefixLongestPrefixSupports reports whether PrefixConsumer.LongestPrefix, ShortestPrefix, AllPrefixes,
+// KeysWithPrefix and Iterator support op.
+func PrefixLongestPrefixSupports(op any) bool {
+	switch op.(type) {
+	case consume.CaseInsensitive, consume.Normalization, consume.Strict:
+		return true
+	}
+	return false
+}
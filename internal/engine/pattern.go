@@ -0,0 +1,155 @@
+package engine
+
+import (
+	"regexp"
+	"slices"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/arran4/go-consume"
+)
+
+// Pattern is a separator which is not a literal string.
+type Pattern interface {
+	// findString and findBytes return the span of the first match in data which starts at or after i, or -1, -1
+	// if there is none, as there is not when i is past the end of data.
+	findString(data string, i int) (int, int)
+	findBytes(data []byte, i int) (int, int)
+}
+
+// RegexpPattern matches a regular expression. The expression sees the input from the position it is searched
+// from, so ^ and \b treat that position as the start of the text.
+type RegexpPattern struct {
+	Regexp *regexp.Regexp
+}
+
+func (p RegexpPattern) findString(data string, i int) (int, int) {
+	if i > len(data) {
+		return -1, -1
+	}
+	loc := p.Regexp.FindStringIndex(data[i:])
+	if loc == nil {
+		return -1, -1
+	}
+	return i + loc[0], i + loc[1]
+}
+
+func (p RegexpPattern) findBytes(data []byte, i int) (int, int) {
+	if i > len(data) {
+		return -1, -1
+	}
+	loc := p.Regexp.FindIndex(data[i:])
+	if loc == nil {
+		return -1, -1
+	}
+	return i + loc[0], i + loc[1]
+}
+
+// RunePattern matches any one rune for which Match returns true. Bytes which are not valid UTF-8 are passed
+// to Match as utf8.RuneError, as ranging over a string does.
+type RunePattern struct {
+	Match func(rune) bool
+}
+
+func (p RunePattern) findString(data string, i int) (int, int) {
+	for i < len(data) {
+		r, w := utf8.DecodeRuneInString(data[i:])
+		if p.Match(r) {
+			return i, i + w
+		}
+		i += w
+	}
+	return -1, -1
+}
+
+func (p RunePattern) findBytes(data []byte, i int) (int, int) {
+	for i < len(data) {
+		r, w := utf8.DecodeRune(data[i:])
+		if p.Match(r) {
+			return i, i + w
+		}
+		i += w
+	}
+	return -1, -1
+}
+
+func findPattern[T string | []byte](p Pattern, data T, i int) (int, int) {
+	switch data := any(data).(type) {
+	case string:
+		return p.findString(data, i)
+	case []byte:
+		return p.findBytes(data, i)
+	}
+	panic("unreachable")
+}
+
+// findPatterns returns the span of the leftmost-longest match of patterns which starts before limit, at a
+// position where a separator may start. Like literal separators, a match may extend into an escape or
+// encasing.
+func findPatterns[T string | []byte](patterns []Pattern, data T, cfg *consume.Config, limit int) (int, int, bool) {
+	// Each pattern's first match at or after the last position it was searched from. It is only searched again
+	// once the lexer passes that match, so a pattern is not run at every position.
+	if cfg.StartOffset > len(data) {
+		return 0, 0, false
+	}
+	type span struct{ start, end int }
+	var nextBuf [4]span
+	next := nextBuf[:0]
+	for _, p := range patterns {
+		start, end := findPattern(p, data, cfg.StartOffset)
+		next = append(next, span{start, end})
+	}
+
+	var stackBuf [8]consume.Encasing
+	stack := stackBuf[:0]
+	for i := cfg.StartOffset; i < len(data) && i < limit; {
+		kind, step, _, enc := lexStep(cfg, stack, data, i)
+		stack = updateStack(stack, kind, enc)
+		if kind == lexPlain && !(i == 0 && cfg.Ignore0PositionMatch) {
+			// Empty matches are ignored, as a separator which consumes nothing would be found forever.
+			end := i
+			for k, p := range patterns {
+				if next[k].start >= 0 && next[k].start < i {
+					next[k].start, next[k].end = findPattern(p, data, i)
+				}
+				if next[k].start == i && next[k].end > end {
+					end = next[k].end
+				}
+			}
+			if end > i {
+				return i, end, true
+			}
+		}
+		i = step
+	}
+	return 0, 0, false
+}
+
+// RegexpPatterns returns a RegexpPattern for each expression.
+func RegexpPatterns(res []*regexp.Regexp) []Pattern {
+	patterns := make([]Pattern, len(res))
+	for i, re := range res {
+		patterns[i] = RegexpPattern{Regexp: re}
+	}
+	return patterns
+}
+
+// WithPatterns returns separators with the keys and patterns of s and the extra patterns.
+func WithPatterns(s *Separators, patterns []Pattern) *Separators {
+	return NewSeparators(s.Keys(), append(slices.Clip(s.Patterns()), patterns...)...)
+}
+
+// RunePatterns returns a RunePattern for each function.
+func RunePatterns(fs []func(rune) bool) []Pattern {
+	patterns := make([]Pattern, len(fs))
+	for i, f := range fs {
+		patterns[i] = RunePattern{Match: f}
+	}
+	return patterns
+}
+
+// RangeTablePattern returns a RunePattern matching the runes in any of tables.
+func RangeTablePattern(tables []*unicode.RangeTable) Pattern {
+	tables = slices.Clone(tables)
+	return RunePattern{Match: func(r rune) bool { return unicode.In(r, tables...) }}
+}
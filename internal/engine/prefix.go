@@ -0,0 +1,312 @@
+package engine
+
+import (
+	"bufio"
+	"unicode/utf8"
+
+	"github.com/arran4/go-consume"
+)
+
+// Prefix finds the first key of t in from and splits it as PrefixConsumer.Consume describes.
+func Prefix[T string | []byte, V any](t *Trie[V], from T, cfg *consume.Config) (T, T, T, bool) {
+	if cfg.MustMatchWholeString {
+		n, found := LongestPrefix(t, from, MappingOf(cfg))
+		if !found || n != len(from) || cfg.StartOffset > 0 || cfg.Ignore0PositionMatch ||
+			checkBounds(from, 0, n, cfg, NoRune, true) != accepted {
+			return from[:0], from[:0], from, false
+		}
+		if cfg.Inclusive {
+			return from, from, from[len(from):], true
+		}
+		return from[:0], from, from, true
+	}
+	ac := t.load().automaton(MappingOf(cfg))
+	start, nextIdx, found := findKey(ac, from, cfg, func(start, nextIdx int) bool {
+		return checkBounds(from, start, nextIdx, cfg, NoRune, true) == accepted
+	})
+	if !found {
+		return from[:0], from[:0], from, false
+	}
+	if cfg.Inclusive {
+		return from[:nextIdx], from[start:nextIdx], from[nextIdx:], true
+	}
+	return from[:start], from[start:nextIdx], from[start:], true
+}
+
+// findKey returns the span of the longest key of ac at the first position in from, from cfg.StartOffset on,
+// for which accept returns true. Keys only start on rune boundaries, counted from cfg.StartOffset, and not at
+// 0 with Ignore0PositionMatch. Each byte of from is scanned once, however many keys there are and however
+// many matches accept turns down.
+func findKey[T string | []byte](ac *acAutomaton, from T, cfg *consume.Config, accept func(start, end int) bool) (int, int, bool) {
+	ks := keyScan[T]{ac: ac, data: from, mapping: MappingOf(cfg), accept: accept}
+	// The rings hold the positions from the first unsettled one on, which are at most maxLen back.
+	size := 1
+	for size <= ac.maxLen {
+		size *= 2
+	}
+	var ringBuf, endsBuf [32]int
+	if size <= len(ringBuf) {
+		ks.ring, ks.ends = ringBuf[:size], endsBuf[:size]
+	} else {
+		ks.ring, ks.ends = make([]int, size), make([]int, size)
+	}
+	ks.mask = size - 1
+	// When normalizing, every rune is fed, as it may carry on the segment before it.
+	skip := !ac.term[0] && !ks.mapping.normalizes()
+	for i := cfg.StartOffset; i < len(from) && !ks.found; {
+		w := runeWidth(from[i:])
+		if skip && ks.state == 0 && !ac.begins[from[i]] {
+			i += w
+			continue
+		}
+		ks.feed(i, i+w, !(i == 0 && cfg.Ignore0PositionMatch))
+		i += w
+	}
+	if !ks.found {
+		ks.flush()
+		ks.settle(ks.pos)
+	}
+	return ks.start, ks.end, ks.found
+}
+
+// keyScan runs the automaton over the input, recording the longest key starting at each stream position
+// still tracked by the automaton. A position is settled once the automaton has moved past it, and the
+// settled positions are offered to accept in order. As in acScan, positions are mapped back to the input
+// through ring, as the automaton is fed mapped runes when matching case-insensitively or under a
+// normalization form.
+type keyScan[T string | []byte] struct {
+	ac      *acAutomaton
+	data    T
+	mapping Mapping
+	seg     segmenter // the segment not yet fed, when normalizing
+	accept  func(start, end int) bool
+
+	state   int32
+	pos     int   // stream position of the next byte
+	settled int   // stream position of the first unsettled byte
+	ring    []int // input position of each recent stream byte which may start a key, otherwise -1
+	ends    []int // input end of the longest key found at each recent stream byte, otherwise -1
+	mask    int   // ring and ends have a power of two length, indexed by stream position & mask
+
+	found      bool
+	start, end int
+}
+
+// feed runs the input bytes data[i:end] through the automaton. If candidate is true a key may start at i.
+// When normalizing, the last segment is held back until the next one starts or flush is called.
+func (ks *keyScan[T]) feed(i, end int, candidate bool) {
+	if ks.mapping.normalizes() {
+		for q := i; q < end && !ks.found; {
+			r, w := decodeSegmentRune(ks.data[q:end])
+			if ks.seg.breaksBefore(r, ks.mapping) {
+				ks.flush()
+			}
+			ks.seg.add(r, w, q, q == i && candidate, ks.mapping)
+			q += w
+		}
+		return
+	}
+	if ks.mapping == 0 {
+		for q := i; q < end && !ks.found; q++ {
+			in := -1
+			if q == i && candidate {
+				in = q
+			}
+			ks.step(ks.data[q], in, q+1)
+		}
+		return
+	}
+	var buf [utf8.UTFMax]byte
+	for q := i; q < end && !ks.found; {
+		folded, w := appendFoldedRune(buf[:0], ks.data[q:end])
+		for k, b := range folded {
+			in, inEnd := -1, -1
+			if k == 0 && q == i && candidate {
+				in = q
+			}
+			if k == len(folded)-1 {
+				inEnd = q + w
+			}
+			ks.step(b, in, inEnd)
+		}
+		q += w
+	}
+}
+
+// flush feeds the segment held back by feed.
+func (ks *keyScan[T]) flush() {
+	start, end, candidate := ks.seg.start, ks.seg.end, ks.seg.candidate
+	ks.seg.flush(ks.mapping, func(b byte, first, last bool) {
+		in, inEnd := -1, -1
+		if first && candidate {
+			in = start
+		}
+		if last {
+			inEnd = end
+		}
+		if !ks.found {
+			ks.step(b, in, inEnd)
+		}
+	})
+}
+
+// step feeds a single byte, as acScan.step does, then settles the positions the automaton has moved past.
+func (ks *keyScan[T]) step(b byte, in, inEnd int) {
+	ac := ks.ac
+	slot := ks.pos & ks.mask
+	ks.ring[slot], ks.ends[slot] = in, -1
+	if in >= 0 && ac.term[0] {
+		ks.ends[slot] = in
+	}
+	ks.state = ac.next(ks.state, b)
+	ks.pos++
+
+	if inEnd >= 0 {
+		s := ks.state
+		if !ac.term[s] {
+			s = ac.dict[s]
+		}
+		for ; s > 0; s = ac.dict[s] {
+			streamStart := ks.pos - int(ac.depth[s])
+			if streamStart < ks.settled {
+				continue
+			}
+			// Later ends are further on, so the key found last at a position is the longest.
+			if slot := streamStart & ks.mask; ks.ring[slot] >= 0 {
+				ks.ends[slot] = inEnd
+			}
+		}
+	}
+	if to := ks.pos - int(ac.depth[ks.state]); to > ks.settled {
+		ks.settle(to)
+	}
+}
+
+// settle offers the positions before the stream position to accept, stopping at the first it accepts.
+func (ks *keyScan[T]) settle(to int) {
+	for ; ks.settled < to && !ks.found; ks.settled++ {
+		slot := ks.settled & ks.mask
+		if start, end := ks.ring[slot], ks.ends[slot]; start >= 0 && end >= 0 && ks.accept(start, end) {
+			ks.found, ks.start, ks.end = true, start, end
+		}
+	}
+}
+
+// PrefixIterator yields each consecutive key of t found at the start of from, as PrefixConsumer.Iterator
+// describes.
+func PrefixIterator[T string | []byte, V any](t *Trie[V], from T, cfg consume.Config) func(yield func(T, T) bool) {
+	return func(yield func(T, T) bool) {
+		for {
+			n, found := LongestPrefix(t, from, MappingOf(&cfg))
+			if !found {
+				return
+			}
+			if !yield(from[:n], from[n:]) {
+				return
+			}
+			if n == 0 {
+				return
+			}
+			from = from[n:]
+		}
+	}
+}
+
+// PrefixSplitFunc returns a bufio.SplitFunc which yields each consecutive key of t found at the start of the
+// data. Input which does not start with a key is handled as cfg.NoMatch says. A key only matches once it is
+// known that no longer key does, so the split function asks for more data while one still might. The split
+// function remembers the last rune it advanced past, for MustBePrecededBy and WordBoundary, so it must only be
+// used by one scanner.
+func PrefixSplitFunc[V any](t *Trie[V], cfg consume.Config) bufio.SplitFunc {
+	// MustBeAtEnd is for Consume, and would turn down every key but the last.
+	cfg.MustBeAtEnd = false
+	prev := NoRune
+	split := func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		// Skipped input is passed over within the call, as bufio.Scanner stops at EOF on a nil token.
+		skipped := 0
+		for {
+			rest := data[skipped:]
+			if atEOF && len(rest) == 0 {
+				return skipped, nil, nil
+			}
+			before := prev
+			if skipped > 0 {
+				before = PrevRune(data, skipped)
+			}
+			n, more := splitPrefixAt(t, rest, atEOF, &cfg, before)
+			if more {
+				return skipped, nil, nil
+			}
+			if n > 0 {
+				return skipped + n, rest[:n], nil
+			}
+			if cfg.NoMatch == consume.NoMatchError {
+				return skipped, nil, consume.ErrNoMatch
+			}
+
+			// The unmatched input runs until the next rune where a prefix matches or might match.
+			i, ended := 0, false
+			for i < len(rest) {
+				if !atEOF && !fullRune(rest[i:]) {
+					break
+				}
+				if i > 0 {
+					// A key which might match once more is read is settled then.
+					n, more := splitPrefixAt(t, rest[i:], atEOF, &cfg, PrevRune(rest, i))
+					if more {
+						break
+					}
+					if n > 0 {
+						ended = true
+						break
+					}
+				}
+				next, ok := keyStart(rest, i, MappingOf(&cfg))
+				if !ok && !atEOF {
+					break
+				}
+				i = next
+			}
+			ended = ended || atEOF
+			if cfg.NoMatch == consume.NoMatchSkip {
+				if !ended {
+					return skipped + i, nil, nil
+				}
+				skipped += i
+				continue
+			}
+			// The token may go on in the next read.
+			if !ended {
+				return 0, nil, nil
+			}
+			return i, rest[:i], nil
+		}
+	}
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := split(data, atEOF)
+		if advance > 0 {
+			prev = PrevRune(data, advance)
+		}
+		return advance, token, err
+	}
+}
+
+// splitPrefixAt returns the length of the key matching at the start of data, or 0 if there is none, and
+// whether more data could change that. The key must meet the conditions cfg places on the input around it,
+// with prev the rune before data. An empty key never matches, as it would not advance the split.
+func splitPrefixAt[V any](t *Trie[V], data []byte, atEOF bool, cfg *consume.Config, prev rune) (int, bool) {
+	n, node, more := longestPrefix(t.load(), data, MappingOf(cfg))
+	if more && !atEOF {
+		return 0, true
+	}
+	if node == nil || n == 0 {
+		return 0, false
+	}
+	switch checkBounds(data, 0, n, cfg, prev, atEOF) {
+	case rejected:
+		return 0, false
+	case undecided:
+		return 0, true
+	}
+	return n, false
+}
@@ -0,0 +1,196 @@
+package engine
+
+import (
+	"unicode/utf8"
+
+	"github.com/arran4/go-consume"
+)
+
+func hasPrefix[T string | []byte](s T, prefix string) bool {
+	return len(s) >= len(prefix) && string(s[:len(prefix)]) == prefix
+}
+
+func decodeRune[T string | []byte](s T) (rune, int) {
+	if len(s) > 0 && s[0] < utf8.RuneSelf {
+		return rune(s[0]), 1
+	}
+	n := min(len(s), utf8.UTFMax)
+	return utf8.DecodeRuneInString(string(s[:n]))
+}
+
+func fullRune[T string | []byte](s T) bool {
+	return utf8.FullRuneInString(string(s[:min(len(s), utf8.UTFMax)]))
+}
+
+// runeWidth returns the width of the rune at the start of s, as utf8.DecodeRuneInString would.
+func runeWidth[T string | []byte](s T) int {
+	_, w := decodeRune(s)
+	return w
+}
+
+// findSeparator returns the byte span of the leftmost-longest separator in data. Separators are only
+// recognised where they start outside of an escape or encasing, but may extend into one. It also returns where
+// the earliest separator the scan was still part way through at the end of data starts, which more data could
+// complete, or -1 if there is none.
+func findSeparator[T string | []byte](ac *acAutomaton, data T, o *consume.Config) (int, int, bool, int) {
+	if ac == nil {
+		return 0, 0, false, -1
+	}
+	sc := acScan[T]{ac: ac, data: data, mapping: MappingOf(o)}
+	var ringBuf [32]int
+	sc.ring = scanRing(ac, ringBuf[:])
+
+	var stackBuf [8]consume.Encasing
+	sc.run(o, stackBuf[:0], o.StartOffset, len(data))
+	sc.flush()
+	return sc.start, sc.end, sc.found, sc.pending()
+}
+
+// scanRing returns a ring for scanning with ac, using buf if it is large enough.
+func scanRing(ac *acAutomaton, buf []int) []int {
+	if ac.maxLen <= len(buf) {
+		return buf[:max(ac.maxLen, 1)]
+	}
+	return make([]int, ac.maxLen)
+}
+
+// acScan runs the automaton over the input while tracking which stream positions may start a separator.
+// When matching case-insensitively or under a normalization form the automaton is fed the mapped runes, so
+// stream positions are mapped back to input positions through ring.
+type acScan[T string | []byte] struct {
+	ac      *acAutomaton
+	data    T
+	mapping Mapping
+	seg     segmenter // the segment not yet fed, when normalizing
+
+	state int32
+	pos   int   // stream position of the next byte
+	ring  []int // input position of each recent stream byte which may start a separator, otherwise -1
+
+	found      bool
+	done       bool
+	bestStream int
+	bestLen    int
+	start, end int
+}
+
+// run lexes sc.data from i until the scan is done or reaches limit, feeding the automaton as it goes. It
+// returns the encasing stack and the position it stopped at, which may be past limit by up to one step.
+func (sc *acScan[T]) run(o *consume.Config, stack []consume.Encasing, i, limit int) ([]consume.Encasing, int) {
+	for i < limit && !sc.done {
+		kind, next, _, enc := lexStep(o, stack, sc.data, i)
+		stack = updateStack(stack, kind, enc)
+		sc.feed(i, next, kind == lexPlain && !(i == 0 && o.Ignore0PositionMatch))
+		i = next
+	}
+	return stack, i
+}
+
+// feed runs the input bytes data[i:end] through the automaton. If candidate is true a separator may start at i.
+// When normalizing, the last segment is held back until the next one starts or flush is called.
+func (sc *acScan[T]) feed(i, end int, candidate bool) {
+	if sc.mapping.normalizes() {
+		for q := i; q < end && !sc.done; {
+			r, w := decodeSegmentRune(sc.data[q:end])
+			if sc.seg.breaksBefore(r, sc.mapping) {
+				sc.flush()
+			}
+			sc.seg.add(r, w, q, q == i && candidate, sc.mapping)
+			q += w
+		}
+		return
+	}
+	if sc.mapping == 0 {
+		for q := i; q < end && !sc.done; q++ {
+			in := -1
+			if q == i && candidate {
+				in = q
+			}
+			sc.step(sc.data[q], in, q+1)
+		}
+		return
+	}
+	var buf [utf8.UTFMax]byte
+	for q := i; q < end && !sc.done; {
+		folded, w := appendFoldedRune(buf[:0], sc.data[q:end])
+		for k, b := range folded {
+			in, inEnd := -1, -1
+			if k == 0 && q == i && candidate {
+				in = q
+			}
+			if k == len(folded)-1 {
+				inEnd = q + w
+			}
+			sc.step(b, in, inEnd)
+		}
+		q += w
+	}
+}
+
+// flush feeds the segment held back by feed.
+func (sc *acScan[T]) flush() {
+	start, end, candidate := sc.seg.start, sc.seg.end, sc.seg.candidate
+	sc.seg.flush(sc.mapping, func(b byte, first, last bool) {
+		in, inEnd := -1, -1
+		if first && candidate {
+			in = start
+		}
+		if last {
+			inEnd = end
+		}
+		if !sc.done {
+			sc.step(b, in, inEnd)
+		}
+	})
+}
+
+// pending returns the input position of the earliest separator the automaton is part way through which a
+// longer key could complete, or -1 if there is none.
+func (sc *acScan[T]) pending() int {
+	for k := sc.pos - int(sc.ac.live[sc.state]); k < sc.pos; k++ {
+		if in := sc.ring[k%len(sc.ring)]; in >= 0 {
+			return in
+		}
+	}
+	return -1
+}
+
+// step feeds a single byte. in is the input position the byte starts a separator at, or -1, and inEnd is the
+// input position after the byte if a separator may end there, or -1.
+func (sc *acScan[T]) step(b byte, in, inEnd int) {
+	ac := sc.ac
+	sc.ring[sc.pos%len(sc.ring)] = in
+	if in >= 0 && ac.term[0] && !sc.found {
+		sc.found, sc.bestStream, sc.bestLen, sc.start, sc.end = true, sc.pos, 0, in, in
+	}
+	sc.state = ac.next(sc.state, b)
+	sc.pos++
+
+	if inEnd >= 0 {
+		s := sc.state
+		if !ac.term[s] {
+			s = ac.dict[s]
+		}
+		for ; s > 0; s = ac.dict[s] {
+			l := int(ac.depth[s])
+			streamStart := sc.pos - l
+			if streamStart < 0 {
+				continue
+			}
+			in := sc.ring[streamStart%len(sc.ring)]
+			if in < 0 {
+				continue
+			}
+			if !sc.found || streamStart < sc.bestStream || (streamStart == sc.bestStream && l > sc.bestLen) {
+				sc.found, sc.bestStream, sc.bestLen, sc.start, sc.end = true, streamStart, l, in, inEnd
+			}
+			// Keys further along the chain are shorter so start later.
+			break
+		}
+	}
+
+	// No later match can start at or before the best one once the automaton no longer tracks it.
+	if sc.found && sc.pos-int(ac.depth[sc.state]) > sc.bestStream {
+		sc.done = true
+	}
+}
@@ -0,0 +1,247 @@
+package engine
+
+import (
+	"bufio"
+	"io"
+	"unicode/utf8"
+
+	"github.com/arran4/go-consume"
+)
+
+const (
+	startBufSize = 4096
+	// maxConsecutiveEmptyReads matches the limit bufio.Scanner places on a reader which makes no progress.
+	maxConsecutiveEmptyReads = 100
+)
+
+// Stream splits the text read from an io.Reader into the same tokens as UntilSplitFunc. Unlike a split
+// function it keeps its place in the current token when it needs more input, so each byte is scanned once
+// however many reads a token spans. With patterns, runs of separators or conditions on the input around them,
+// the search after a read resumes a little before the end of the previous one instead, as resumeAt describes,
+// but from the start of the token for a regular expression and from where an open escape or encasing began.
+type Stream struct {
+	r            io.Reader
+	cfg          consume.Config
+	ac           *acAutomaton
+	separators   *Separators // set if separators are searched for again after each read
+	tail         int         // bytes needed after a separator found by separators, as Separators.tail describes
+	prev         rune        // the last rune of the input before buf[start], or NoRune
+	lookahead    int
+	buf          []byte
+	start, end   int // buf[start:end] is the unconsumed input, starting with the current token
+	maxTokenSize int
+	eof          bool
+	err          error
+	scanCalled   bool
+
+	token, separator []byte
+
+	// Scan state of the current token, relative to buf[start].
+	sc    acScan[[]byte]
+	stack []consume.Encasing
+	i     int
+
+	// With separators, the search of the current token resumes at from, and i is how far it has been lexed,
+	// with stack holding the encasings open there.
+	from int
+}
+
+func NewStream(s *Separators, r io.Reader, cfg consume.Config) *Stream {
+	st := &Stream{r: r, cfg: cfg, ac: s.automaton(MappingOf(&cfg)), maxTokenSize: bufio.MaxScanTokenSize}
+	// Lexing a step needs the longest escape plus the rune it escapes, or the longest encasing delimiter,
+	// to be present. Before EOF the scan stops that far short of the end of the input.
+	st.lookahead = utf8.UTFMax
+	for _, esc := range cfg.Escapes {
+		st.lookahead = max(st.lookahead, len(esc)+utf8.UTFMax)
+	}
+	for _, enc := range cfg.Encasings {
+		st.lookahead = max(st.lookahead, len(enc.Start), len(enc.End))
+	}
+	if st.ac != nil {
+		st.sc.ring = make([]int, max(st.ac.maxLen, 1))
+	}
+	// Patterns, runs of separators and conditions on the input around them are not handled by the
+	// incremental scan.
+	st.prev = NoRune
+	if len(s.Patterns()) > 0 || cfg.CollapseRuns || hasBounds(&cfg) {
+		st.separators, st.tail = s, s.tail(&cfg)
+	}
+	st.reset()
+	return st
+}
+
+// reset starts scanning a new token at buf[start].
+func (st *Stream) reset() {
+	st.sc = acScan[[]byte]{ac: st.ac, mapping: MappingOf(&st.cfg), ring: st.sc.ring}
+	st.stack = st.stack[:0]
+	st.i = st.cfg.StartOffset
+	st.from = 0
+}
+
+// Buffer sets the initial buffer and the maximum token size, as bufio.Scanner.Buffer does. It panics if
+// called after scanning has started.
+func (st *Stream) Buffer(buf []byte, max int) {
+	if st.scanCalled {
+		panic("Buffer called after Scan")
+	}
+	st.buf = buf[0:cap(buf)]
+	st.maxTokenSize = max
+}
+
+// Fail makes Scan stop with err before reading any input.
+func (st *Stream) Fail(err error) {
+	st.err = err
+}
+
+// Scan advances to the next token, which is then available through Token and Separator. It returns false at
+// the end of the input or on an error, which Err reports.
+func (st *Stream) Scan() bool {
+	st.scanCalled = true
+	st.token, st.separator = nil, nil
+	if st.err != nil {
+		return false
+	}
+	for {
+		data := st.buf[st.start:st.end]
+		if st.separators != nil {
+			// As in UntilSplitFunc, a match too near the end of the data waits for more of it.
+			cfg := st.cfg
+			cfg.StartOffset = max(cfg.StartOffset, st.from)
+			start, end, found, resume := separatorSpan(st.separators, data, &cfg, st.prev, st.eof)
+			if found && (st.eof || len(data)-end >= st.tail) {
+				st.sc.found, st.sc.done, st.sc.start, st.sc.end = true, true, start, end
+			} else if !st.eof {
+				if found {
+					resume = min(resume, start)
+				}
+				st.advance(data, resume)
+			}
+		} else if st.ac == nil {
+			st.i = max(st.i, len(data))
+		} else {
+			limit := len(data)
+			if !st.eof {
+				limit -= st.lookahead
+			}
+			st.sc.data = data
+			st.stack, st.i = st.sc.run(&st.cfg, st.stack, st.i, limit)
+			if st.eof && st.i >= len(data) {
+				st.sc.flush()
+			}
+		}
+
+		if st.sc.found && (st.sc.done || st.eof && st.i >= len(data)) {
+			start, end := st.sc.start, st.sc.end
+			if end == 0 {
+				st.err = consume.ErrNoProgress
+				return false
+			}
+			st.token = DecodeToken(data, start, &st.cfg)
+			if st.cfg.Inclusive {
+				if len(st.token) == start {
+					st.token = data[:end]
+				} else {
+					st.token = append(st.token, data[start:end]...)
+				}
+			}
+			st.separator = data[start:end]
+			st.prev = PrevRune(data, end)
+			st.start += end
+			st.reset()
+			return true
+		}
+		if st.eof && (st.separators != nil || st.i >= len(data)) {
+			if len(data) == 0 {
+				return false
+			}
+			st.token = DecodeToken(data, len(data), &st.cfg)
+			st.start = st.end
+			st.reset()
+			return true
+		}
+
+		if err := st.fill(); err != nil {
+			st.err = err
+			return false
+		}
+	}
+}
+
+// advance moves the start of the next search of the current token in data up to limit, or, with escapes or
+// encasings, to the last position before it which is outside them all, as StartOffset expects.
+func (st *Stream) advance(data []byte, limit int) {
+	if len(st.cfg.Escapes) == 0 && len(st.cfg.Encasings) == 0 {
+		st.from = max(st.from, limit)
+		return
+	}
+	m := MappingOf(&st.cfg)
+	limit = min(limit, len(data)-st.lookahead)
+	for st.i < limit {
+		kind, next, _, enc := lexStep(&st.cfg, st.stack, data, st.i)
+		st.stack = updateStack(st.stack, kind, enc)
+		st.i = next
+		if len(st.stack) == 0 && next <= limit && mayStartAt(data, next, m) {
+			st.from = next
+		}
+	}
+}
+
+// fill reads more input, making room in the buffer first if needed.
+func (st *Stream) fill() error {
+	if st.start > 0 && (st.end == len(st.buf) || st.start > len(st.buf)/2) {
+		copy(st.buf, st.buf[st.start:st.end])
+		st.end -= st.start
+		st.start = 0
+	}
+	if st.end == len(st.buf) {
+		if len(st.buf) >= st.maxTokenSize || len(st.buf) > maxInt/2 {
+			return bufio.ErrTooLong
+		}
+		newSize := len(st.buf) * 2
+		if newSize == 0 {
+			newSize = startBufSize
+		}
+		newBuf := make([]byte, min(newSize, st.maxTokenSize))
+		copy(newBuf, st.buf[st.start:st.end])
+		st.buf = newBuf
+	}
+	for loop := 0; ; {
+		n, err := st.r.Read(st.buf[st.end:len(st.buf)])
+		if n < 0 || len(st.buf)-st.end < n {
+			return bufio.ErrBadReadCount
+		}
+		st.end += n
+		if err == io.EOF {
+			st.eof = true
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			return nil
+		}
+		loop++
+		if loop > maxConsecutiveEmptyReads {
+			return io.ErrNoProgress
+		}
+	}
+}
+
+const maxInt = int(^uint(0) >> 1)
+
+// Token returns the most recent token found by Scan. It is only valid until the next call to Scan.
+func (st *Stream) Token() []byte {
+	return st.token
+}
+
+// Separator returns the separator which ended the most recent token, or nil if the token ran to the end of the
+// input. It is only valid until the next call to Scan.
+func (st *Stream) Separator() []byte {
+	return st.separator
+}
+
+// Err returns the first error other than io.EOF encountered by Scan.
+func (st *Stream) Err() error {
+	return st.err
+}
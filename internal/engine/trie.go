@@ -0,0 +1,714 @@
+package engine
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// linearChildren is the most children a node has for a linear scan to find one. Nodes with more are indexed
+// by first byte, as in an adaptive radix tree.
+const linearChildren = 8
+
+type trieNode[V any] struct {
+	segment  string
+	children []*trieNode[V] // sorted by the first byte of their segment
+	// index maps a first byte to one more than the index of its child, for nodes with more than
+	// linearChildren children. It is shared by copies of the node until the set of children changes.
+	index    *[256]uint16
+	isEnd    bool
+	fullPath string
+	value    V
+	// origins holds, in order, the keys which map to the key ending at a node of a mapped trie.
+	origins []string
+}
+
+// Trie is a compressed trie of keys, each with a value. Nodes are never changed once they are reachable from a
+// published root, so readers need no locking. Insert and Delete copy the nodes along the path they change and
+// then publish a new root, and Clone shares the current root.
+type Trie[V any] struct {
+	mu    sync.Mutex // serialises writers
+	state atomic.Pointer[trieState[V]]
+}
+
+// trieState is one version of a trie. The copies of the trie with mapped keys, such as case folded ones, and
+// the automata which search for keys anywhere in a text are built on first use.
+type trieState[V any] struct {
+	root   *trieNode[V]
+	size   int
+	mapped [numMappings]mappedTrie[V]
+	search [numMappings]lazyAutomaton
+}
+
+// mappedTrie is a trie of the keys mapped by one Mapping.
+type mappedTrie[V any] struct {
+	once sync.Once
+	root atomic.Pointer[trieNode[V]]
+}
+
+type lazyAutomaton struct {
+	once sync.Once
+	ac   *acAutomaton
+}
+
+// NewTrie returns a trie of the given keys. If value is not nil it gives the value of each key, and of
+// repeated keys the last one is kept.
+func NewTrie[V any](keys []string, value func(i int) V) *Trie[V] {
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	// A stable sort keeps repeated keys in order, so the last of them wins.
+	slices.SortStableFunc(order, func(a, b int) int {
+		return strings.Compare(keys[a], keys[b])
+	})
+	sorted := make([]string, 0, len(keys))
+	values := make([]V, 0, len(keys))
+	for _, i := range order {
+		var v V
+		if value != nil {
+			v = value(i)
+		}
+		if len(sorted) > 0 && sorted[len(sorted)-1] == keys[i] {
+			values[len(values)-1] = v
+			continue
+		}
+		sorted = append(sorted, keys[i])
+		values = append(values, v)
+	}
+	root := buildTrie(sorted, func(n *trieNode[V], i int) {
+		n.value = values[i]
+	})
+	t := &Trie[V]{}
+	t.state.Store(&trieState[V]{root: root, size: len(sorted)})
+	return t
+}
+
+func (t *Trie[V]) load() *trieState[V] {
+	return t.state.Load()
+}
+
+// Len returns the number of keys in the trie.
+func (t *Trie[V]) Len() int {
+	return t.load().size
+}
+
+// Clone returns a trie with the same keys which changes independently of t.
+func (t *Trie[V]) Clone() *Trie[V] {
+	c := &Trie[V]{}
+	c.state.Store(t.load())
+	return c
+}
+
+// Get returns the value of key and whether key is in the trie.
+func (t *Trie[V]) Get(key string) (V, bool) {
+	if n := lookup(t.load().root, key); n != nil && n.isEnd {
+		return n.value, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Contains reports whether key is in the trie.
+func (t *Trie[V]) Contains(key string) bool {
+	_, ok := t.Get(key)
+	return ok
+}
+
+// lookup returns the node at which key ends, or nil if there is none.
+func lookup[V any](n *trieNode[V], key string) *trieNode[V] {
+	for depth := 0; depth < len(key); {
+		child := n.child(key[depth])
+		if child == nil || !hasPrefix(key[depth:], child.segment) {
+			return nil
+		}
+		n = child
+		depth += len(child.segment)
+	}
+	return n
+}
+
+// descend returns the highest node below n whose path starts with prefix, or nil if there is none.
+func descend[V any](n *trieNode[V], prefix string) *trieNode[V] {
+	for depth := 0; depth < len(prefix); {
+		child := n.child(prefix[depth])
+		if child == nil {
+			return nil
+		}
+		l := commonPrefixLen(child.segment, prefix[depth:])
+		if l < len(child.segment) && depth+l < len(prefix) {
+			return nil
+		}
+		n = child
+		depth += l
+	}
+	return n
+}
+
+// KeysWithPrefix calls yield with each key in t which starts with prefix, and its value, in key order, until
+// yield returns false. Under a mapping the keys whose mapped form starts with the mapped prefix are collected
+// and sorted before the first call.
+func KeysWithPrefix[V any](t *Trie[V], prefix string, m Mapping, yield func(key string, value V) bool) {
+	st := t.load()
+	if m == 0 {
+		if n := descend(st.root, prefix); n != nil {
+			walk(n, func(n *trieNode[V]) bool {
+				return yield(n.fullPath, n.value)
+			})
+		}
+		return
+	}
+	n := descend(st.mappedRoot(m), MapString(prefix, m))
+	if n == nil {
+		return
+	}
+	var keys []string
+	walk(n, func(n *trieNode[V]) bool {
+		keys = append(keys, n.origins...)
+		return true
+	})
+	slices.Sort(keys)
+	for _, key := range keys {
+		if !yield(key, lookup(st.root, key).value) {
+			return
+		}
+	}
+}
+
+// Insert sets the value of key and reports whether key was not already in the trie.
+func (t *Trie[V]) Insert(key string, value V) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	old := t.load()
+	added := !t.Contains(key)
+	next := &trieState[V]{size: old.size}
+	next.root = insertNode(old.root, key, 0, func(n *trieNode[V]) {
+		n.value = value
+	})
+	if added {
+		next.size++
+	}
+	for m := Mapping(1); m < numMappings; m++ {
+		mapped := old.mapped[m].root.Load()
+		if mapped == nil {
+			continue
+		}
+		if added {
+			mapped = insertNode(mapped, MapString(key, m), 0, func(n *trieNode[V]) {
+				i, _ := slices.BinarySearch(n.origins, key)
+				n.origins = slices.Insert(slices.Clone(n.origins), i, key)
+			})
+		}
+		next.setMapped(m, mapped)
+	}
+	t.state.Store(next)
+	return added
+}
+
+// Delete removes key from the trie and reports whether it was there.
+func (t *Trie[V]) Delete(key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	old := t.load()
+	root, ok := deleteNode(old.root, key, 0, func(n *trieNode[V]) bool {
+		return false
+	})
+	if !ok {
+		return false
+	}
+	next := &trieState[V]{root: root, size: old.size - 1}
+	for m := Mapping(1); m < numMappings; m++ {
+		mapped := old.mapped[m].root.Load()
+		if mapped == nil {
+			continue
+		}
+		mapped, _ = deleteNode(mapped, MapString(key, m), 0, func(n *trieNode[V]) bool {
+			i, _ := slices.BinarySearch(n.origins, key)
+			n.origins = slices.Delete(slices.Clone(n.origins), i, i+1)
+			return len(n.origins) > 0
+		})
+		next.setMapped(m, mapped)
+	}
+	t.state.Store(next)
+	return true
+}
+
+// walk calls fn for each node below n, including n, at which a key ends, in key order, until fn returns
+// false. It reports whether fn always returned true.
+func walk[V any](n *trieNode[V], fn func(n *trieNode[V]) bool) bool {
+	if n.isEnd && !fn(n) {
+		return false
+	}
+	for _, child := range n.children {
+		if !walk(child, fn) {
+			return false
+		}
+	}
+	return true
+}
+
+// child returns the child whose segment starts with b, or nil.
+func (n *trieNode[V]) child(b byte) *trieNode[V] {
+	if n.index != nil {
+		if i := n.index[b]; i != 0 {
+			return n.children[i-1]
+		}
+		return nil
+	}
+	for _, c := range n.children {
+		if c.segment[0] >= b {
+			if c.segment[0] == b {
+				return c
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// setChildren sets the children of n and indexes them if there are too many to scan.
+func (n *trieNode[V]) setChildren(children []*trieNode[V]) {
+	n.children, n.index = children, nil
+	if len(children) > linearChildren {
+		n.index = new([256]uint16)
+		for i, c := range children {
+			n.index[c.segment[0]] = uint16(i + 1)
+		}
+	}
+}
+
+// childIndex returns the index of the child whose segment starts with b, or where it would be inserted.
+func (n *trieNode[V]) childIndex(b byte) (int, bool) {
+	return slices.BinarySearchFunc(n.children, b, func(c *trieNode[V], b byte) int {
+		return int(c.segment[0]) - int(b)
+	})
+}
+
+// withChild returns a copy of n with the child at i replaced by c, or c inserted at i if insert is set.
+// A nil c removes the child at i.
+func (n *trieNode[V]) withChild(i int, c *trieNode[V], insert bool) *trieNode[V] {
+	cp := *n
+	switch {
+	case insert:
+		cp.setChildren(slices.Insert(slices.Clone(n.children), i, c))
+	case c == nil:
+		cp.setChildren(slices.Delete(slices.Clone(n.children), i, i+1))
+	default:
+		cp.children = slices.Clone(n.children)
+		cp.children[i] = c
+	}
+	return &cp
+}
+
+// insertNode returns a copy of n, whose segment ends at depth bytes into key, with key added below it. set
+// updates the copy of the node at which key ends.
+func insertNode[V any](n *trieNode[V], key string, depth int, set func(n *trieNode[V])) *trieNode[V] {
+	if depth == len(key) {
+		cp := *n
+		cp.isEnd, cp.fullPath = true, key
+		set(&cp)
+		return &cp
+	}
+	i, ok := n.childIndex(key[depth])
+	if !ok {
+		leaf := &trieNode[V]{segment: key[depth:], isEnd: true, fullPath: key}
+		set(leaf)
+		return n.withChild(i, leaf, true)
+	}
+	child := n.children[i]
+	l := commonPrefixLen(child.segment, key[depth:])
+	if l == len(child.segment) {
+		return n.withChild(i, insertNode(child, key, depth+l, set), false)
+	}
+
+	// Split the child where key leaves its segment.
+	rest := *child
+	rest.segment = child.segment[l:]
+	mid := &trieNode[V]{segment: child.segment[:l], children: []*trieNode[V]{&rest}}
+	return n.withChild(i, insertNode(mid, key, depth+l, set), false)
+}
+
+// deleteNode returns a copy of n, whose segment ends at depth bytes into key, with key removed below it, and
+// whether key was found. unset updates the copy of the node at which key ends, and reports whether a key
+// still ends there. Nodes left without a key or children are removed, and a node left with a single child
+// and no key is merged with the child. The root is never removed or merged.
+func deleteNode[V any](n *trieNode[V], key string, depth int, unset func(n *trieNode[V]) bool) (*trieNode[V], bool) {
+	if depth == len(key) {
+		if !n.isEnd {
+			return n, false
+		}
+		cp := *n
+		if !unset(&cp) {
+			var zero V
+			cp.isEnd, cp.fullPath, cp.value = false, "", zero
+		}
+		return compactNode(&cp, depth), true
+	}
+	i, ok := n.childIndex(key[depth])
+	if !ok || !hasPrefix(key[depth:], n.children[i].segment) {
+		return n, false
+	}
+	child, found := deleteNode(n.children[i], key, depth+len(n.children[i].segment), unset)
+	if !found {
+		return n, false
+	}
+	return compactNode(n.withChild(i, child, false), depth), true
+}
+
+// compactNode returns n, nil if it can be removed, or its merged child if it has only one. The root, at depth
+// 0, is kept as is.
+func compactNode[V any](n *trieNode[V], depth int) *trieNode[V] {
+	if n.isEnd || depth == 0 {
+		return n
+	}
+	switch len(n.children) {
+	case 0:
+		return nil
+	case 1:
+		merged := *n.children[0]
+		merged.segment = n.segment + merged.segment
+		return &merged
+	}
+	return n
+}
+
+func commonPrefixLen(a, b string) int {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
+
+// buildTrie builds a compressed trie from sorted keys. atEnd is called with the node at which each key ends
+// and the index of the key, including for repeated keys.
+func buildTrie[V any](sorted []string, atEnd func(n *trieNode[V], i int)) *trieNode[V] {
+	root := &trieNode[V]{}
+	if len(sorted) == 0 {
+		return root
+	}
+
+	// Build trie from sorted list
+	var insert func(parent *trieNode[V], start, end int, depth int)
+	insert = func(parent *trieNode[V], start, end int, depth int) {
+		if start >= end {
+			return
+		}
+
+		i := start
+		// Check if the items end here (are a prefix of others in this group)
+		// Use loop to handle duplicates
+		for i < end && len(sorted[i]) == depth {
+			parent.fullPath = sorted[i]
+			parent.isEnd = true
+			atEnd(parent, i)
+			i++
+		}
+
+		for i < end {
+			// Identify group starting with same char
+			char := sorted[i][depth]
+			groupStart := i
+			i++
+			// Scan to find end of group
+			for i < end && len(sorted[i]) > depth && sorted[i][depth] == char {
+				i++
+			}
+
+			// Found a group [groupStart, i)
+			// Find common prefix for this group to compress the trie (Patricia Trie optimization)
+			lcp := 0
+			s1 := sorted[groupStart]
+			s2 := sorted[i-1]
+			minLen := len(s1)
+			if len(s2) < minLen {
+				minLen = len(s2)
+			}
+
+			for k := 0; k < minLen-depth; k++ {
+				if s1[depth+k] == s2[depth+k] {
+					lcp++
+				} else {
+					break
+				}
+			}
+
+			// Create node
+			segment := s1[depth : depth+lcp]
+			node := &trieNode[V]{segment: segment}
+			parent.children = append(parent.children, node)
+
+			// Recurse
+			insert(node, groupStart, i, depth+lcp)
+		}
+		parent.setChildren(parent.children)
+	}
+
+	insert(root, 0, len(sorted), 0)
+
+	return root
+}
+
+// mappedRoot returns a trie built from the form of every key mapped by m, which is not 0. It is built on
+// first use.
+func (st *trieState[V]) mappedRoot(m Mapping) *trieNode[V] {
+	mt := &st.mapped[m]
+	if root := mt.root.Load(); root != nil {
+		return root
+	}
+	mt.once.Do(func() {
+		type pair struct{ mapped, key string }
+		var pairs []pair
+		walk(st.root, func(n *trieNode[V]) bool {
+			pairs = append(pairs, pair{MapString(n.fullPath, m), n.fullPath})
+			return true
+		})
+		slices.SortFunc(pairs, func(a, b pair) int {
+			if c := strings.Compare(a.mapped, b.mapped); c != 0 {
+				return c
+			}
+			return strings.Compare(a.key, b.key)
+		})
+		mapped := make([]string, len(pairs))
+		for i, p := range pairs {
+			mapped[i] = p.mapped
+		}
+		mt.root.Store(buildTrie(mapped, func(n *trieNode[V], i int) {
+			n.origins = append(n.origins, pairs[i].key)
+		}))
+	})
+	return mt.root.Load()
+}
+
+// automaton returns an Aho-Corasick automaton of the keys as mapped by m.
+func (st *trieState[V]) automaton(m Mapping) *acAutomaton {
+	la := &st.search[m]
+	la.once.Do(func() {
+		var keys []string
+		walk(st.root, func(n *trieNode[V]) bool {
+			keys = append(keys, MapString(n.fullPath, m))
+			return true
+		})
+		la.ac = newACAutomaton(keys)
+		if m&mapFold != 0 {
+			la.ac.foldBegins()
+		}
+	})
+	return la.ac
+}
+
+// setMapped sets the trie mapped by m of a new state.
+func (st *trieState[V]) setMapped(m Mapping, root *trieNode[V]) {
+	mt := &st.mapped[m]
+	mt.once.Do(func() {
+		mt.root.Store(root)
+	})
+}
+
+// LongestPrefix returns the length of the longest key in t which is a prefix of text, and whether there is one.
+func LongestPrefix[T string | []byte, V any](t *Trie[V], text T, m Mapping) (int, bool) {
+	n, node, _ := longestPrefix(t.load(), text, m)
+	return n, node != nil
+}
+
+// LongestPrefixKey is LongestPrefix which also returns the key which matched and its value. Under a mapping
+// several keys may match the same text, and the first of them in key order is returned.
+func LongestPrefixKey[T string | []byte, V any](t *Trie[V], text T, m Mapping) (int, string, V, bool) {
+	st := t.load()
+	n, node, _ := longestPrefix(st, text, m)
+	return resolve(st, n, node, m)
+}
+
+// ShortestPrefixKey is LongestPrefixKey for the shortest key in t which is a prefix of text.
+func ShortestPrefixKey[T string | []byte, V any](t *Trie[V], text T, m Mapping) (int, string, V, bool) {
+	st := t.load()
+	n, node := 0, (*trieNode[V])(nil)
+	walkPrefixes(st, text, m, func(end int, match *trieNode[V]) bool {
+		n, node = end, match
+		return false
+	})
+	return resolve(st, n, node, m)
+}
+
+// AllPrefixes calls yield with the length, key and value of each key in t which is a prefix of text, shortest
+// first, until yield returns false.
+func AllPrefixes[T string | []byte, V any](t *Trie[V], text T, m Mapping, yield func(n int, key string, value V) bool) {
+	st := t.load()
+	walkPrefixes(st, text, m, func(end int, match *trieNode[V]) bool {
+		_, key, value, _ := resolve(st, end, match, m)
+		return yield(end, key, value)
+	})
+}
+
+// resolve returns the key and value of a node found by walkPrefixes. A node of a mapped trie stands for the
+// keys which map to it, and the first of them is used.
+func resolve[V any](st *trieState[V], n int, node *trieNode[V], m Mapping) (int, string, V, bool) {
+	if node == nil {
+		var zero V
+		return 0, "", zero, false
+	}
+	if m == 0 {
+		return n, node.fullPath, node.value, true
+	}
+	key := node.origins[0]
+	return n, key, lookup(st.root, key).value, true
+}
+
+// longestPrefix returns the length of the longest key in st which is a prefix of text and the node at which
+// it ends, or nil if there is none. It also reports whether more text could change the result, because the
+// text ran out part way along a key or in the middle of a rune.
+func longestPrefix[T string | []byte, V any](st *trieState[V], text T, m Mapping) (int, *trieNode[V], bool) {
+	n, node := 0, (*trieNode[V])(nil)
+	more := walkPrefixes(st, text, m, func(end int, match *trieNode[V]) bool {
+		n, node = end, match
+		return true
+	})
+	return n, node, more
+}
+
+// walkPrefixes calls visit with the length of each key in st which is a prefix of text, shortest first, and
+// the node at which it ends, until visit returns false. Under a mapping the nodes are those of the mapped
+// trie. It reports whether more text could match a longer key, because the text ran out part way along a key
+// or in the middle of a rune, which is only meaningful if visit never returned false.
+func walkPrefixes[T string | []byte, V any](st *trieState[V], text T, m Mapping, visit func(n int, node *trieNode[V]) bool) bool {
+	switch {
+	case m.normalizes():
+		return walkPrefixesNorm(st.mappedRoot(m), text, m, visit)
+	case m != 0:
+		return walkPrefixesFold(st.mappedRoot(m), text, visit)
+	}
+	curr := st.root
+
+	// Check match at root (empty string)
+	if curr.isEnd && !visit(0, curr) {
+		return false
+	}
+
+	idx := 0
+	for idx < len(text) {
+		next := curr.child(text[idx])
+		if next == nil {
+			return false
+		}
+		// Check if full segment matches
+		if !hasPrefix(text[idx:], next.segment) {
+			// Mismatch within segment or text too short
+			rest := len(text) - idx
+			return rest < len(next.segment) && string(text[idx:]) == next.segment[:rest]
+		}
+		idx += len(next.segment)
+		curr = next
+		if curr.isEnd && !visit(idx, curr) {
+			return false
+		}
+	}
+
+	return len(curr.children) > 0
+}
+
+// walkPrefixesFold walks the case folded trie from curr one input rune at a time. Folded runes can differ in
+// length from the input runes, so matches are reported as offsets into text rather than stored paths.
+func walkPrefixesFold[T string | []byte, V any](curr *trieNode[V], text T, visit func(n int, node *trieNode[V]) bool) bool {
+	pos := 0 // bytes of curr.segment matched so far
+	if curr.isEnd && !visit(0, curr) {
+		return false
+	}
+	var buf [utf8.UTFMax]byte
+
+	for idx := 0; idx < len(text); {
+		partial := !fullRune(text[idx:])
+		folded, w := appendFoldedRune(buf[:0], text[idx:])
+		for _, b := range folded {
+			if pos < len(curr.segment) {
+				if curr.segment[pos] != b {
+					return partial
+				}
+				pos++
+				continue
+			}
+			next := curr.child(b)
+			if next == nil {
+				return partial
+			}
+			curr = next
+			pos = 1
+		}
+		idx += w
+		if pos == len(curr.segment) && curr.isEnd && !visit(idx, curr) {
+			return false
+		}
+	}
+
+	return pos < len(curr.segment) || len(curr.children) > 0
+}
+
+// walkPrefixesNorm is walkPrefixesFold for a mapping which normalizes. The mapped trie is walked one input
+// segment at a time, and keys only match where a segment ends. More text could add combining marks to the last
+// segment, so that is only settled if the walk fails on the starter it begins with.
+func walkPrefixesNorm[T string | []byte, V any](curr *trieNode[V], text T, m Mapping, visit func(n int, node *trieNode[V]) bool) bool {
+	pos := 0 // bytes of curr.segment matched so far
+	if curr.isEnd && !visit(0, curr) {
+		return false
+	}
+	var sg segmenter
+	// walk moves along the trie by the bytes of the segment, returning how many it matched and whether it
+	// matched them all.
+	walk := func() (int, bool) {
+		n, ok := 0, true
+		sg.flush(m, func(b byte, _, _ bool) {
+			if !ok {
+				return
+			}
+			if pos < len(curr.segment) {
+				if curr.segment[pos] != b {
+					ok = false
+					return
+				}
+				pos++
+				n++
+				return
+			}
+			next := curr.child(b)
+			if next == nil {
+				ok = false
+				return
+			}
+			curr, pos = next, 1
+			n++
+		})
+		return n, ok
+	}
+
+	partial := false
+	for idx := 0; idx < len(text); {
+		r, w := decodeSegmentRune(text[idx:])
+		if sg.breaksBefore(r, m) {
+			end := sg.end
+			if _, ok := walk(); !ok {
+				return false
+			}
+			if pos == len(curr.segment) && curr.isEnd && !visit(end, curr) {
+				return false
+			}
+		}
+		partial = !fullRune(text[idx:])
+		sg.add(r, w, idx, false, m)
+		idx += w
+	}
+	if sg.n == 0 {
+		return pos < len(curr.segment) || len(curr.children) > 0
+	}
+	end, lead := sg.end, sg.leadLen(m)
+	n, ok := walk()
+	if !ok {
+		return partial || n >= lead
+	}
+	if pos == len(curr.segment) && curr.isEnd {
+		visit(end, curr)
+	}
+	return true
+}
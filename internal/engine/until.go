@@ -0,0 +1,368 @@
+package engine
+
+import (
+	"bufio"
+	"unicode/utf8"
+
+	"github.com/arran4/go-consume"
+)
+
+// Separators holds the automata used to find a set of literal separators, and any patterns which are
+// separators too. The automata of mapped separators, such as case folded ones, are built on first use.
+type Separators struct {
+	keys     []string
+	patterns []Pattern
+	exact    *acAutomaton
+	mapped   [numMappings]lazyAutomaton
+}
+
+func NewSeparators(keys []string, patterns ...Pattern) *Separators {
+	s := &Separators{keys: keys, patterns: patterns}
+	if len(keys) > 0 {
+		s.exact = newACAutomaton(keys)
+	}
+	return s
+}
+
+// Keys returns the literal separators.
+func (s *Separators) Keys() []string {
+	if s == nil {
+		return nil
+	}
+	return s.keys
+}
+
+// Patterns returns the separators which are not literal strings.
+func (s *Separators) Patterns() []Pattern {
+	if s == nil {
+		return nil
+	}
+	return s.patterns
+}
+
+func (s *Separators) automaton(m Mapping) *acAutomaton {
+	if s == nil || s.exact == nil {
+		return nil
+	}
+	if m == 0 {
+		return s.exact
+	}
+	la := &s.mapped[m]
+	la.once.Do(func() {
+		mapped := make([]string, len(s.keys))
+		for i, k := range s.keys {
+			mapped[i] = MapString(k, m)
+		}
+		la.ac = newACAutomaton(mapped)
+	})
+	return la.ac
+}
+
+// separatorSpan returns the byte span of the leftmost-longest separator of s in data, whether literal or a
+// pattern, which meets the conditions o places on the input around it. prev is the rune before data, or NoRune
+// at the start of the input, and atEOF reports whether data runs to the end of the input. It finds nothing if
+// a separator cannot be settled without more input. Before EOF it also returns where a search of the same data
+// with more after it may resume from, as resumeAt describes, which is never inside a run it has rejected, as
+// the end of the run is not a run.
+func separatorSpan[T string | []byte](s *Separators, data T, o *consume.Config, prev rune, atEOF bool) (int, int, bool, int) {
+	cfg := o
+	var step consume.Config
+	rejected := len(data) // the start of the first rejected run which a resumed search could start inside
+	for {
+		start, end, found, pending := runSpan(s, data, cfg)
+		if pending < 0 {
+			pending = len(data)
+		}
+		if found && !atEOF && pending <= start {
+			// A longer separator, or one which starts sooner, may yet match.
+			return 0, 0, false, resumeAt(s, data, o, min(pending, rejected), atEOF)
+		}
+		if !found {
+			return start, end, false, resumeAt(s, data, o, min(pending, rejected), atEOF)
+		}
+		if !hasBounds(o) {
+			return start, end, true, resumeAt(s, data, o, min(start, rejected), atEOF)
+		}
+		switch checkBounds(data, start, end, o, prev, atEOF) {
+		case accepted:
+			return start, end, true, resumeAt(s, data, o, min(start, rejected), atEOF)
+		case undecided:
+			return 0, 0, false, resumeAt(s, data, o, min(start, rejected), atEOF)
+		}
+		if o.CollapseRuns && rejected == len(data) && end >= resumeAt(s, data, o, len(data), atEOF) {
+			rejected = start
+		}
+		// Search on from the next rune a separator may start at, or past a run, as part of a run is not a run.
+		// That position is outside any escape or encasing, as StartOffset expects.
+		step = *o
+		step.StartOffset, _ = keyStart(data, start, MappingOf(o))
+		if o.CollapseRuns {
+			step.StartOffset = end
+		}
+		cfg = &step
+	}
+}
+
+// resumeAt returns where a search of data may resume from once more input follows it. That is no later than
+// before, where a separator which more input could settle starts, and no later than a separator which reaches
+// past the end of data could start, nor than o.StartOffset. It is at the start of a rune, or of a segment when
+// normalizing, but may be inside an escape or encasing. At EOF there is no more input, and it returns
+// o.StartOffset.
+func resumeAt[T string | []byte](s *Separators, data T, o *consume.Config, before int, atEOF bool) int {
+	if atEOF {
+		return o.StartOffset
+	}
+	reach := s.reach(o)
+	if reach < 0 {
+		return o.StartOffset
+	}
+	m := MappingOf(o)
+	i := max(o.StartOffset, min(before, len(data)-reach))
+	for i > o.StartOffset && !mayStartAt(data, i, m) {
+		i--
+	}
+	return i
+}
+
+// runSpan returns the byte span of the leftmost-longest separator of s in data, taking in the separators
+// which follow it with CollapseRuns, and the pending position firstSeparator returns.
+func runSpan[T string | []byte](s *Separators, data T, o *consume.Config) (int, int, bool, int) {
+	start, end, found, pending := firstSeparator(s, data, o)
+	if found && o.CollapseRuns {
+		for {
+			next, ok := separatorAt(s, data, o, end)
+			if !ok {
+				break
+			}
+			end = next
+		}
+	}
+	return start, end, found, pending
+}
+
+// firstSeparator returns the byte span of the leftmost-longest single separator of s in data, and where a
+// literal separator which more data could complete starts, as findSeparator does.
+func firstSeparator[T string | []byte](s *Separators, data T, o *consume.Config) (int, int, bool, int) {
+	start, end, found, pending := findSeparator(s.automaton(MappingOf(o)), data, o)
+	if len(s.Patterns()) == 0 {
+		return start, end, found, pending
+	}
+	// A pattern can only win by starting no later than the literal separator.
+	limit := len(data)
+	if found {
+		limit = start + 1
+	}
+	if ps, pe, ok := findPatterns(s.patterns, data, o, limit); ok && (!found || ps < start || pe > end) {
+		start, end, found = ps, pe, true
+	}
+	return start, end, found, pending
+}
+
+// separatorAt returns the end of the longest separator of s which starts at i. As with StartOffset, the
+// input at i is taken to be outside any escape or encasing.
+func separatorAt[T string | []byte](s *Separators, data T, o *consume.Config, i int) (int, bool) {
+	if i >= len(data) {
+		return 0, false
+	}
+	cfg := *o
+	cfg.StartOffset, cfg.Ignore0PositionMatch = 0, false
+	end, found := 0, false
+	m := MappingOf(o)
+	if ac := s.automaton(m); ac != nil {
+		window := data[i:min(len(data), i+runWindow(ac, m))]
+		if start, e, ok, _ := findSeparator(ac, window, &cfg); ok && start == 0 {
+			end, found = i+e, true
+		}
+	}
+	if len(s.patterns) > 0 {
+		cfg.StartOffset = i
+		if _, e, ok := findPatterns(s.patterns, data, &cfg, i+1); ok && e > end {
+			end, found = e, true
+		}
+	}
+	return end, found
+}
+
+// tail returns how many bytes must follow a separator before a split function can be sure of it, as more
+// input could make a pattern match longer, add combining marks to a normalized separator or, with
+// CollapseRuns, carry on the run. A pattern needs a whole rune after it, as the rest of one which has been
+// cut short may yet match.
+func (s *Separators) tail(cfg *consume.Config) int {
+	n := 0
+	if cfg.CollapseRuns {
+		n = 1
+	}
+	if len(s.Patterns()) > 0 {
+		n = utf8.UTFMax
+	}
+	m := MappingOf(cfg)
+	if m.normalizes() {
+		n = utf8.UTFMax
+	}
+	if ac := s.automaton(m); ac != nil && cfg.CollapseRuns {
+		n = max(n, runWindow(ac, m))
+	}
+	return n
+}
+
+// reach returns how far before the end of the input a separator may start which more input could add to, or
+// -1 if there is no limit, as a regular expression can match any amount of input. A run with CollapseRuns is
+// only bounded once it is found.
+func (s *Separators) reach(cfg *consume.Config) int {
+	for _, p := range s.Patterns() {
+		if _, ok := p.(RegexpPattern); ok {
+			return -1
+		}
+	}
+	// A rune pattern matches a whole rune, which may not all have been read.
+	n := utf8.UTFMax
+	m := MappingOf(cfg)
+	if ac := s.automaton(m); ac != nil {
+		n = max(n, runWindow(ac, m))
+	}
+	return n
+}
+
+// runWindow returns how many bytes of input hold any separator of ac, even one whose mapped form is shorter,
+// along with the rune after it when normalizing, which settles where its last segment ends.
+func runWindow(ac *acAutomaton, m Mapping) int {
+	if m.normalizes() {
+		return (ac.maxLen + 1) * utf8.UTFMax
+	}
+	return ac.maxLen * utf8.UTFMax
+}
+
+// Until finds the first separator in from and splits it as UntilConsumer.Consume describes. prev is the rune
+// before from, for MustBePrecededBy and WordBoundary, or NoRune if from is the start of the input.
+func Until[T string | []byte](s *Separators, from T, cfg *consume.Config, prev rune) (T, T, T, bool) {
+	ac := s.automaton(MappingOf(cfg))
+	start, end, found, _ := separatorSpan(s, from, cfg, prev, true)
+	if cfg.MustMatchWholeString {
+		// The empty string is only a separator of empty input, which findSeparator never scans.
+		found = (found && start == 0 && end == len(from)) || (len(from) == 0 && ac != nil && ac.term[0])
+	}
+	if found {
+		// DecodeToken only ever removes bytes, so an unchanged length means an unchanged token.
+		matched := DecodeToken(from, start, cfg)
+		if cfg.Inclusive {
+			if len(matched) == start {
+				return from[:end], from[start:end], from[end:], true
+			}
+			return concat(matched, from[start:end]), from[start:end], from[end:], true
+		}
+		return matched, from[start:end], from[start:], true
+	}
+	if cfg.ConsumeRemainingIfNotFound {
+		return DecodeToken(from, len(from), cfg), from[:0], from[len(from):], true
+	}
+	return from[:0], from[:0], from, false
+}
+
+// concat returns a joined with b. A decoded token is never shared with the input, so it may be appended to.
+func concat[T string | []byte](a, b T) T {
+	switch a := any(a).(type) {
+	case string:
+		return T(a + string(b))
+	case []byte:
+		return T(append(a, any(b).([]byte)...))
+	}
+	panic("unreachable")
+}
+
+// UntilE is Until for ConsumeE. When no separator is found it reports input which ends inside an encasing or
+// with a dangling escape.
+func UntilE[T string | []byte](s *Separators, from T, cfg *consume.Config, prev rune) (T, T, T, bool, error) {
+	remainingIfNotFound := cfg.ConsumeRemainingIfNotFound
+	cfg.ConsumeRemainingIfNotFound = false
+	matched, separator, remaining, found := Until(s, from, cfg, prev)
+	if found {
+		return matched, separator, remaining, true, nil
+	}
+	if err := CheckTerminated(from, cfg); err != nil {
+		return from[:0], from[:0], from, false, err
+	}
+	if remainingIfNotFound {
+		return DecodeToken(from, len(from), cfg), from[:0], from[len(from):], true, nil
+	}
+	return from[:0], from[:0], from, false, nil
+}
+
+// UntilIterator splits from on the separators as UntilConsumer.Iterator describes.
+func UntilIterator[T string | []byte](s *Separators, from T, cfg consume.Config) func(yield func(T, T) bool) {
+	return func(yield func(T, T) bool) {
+		// StartOffset only applies to the first run
+		stepCfg := cfg
+		prev := NoRune
+		for {
+			matched, separator, remaining, found := Until(s, from, &stepCfg, prev)
+			stepCfg.StartOffset = 0
+
+			if !found {
+				yield(DecodeToken(from, len(from), &stepCfg), from[:0])
+				return
+			}
+
+			if !yield(matched, separator) {
+				return
+			}
+
+			last := from
+			if cfg.Inclusive {
+				from = remaining
+			} else {
+				if len(separator) > 0 {
+					from = remaining[len(separator):]
+				} else {
+					if len(matched) > 0 {
+						from = remaining
+					} else {
+						if len(from) > 0 {
+							from = from[1:]
+						} else {
+							return
+						}
+					}
+				}
+			}
+			if n := len(last) - len(from); n > 0 {
+				prev = PrevRune(last, n)
+			}
+		}
+	}
+}
+
+// UntilSplitFunc returns a bufio.SplitFunc which yields the text between separators.
+// The split function remembers the last rune it advanced past, for MustBePrecededBy and WordBoundary, so it
+// must only be used by one scanner.
+func UntilSplitFunc(s *Separators, cfg consume.Config) bufio.SplitFunc {
+	tail := s.tail(&cfg)
+	prev := NoRune
+	split := func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+
+		if start, end, found, _ := separatorSpan(s, data, &cfg, prev, atEOF); found && (atEOF || len(data)-end >= tail) {
+			token := DecodeToken(data, start, &cfg)
+			if cfg.Inclusive {
+				if len(token) == start {
+					return end, data[:end], nil
+				}
+				return end, append(token, data[start:end]...), nil
+			}
+			return end, token, nil
+		}
+
+		if atEOF {
+			return len(data), DecodeToken(data, len(data), &cfg), nil
+		}
+		return 0, nil, nil
+	}
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := split(data, atEOF)
+		if advance > 0 {
+			prev = PrevRune(data, advance)
+		}
+		return advance, token, err
+	}
+}
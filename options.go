@@ -4,13 +4,72 @@ type Inclusive bool
 type StartOffset int
 type Ignore0PositionMatch bool
 type MustBeFollowedBy func(rune) bool
+
+// MustBePrecededBy requires the rune before a match to satisfy the predicate. A match at the start of the
+// input needs no rune before it.
+type MustBePrecededBy func(rune) bool
+
 type MustBeAtEnd bool
 type CaseInsensitive bool
+
+// Normalization matches keys and separators under a Unicode normalization form, so that text written with
+// precomposed characters, such as "é", matches the same text written with combining marks, such as "e\u0301".
+// The returned slices are always taken from the input as it is. A match starts and ends at the edges of a
+// base character and the combining marks after it, so a key never matches part of a character.
+//
+// NFC and NFD match the same text, as two strings are equal in one form exactly when they are equal in the
+// other, and so do NFKC and NFKD, which also match compatibility characters such as "ﬁ" and "ｆｕｌｌ" with
+// their plain forms. The decompositions come from a table built into the package which covers Latin, Greek
+// and Cyrillic letters, punctuation, letterlike symbols, number forms, ligatures and fullwidth forms, as well
+// as Hangul syllables. Characters outside it are matched as they are.
+type Normalization int
+
+const (
+	// NoNormalization matches the bytes as they are.
+	NoNormalization Normalization = iota
+	NFC
+	NFD
+	NFKC
+	NFKD
+)
+
 type MustMatchWholeString bool
 type ConsumeRemainingIfNotFound bool
 
 type Escape string
 type Encasing struct {
-	Start, End string
+	Start string `json:"start"`
+	End   string `json:"end"`
 }
 type EscapeBreaksEncasing bool
+
+// Unescape removes escape strings from the returned token, keeping the runes they escape.
+type Unescape bool
+
+// StripEncasing removes the start and end of outermost encasings from the returned token.
+type StripEncasing bool
+
+// Strict makes split functions and scanners fail with an *UnsupportedOptionError for options they do not
+// support, rather than silently ignoring them. Methods which cannot return an error, such as Consume, ignore
+// it; their ConsumeE variants and With always report unsupported options.
+type Strict bool
+
+// CollapseRuns makes a run of adjacent separators count as one, so that no empty tokens come between them.
+type CollapseRuns bool
+
+// WordBoundary requires a match to start and end at word boundaries, as \b does in a regular expression, so
+// that a separator such as "and" only matches a whole word. Letters, digits and underscores are word runes,
+// and the start and end of the input are not.
+type WordBoundary bool
+
+// NoMatch selects what a split function does with input which does not start with a match.
+type NoMatch int
+
+const (
+	// NoMatchError stops the scan with ErrNoMatch.
+	NoMatchError NoMatch = iota
+	// NoMatchSkip discards the input up to the next position where a match may start.
+	NoMatchSkip
+	// NoMatchToken yields the input up to the next position where a match may start as a token of its own.
+	NoMatchToken
+)
@@ -0,0 +1,35 @@
+package parse_test
+
+import (
+	"fmt"
+
+	"github.com/arran4/go-consume"
+	"github.com/arran4/go-consume/parse"
+	"github.com/arran4/go-consume/strconsume"
+)
+
+type command struct {
+	Name  string
+	Flags []string
+	Path  string
+}
+
+func Example() {
+	space := parse.Literal(" ")
+	word := parse.Until(strconsume.NewUntilConsumer(" "), consume.ConsumeRemainingIfNotFound(true))
+	name := parse.Label(parse.Prefix(strconsume.NewPrefixConsumer("get", "put", "rm")), "command")
+	flag := parse.Seq3(space, parse.Literal("-"), word, func(_, _, flag string) string { return flag })
+	path := parse.Seq2(space, word, func(_, path string) string { return path })
+
+	cmd := parse.Seq3(name, parse.Many(flag), path, func(name string, flags []string, path string) command {
+		return command{Name: name, Flags: flags, Path: path}
+	})
+
+	c, err := cmd.Parse("get -r -v path/to/thing")
+	fmt.Printf("%+v %v\n", c, err)
+	_, err = cmd.Parse("list path")
+	fmt.Println(err)
+	// Output:
+	// {Name:get Flags:[r v] Path:path/to/thing} <nil>
+	// parse: expected command at offset 0
+}
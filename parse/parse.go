@@ -0,0 +1,322 @@
+// Package parse builds parsers for small grammars out of go-consume consumers. A Parser reads a typed value
+// from the start of its input and passes the rest on, so parsers combine with Seq, Choice, Many, Optional
+// and Map into a parser for the whole grammar. Alternatives are tried in order from the same input, as in a
+// parsing expression grammar, so a Choice takes the first alternative which matches rather than the longest.
+package parse
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/arran4/go-consume"
+)
+
+// Parser reads a value of type T from the start of its input. The zero Parser is not usable.
+type Parser[T any] struct {
+	run func(in string) (T, string, *failure)
+}
+
+// failure records where a parser stopped, as the number of bytes of input left, so that it does not depend on
+// how much input came before.
+type failure struct {
+	rest     int
+	expected []string
+	err      error
+}
+
+func fail(in string, expected ...string) *failure {
+	return &failure{rest: len(in), expected: expected}
+}
+
+// SyntaxError reports input which a parser did not match.
+type SyntaxError struct {
+	// Offset is the byte offset into the input where the parser stopped, and Position is its line and column.
+	Offset   int
+	Position consume.Position
+	// Expected describes what could have matched at Offset, such as a quoted literal or a Label.
+	Expected []string
+	// Err is the error returned by a Func or MapErr, if that is why the parser stopped.
+	Err error
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("parse: %v at offset %d", e.Err, e.Offset)
+	}
+	switch len(e.Expected) {
+	case 0:
+		return fmt.Sprintf("parse: invalid input at offset %d", e.Offset)
+	case 1:
+		return fmt.Sprintf("parse: expected %s at offset %d", e.Expected[0], e.Offset)
+	}
+	return fmt.Sprintf("parse: expected %s or %s at offset %d", strings.Join(e.Expected[:len(e.Expected)-1], ", "),
+		e.Expected[len(e.Expected)-1], e.Offset)
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+func (f *failure) syntaxError(input string) *SyntaxError {
+	offset := len(input) - f.rest
+	return &SyntaxError{Offset: offset, Position: consume.PositionAt(input, offset), Expected: f.expected, Err: f.err}
+}
+
+// Parse parses the whole of input. It returns a *SyntaxError if p does not match or input is left over.
+func (p Parser[T]) Parse(input string) (T, error) {
+	v, rest, f := p.run(input)
+	if f == nil && rest != "" {
+		f = fail(rest, "end of input")
+	}
+	if f != nil {
+		var zero T
+		return zero, f.syntaxError(input)
+	}
+	return v, nil
+}
+
+// ParsePrefix parses the start of input and returns the rest. It returns a *SyntaxError if p does not match.
+func (p Parser[T]) ParsePrefix(input string) (T, string, error) {
+	v, rest, f := p.run(input)
+	if f != nil {
+		return v, input, f.syntaxError(input)
+	}
+	return v, rest, nil
+}
+
+// Func returns a parser which calls f. f returns the value it read and the rest of its input, which must be a
+// suffix of its input. An error from f is reported as a *SyntaxError at the start of its input.
+func Func[T any](f func(input string) (T, string, error)) Parser[T] {
+	return Parser[T]{run: func(in string) (T, string, *failure) {
+		v, rest, err := f(in)
+		if err != nil {
+			return v, in, &failure{rest: len(in), err: err}
+		}
+		return v, rest, nil
+	}}
+}
+
+// Literal matches s exactly.
+func Literal(s string) Parser[string] {
+	expected := strconv.Quote(s)
+	return Parser[string]{run: func(in string) (string, string, *failure) {
+		if !strings.HasPrefix(in, s) {
+			return "", in, fail(in, expected)
+		}
+		return in[:len(s)], in[len(s):], nil
+	}}
+}
+
+// PrefixMatcher is implemented by *strconsume.PrefixConsumer and the prefix matchers written by consumegen.
+type PrefixMatcher interface {
+	LongestPrefix(text string, ops ...any) (string, bool)
+}
+
+// Prefix matches the longest prefix of m at the start of the input, passing ops to m.LongestPrefix. An empty
+// prefix matches too, without consuming input.
+func Prefix(m PrefixMatcher, ops ...any) Parser[string] {
+	return Parser[string]{run: func(in string) (string, string, *failure) {
+		matched, ok := m.LongestPrefix(in, ops...)
+		if !ok {
+			return "", in, fail(in, "prefix")
+		}
+		return matched, in[len(matched):], nil
+	}}
+}
+
+// Until matches the input up to the first match of c, passing ops to c.Consume, and returns what Consume
+// matched. Without consume.Inclusive the rest of the input starts at the match, ready for the next parser,
+// and with it, after the match. With consume.ConsumeRemainingIfNotFound it matches the whole input if c does
+// not.
+func Until(c consume.Consumer, ops ...any) Parser[string] {
+	return Parser[string]{run: func(in string) (string, string, *failure) {
+		matched, _, rest, found := c.Consume(in, ops...)
+		if !found {
+			return "", in, fail(in, "separator")
+		}
+		return matched, rest, nil
+	}}
+}
+
+// Label names what p matches in errors, in place of what p itself expects, when p fails without getting past
+// the start of its input.
+func Label[T any](p Parser[T], name string) Parser[T] {
+	return Parser[T]{run: func(in string) (T, string, *failure) {
+		v, rest, f := p.run(in)
+		if f != nil && f.rest == len(in) && f.err == nil {
+			f = fail(in, name)
+		}
+		return v, rest, f
+	}}
+}
+
+// Seq matches each parser in turn and returns their values.
+func Seq[T any](ps ...Parser[T]) Parser[[]T] {
+	return Parser[[]T]{run: func(in string) ([]T, string, *failure) {
+		vs := make([]T, 0, len(ps))
+		rest := in
+		for _, p := range ps {
+			v, r, f := p.run(rest)
+			if f != nil {
+				return nil, in, f
+			}
+			vs, rest = append(vs, v), r
+		}
+		return vs, rest, nil
+	}}
+}
+
+// Seq2 matches a then b and combines their values with f.
+func Seq2[A, B, R any](a Parser[A], b Parser[B], f func(A, B) R) Parser[R] {
+	return Parser[R]{run: func(in string) (R, string, *failure) {
+		var zero R
+		va, rest, fl := a.run(in)
+		if fl != nil {
+			return zero, in, fl
+		}
+		vb, rest, fl := b.run(rest)
+		if fl != nil {
+			return zero, in, fl
+		}
+		return f(va, vb), rest, nil
+	}}
+}
+
+// Seq3 matches a, b then c and combines their values with f.
+func Seq3[A, B, C, R any](a Parser[A], b Parser[B], c Parser[C], f func(A, B, C) R) Parser[R] {
+	return Parser[R]{run: func(in string) (R, string, *failure) {
+		var zero R
+		va, rest, fl := a.run(in)
+		if fl != nil {
+			return zero, in, fl
+		}
+		vb, rest, fl := b.run(rest)
+		if fl != nil {
+			return zero, in, fl
+		}
+		vc, rest, fl := c.run(rest)
+		if fl != nil {
+			return zero, in, fl
+		}
+		return f(va, vb, vc), rest, nil
+	}}
+}
+
+// Choice returns the value of the first of ps which matches. If none do, it reports the failure which got
+// furthest into the input, with what each alternative expected there.
+func Choice[T any](ps ...Parser[T]) Parser[T] {
+	return Parser[T]{run: func(in string) (T, string, *failure) {
+		var best *failure
+		for _, p := range ps {
+			v, rest, f := p.run(in)
+			if f == nil {
+				return v, rest, nil
+			}
+			best = furthest(best, f)
+		}
+		var zero T
+		if best == nil {
+			best = fail(in)
+		}
+		return zero, in, best
+	}}
+}
+
+// furthest returns whichever of a and b got further, merging what they expected if they got as far.
+func furthest(a, b *failure) *failure {
+	switch {
+	case a == nil || b.rest < a.rest:
+		return b
+	case a.rest < b.rest || a.err != nil:
+		return a
+	case b.err != nil:
+		return b
+	}
+	merged := &failure{rest: a.rest, expected: slices.Clone(a.expected)}
+	for _, e := range b.expected {
+		if !slices.Contains(merged.expected, e) {
+			merged.expected = append(merged.expected, e)
+		}
+	}
+	return merged
+}
+
+// Many matches p as many times as it can, including none, and returns the values. It stops if p matches
+// without consuming input.
+func Many[T any](p Parser[T]) Parser[[]T] {
+	return Parser[[]T]{run: func(in string) ([]T, string, *failure) {
+		var vs []T
+		for {
+			v, rest, f := p.run(in)
+			if f != nil || len(rest) == len(in) {
+				return vs, in, nil
+			}
+			vs, in = append(vs, v), rest
+		}
+	}}
+}
+
+// Many1 is like Many, but p must match at least once.
+func Many1[T any](p Parser[T]) Parser[[]T] {
+	many := Many(p)
+	return Parser[[]T]{run: func(in string) ([]T, string, *failure) {
+		v, rest, f := p.run(in)
+		if f != nil {
+			return nil, in, f
+		}
+		vs, rest, _ := many.run(rest)
+		return append([]T{v}, vs...), rest, nil
+	}}
+}
+
+// Optional returns the value of p, or def without consuming input if p does not match.
+func Optional[T any](p Parser[T], def T) Parser[T] {
+	return Parser[T]{run: func(in string) (T, string, *failure) {
+		v, rest, f := p.run(in)
+		if f != nil {
+			return def, in, nil
+		}
+		return v, rest, nil
+	}}
+}
+
+// Map converts the value of p with f.
+func Map[T, U any](p Parser[T], f func(T) U) Parser[U] {
+	return Parser[U]{run: func(in string) (U, string, *failure) {
+		v, rest, fl := p.run(in)
+		if fl != nil {
+			var zero U
+			return zero, in, fl
+		}
+		return f(v), rest, nil
+	}}
+}
+
+// MapErr converts the value of p with f. An error from f is reported as a *SyntaxError at the start of the
+// text p matched, such as a number which strconv.Atoi rejects.
+func MapErr[T, U any](p Parser[T], f func(T) (U, error)) Parser[U] {
+	return Parser[U]{run: func(in string) (U, string, *failure) {
+		var zero U
+		v, rest, fl := p.run(in)
+		if fl != nil {
+			return zero, in, fl
+		}
+		u, err := f(v)
+		if err != nil {
+			return zero, in, &failure{rest: len(in), err: err}
+		}
+		return u, rest, nil
+	}}
+}
+
+// Lazy returns a parser which calls f the first time it runs and then behaves as the parser f returns, so
+// that a grammar can refer to itself.
+func Lazy[T any](f func() Parser[T]) Parser[T] {
+	get := sync.OnceValue(f)
+	return Parser[T]{run: func(in string) (T, string, *failure) {
+		return get().run(in)
+	}}
+}
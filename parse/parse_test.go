@@ -0,0 +1,213 @@
+package parse
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/arran4/go-consume"
+	"github.com/arran4/go-consume/strconsume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiteral(t *testing.T) {
+	v, rest, err := Literal("ab").ParsePrefix("abc")
+	assert.NoError(t, err)
+	assert.Equal(t, "ab", v)
+	assert.Equal(t, "c", rest)
+
+	_, rest, err = Literal("ab").ParsePrefix("ac")
+	assert.EqualError(t, err, `parse: expected "ab" at offset 0`)
+	assert.Equal(t, "ac", rest)
+}
+
+func TestPrefix(t *testing.T) {
+	p := Prefix(strconsume.NewPrefixConsumer("get", "getall"))
+	v, rest, err := p.ParsePrefix("getall x")
+	assert.NoError(t, err)
+	assert.Equal(t, "getall", v)
+	assert.Equal(t, " x", rest)
+
+	v, err = Prefix(strconsume.NewPrefixConsumer("get"), consume.CaseInsensitive(true)).Parse("GET")
+	assert.NoError(t, err)
+	assert.Equal(t, "GET", v)
+
+	_, err = p.Parse("put")
+	assert.EqualError(t, err, "parse: expected prefix at offset 0")
+}
+
+func TestUntil(t *testing.T) {
+	cu := strconsume.NewUntilConsumer(",")
+	v, rest, err := Until(cu).ParsePrefix("a,b")
+	assert.NoError(t, err)
+	assert.Equal(t, "a", v)
+	assert.Equal(t, ",b", rest)
+
+	v, rest, err = Until(cu, consume.Inclusive(true)).ParsePrefix("a,b")
+	assert.NoError(t, err)
+	assert.Equal(t, "a,", v)
+	assert.Equal(t, "b", rest)
+
+	_, _, err = Until(cu).ParsePrefix("ab")
+	assert.EqualError(t, err, "parse: expected separator at offset 0")
+
+	v, err = Until(cu, consume.ConsumeRemainingIfNotFound(true)).Parse("ab")
+	assert.NoError(t, err)
+	assert.Equal(t, "ab", v)
+}
+
+func TestSeq(t *testing.T) {
+	p := Seq(Literal("a"), Literal("b"), Literal("c"))
+	v, err := p.Parse("abc")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, v)
+
+	_, err = p.Parse("abd")
+	var se *SyntaxError
+	assert.ErrorAs(t, err, &se)
+	assert.Equal(t, 2, se.Offset)
+	assert.Equal(t, []string{`"c"`}, se.Expected)
+
+	pair := Seq2(Literal("x"), Literal("y"), func(a, b string) string { return b + a })
+	v2, err := pair.Parse("xy")
+	assert.NoError(t, err)
+	assert.Equal(t, "yx", v2)
+
+	triple := Seq3(Literal("1"), Literal("2"), Literal("3"), func(a, b, c string) string { return c + b + a })
+	v3, err := triple.Parse("123")
+	assert.NoError(t, err)
+	assert.Equal(t, "321", v3)
+	_, err = triple.Parse("12")
+	assert.EqualError(t, err, `parse: expected "3" at offset 2`)
+}
+
+func TestChoice(t *testing.T) {
+	p := Choice(Literal("ab"), Literal("a"), Literal("abc"))
+	v, rest, err := p.ParsePrefix("abc")
+	assert.NoError(t, err)
+	assert.Equal(t, "ab", v, "the first alternative which matches wins")
+	assert.Equal(t, "c", rest)
+
+	_, err = Choice(Literal("x"), Literal("y"), Literal("x")).Parse("z")
+	assert.EqualError(t, err, `parse: expected "x" or "y" at offset 0`)
+
+	// The alternative which got furthest explains the error.
+	deep := Choice(Seq(Literal("a"), Literal("b")), Seq(Literal("a"), Literal("c"), Literal("d")), Seq(Literal("b")))
+	_, err = deep.Parse("acx")
+	assert.EqualError(t, err, `parse: expected "d" at offset 2`)
+	_, err = deep.Parse("ax")
+	assert.EqualError(t, err, `parse: expected "b" or "c" at offset 1`)
+}
+
+func TestMany(t *testing.T) {
+	p := Many(Literal("ab"))
+	v, rest, err := p.ParsePrefix("ababa")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ab", "ab"}, v)
+	assert.Equal(t, "a", rest)
+
+	v, rest, err = p.ParsePrefix("x")
+	assert.NoError(t, err)
+	assert.Empty(t, v)
+	assert.Equal(t, "x", rest)
+
+	// A parser which consumes nothing does not loop forever.
+	v, err = Many(Optional(Literal("a"), "")).Parse("")
+	assert.NoError(t, err)
+	assert.Empty(t, v)
+
+	_, err = Many1(Literal("ab")).Parse("x")
+	assert.EqualError(t, err, `parse: expected "ab" at offset 0`)
+	v, err = Many1(Literal("ab")).Parse("abab")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ab", "ab"}, v)
+}
+
+func TestOptional(t *testing.T) {
+	p := Seq(Optional(Literal("-"), "+"), Literal("1"))
+	v, err := p.Parse("-1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"-", "1"}, v)
+	v, err = p.Parse("1")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"+", "1"}, v)
+}
+
+func TestMap(t *testing.T) {
+	digits := Until(strconsume.NewUntilConsumer(","), consume.ConsumeRemainingIfNotFound(true))
+	n, err := Map(digits, func(s string) int { return len(s) }).Parse("1234")
+	assert.NoError(t, err)
+	assert.Equal(t, 4, n)
+
+	number := MapErr(digits, strconv.Atoi)
+	list := Seq(number, Map(Literal(","), func(string) int { return 0 }), number)
+	v, err := list.Parse("12,34")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{12, 0, 34}, v)
+
+	_, err = list.Parse("12,3x")
+	var se *SyntaxError
+	assert.ErrorAs(t, err, &se)
+	assert.Equal(t, 3, se.Offset)
+	assert.True(t, errors.Is(err, strconv.ErrSyntax))
+}
+
+func TestLabel(t *testing.T) {
+	verb := Label(Choice(Literal("get"), Literal("put")), "verb")
+	_, err := verb.Parse("rm")
+	assert.EqualError(t, err, "parse: expected verb at offset 0")
+
+	// A failure past the start keeps its own explanation.
+	_, err = Label(Seq(Literal("a"), Literal("b")), "ab").Parse("ac")
+	assert.EqualError(t, err, `parse: expected "b" at offset 1`)
+}
+
+func TestLazy(t *testing.T) {
+	// nested matches balanced parentheses and returns how deep they go.
+	var nested Parser[int]
+	nested = Choice(
+		Seq3(Literal("("), Lazy(func() Parser[int] { return nested }), Literal(")"), func(_ string, depth int, _ string) int {
+			return depth + 1
+		}),
+		Map(Literal(""), func(string) int { return 0 }),
+	)
+	depth, err := nested.Parse("((()))")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, depth)
+
+	_, err = nested.Parse("(()")
+	assert.Error(t, err)
+}
+
+func TestFunc(t *testing.T) {
+	upper := Func(func(in string) (string, string, error) {
+		i := 0
+		for i < len(in) && in[i] >= 'A' && in[i] <= 'Z' {
+			i++
+		}
+		if i == 0 {
+			return "", in, errors.New("expected upper case")
+		}
+		return in[:i], in[i:], nil
+	})
+	v, err := Seq(Literal("x"), upper).Parse("xAB")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"x", "AB"}, v)
+
+	_, err = Seq(Literal("x"), upper).Parse("xab")
+	assert.EqualError(t, err, "parse: expected upper case at offset 1")
+}
+
+func TestSyntaxError_Position(t *testing.T) {
+	line := Seq(Literal("a"), Literal("\n"))
+	_, err := Many(line).Parse("a\na\nb")
+	var se *SyntaxError
+	assert.ErrorAs(t, err, &se)
+	assert.Equal(t, 4, se.Offset)
+	assert.Equal(t, consume.Position{Offset: 4, Line: 3, Column: 1}, se.Position)
+}
+
+func TestParser_Parse_TrailingInput(t *testing.T) {
+	_, err := Literal("a").Parse("ab")
+	assert.EqualError(t, err, "parse: expected end of input at offset 1")
+}
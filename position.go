@@ -0,0 +1,68 @@
+package consume
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Position is a place in the input. Lines start at each "\n", and columns count runes, so a tab or a wide
+// rune is one column.
+type Position struct {
+	// Offset is the byte offset, from 0.
+	Offset int
+	// Line is the line number, from 1.
+	Line int
+	// Column is the rune on the line, from 1.
+	Column int
+}
+
+// PositionAt returns the position of the byte offset in input. It scans input up to offset, so a Cursor is
+// cheaper for finding many positions in order.
+func PositionAt(input string, offset int) Position {
+	return Position{Line: 1, Column: 1}.Advance(input[:offset])
+}
+
+// Advance returns the position after text, which starts at p.
+func (p Position) Advance(text string) Position {
+	p.Offset += len(text)
+	if i := strings.LastIndexByte(text, '\n'); i >= 0 {
+		p.Line += strings.Count(text, "\n")
+		p.Column = 1
+		text = text[i+1:]
+	}
+	p.Column += utf8.RuneCountInString(text)
+	return p
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Span is the part of the input from Start up to End.
+type Span struct {
+	Start, End Position
+}
+
+// Len returns the length of the span in bytes.
+func (s Span) Len() int {
+	return s.End.Offset - s.Start.Offset
+}
+
+func (s Span) String() string {
+	return fmt.Sprintf("%v-%v", s.Start, s.End)
+}
+
+// SpanError is an error about a span of the input, such as one returned by strconsume.Cursor.
+type SpanError struct {
+	Span Span
+	Err  error
+}
+
+func (e *SpanError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Span.Start, e.Err)
+}
+
+func (e *SpanError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,42 @@
+package consume
+
+import "testing"
+
+func TestPositionAt(t *testing.T) {
+	input := "ab\ncdé\n\nxyz"
+	tests := []struct {
+		offset   int
+		expected Position
+	}{
+		{offset: 0, expected: Position{Offset: 0, Line: 1, Column: 1}},
+		{offset: 2, expected: Position{Offset: 2, Line: 1, Column: 3}},
+		{offset: 3, expected: Position{Offset: 3, Line: 2, Column: 1}},
+		{offset: 7, expected: Position{Offset: 7, Line: 2, Column: 4}},
+		{offset: 8, expected: Position{Offset: 8, Line: 3, Column: 1}},
+		{offset: 10, expected: Position{Offset: 10, Line: 4, Column: 2}},
+	}
+	for _, tt := range tests {
+		if got := PositionAt(input, tt.offset); got != tt.expected {
+			t.Errorf("PositionAt(%d) = %+v, expected %+v", tt.offset, got, tt.expected)
+		}
+	}
+
+	// Advancing in pieces gives the same position as advancing at once.
+	p := Position{Line: 1, Column: 1}
+	for _, piece := range []string{"ab", "\ncd", "é\n", "\nx", "yz"} {
+		p = p.Advance(piece)
+	}
+	if expected := PositionAt(input, len(input)); p != expected {
+		t.Errorf("Advance in pieces = %+v, expected %+v", p, expected)
+	}
+}
+
+func TestSpanError(t *testing.T) {
+	err := &SpanError{Span: Span{Start: Position{Offset: 4, Line: 2, Column: 3}}, Err: ErrNoMatch}
+	if got, expected := err.Error(), "2:3: "+ErrNoMatch.Error(); got != expected {
+		t.Errorf("Error() = %q, expected %q", got, expected)
+	}
+	if err.Unwrap() != ErrNoMatch {
+		t.Errorf("Unwrap() = %v, expected ErrNoMatch", err.Unwrap())
+	}
+}
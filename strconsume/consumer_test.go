@@ -0,0 +1,58 @@
+package strconsume
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/arran4/go-consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsumer_Implementations(t *testing.T) {
+	consumers := map[string]consume.Consumer{
+		"UntilConsumer":  NewUntilConsumer(","),
+		"PrefixConsumer": NewPrefixConsumer(","),
+	}
+	for name, c := range consumers {
+		t.Run(name, func(t *testing.T) {
+			before, separator, remaining, found := c.Consume("a,b")
+			assert.True(t, found)
+			assert.Equal(t, "a", before)
+			assert.Equal(t, ",", separator)
+			assert.Equal(t, ",b", remaining)
+		})
+	}
+}
+
+func TestConsumer_Adapters(t *testing.T) {
+	cu := NewUntilConsumer(",", ";")
+	input := "a,b;;c"
+
+	var expected, got [][2]string
+	for m, s := range cu.Iterator(input) {
+		expected = append(expected, [2]string{m, s})
+	}
+	for m, s := range consume.Iterator(cu, input) {
+		got = append(got, [2]string{m, s})
+	}
+	assert.Equal(t, expected, got)
+
+	// PrefixConsumer has an Iterator and SplitFunc of its own, which yield the keys at the start of the input,
+	// but the adapters yield the text between matches for it too.
+	pc := NewPrefixConsumer("GET")
+	got = nil
+	for m, s := range consume.Iterator(pc, "a GET b GET") {
+		got = append(got, [2]string{m, s})
+	}
+	assert.Equal(t, [][2]string{{"a ", "GET"}, {" b ", "GET"}, {"", ""}}, got)
+
+	s := bufio.NewScanner(strings.NewReader("a GET b GET"))
+	s.Split(consume.SplitFunc(pc))
+	var tokens []string
+	for s.Scan() {
+		tokens = append(tokens, s.Text())
+	}
+	assert.NoError(t, s.Err())
+	assert.Equal(t, []string{"a ", " b "}, tokens)
+}
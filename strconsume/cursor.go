@@ -0,0 +1,140 @@
+package strconsume
+
+import (
+	"errors"
+
+	"github.com/arran4/go-consume"
+	"github.com/arran4/go-consume/internal/engine"
+)
+
+// Cursor reads through an input string with consumers, keeping track of the byte offset, line and column it
+// has reached, so that every token it returns carries the span of input it came from.
+type Cursor struct {
+	input string
+	pos   consume.Position
+}
+
+// Token is a piece of input read by a Cursor.
+type Token struct {
+	// Text is the text matched, after any Unescape or StripEncasing, and Span is the raw input it came from.
+	Text string
+	Span consume.Span
+	// Separator is the separator which ended the text, if any, and SeparatorSpan is where it is. Without a
+	// separator, SeparatorSpan is the empty span at the end of Span.
+	Separator     string
+	SeparatorSpan consume.Span
+}
+
+// NewCursor returns a cursor at the start of input.
+func NewCursor(input string) *Cursor {
+	return &Cursor{input: input, pos: consume.Position{Line: 1, Column: 1}}
+}
+
+// Pos returns the position the cursor has reached.
+func (c *Cursor) Pos() consume.Position {
+	return c.pos
+}
+
+// Rest returns the input from the cursor on.
+func (c *Cursor) Rest() string {
+	return c.input[c.pos.Offset:]
+}
+
+// Done reports whether the cursor has reached the end of the input.
+func (c *Cursor) Done() bool {
+	return c.pos.Offset == len(c.input)
+}
+
+// Until reads the text up to the first separator of cu from the cursor on, as cu.Consume does, and moves the
+// cursor past the separator. The cursor always moves past the separator, with or without consume.Inclusive,
+// which only adds it to the token's Text. If no separator is found it returns false and the cursor does not
+// move, unless consume.ConsumeRemainingIfNotFound is set, when it reads the rest of the input. StartOffset is
+// counted from the cursor.
+func (c *Cursor) Until(cu UntilConsumer, ops ...any) (Token, bool) {
+	cfg := engine.MustApplyOptions(cu.config, ops)
+	matched, separator, remaining, found := engine.Until(cu.separators, c.Rest(), &cfg, c.prev())
+	if !found {
+		return Token{}, false
+	}
+	return c.untilToken(matched, separator, remaining, cfg.Inclusive), true
+}
+
+// UntilE is like Until, but reports errors as cu.ConsumeE does. An error about the input, such as a
+// *consume.UnterminatedEncasingError, is returned as a *consume.SpanError holding where the problem is, and
+// the offset in the error it wraps is an offset into the whole input.
+func (c *Cursor) UntilE(cu UntilConsumer, ops ...any) (Token, bool, error) {
+	cfg, err := engine.ApplyOptions(cu.config, "Cursor.UntilE", engine.UntilConsumeSupports, ops)
+	if err == nil {
+		err = consume.CheckSupported("Cursor.UntilE", engine.UntilConsumeSupports, ops...)
+	}
+	if err != nil {
+		return Token{}, false, err
+	}
+	matched, separator, remaining, found, err := engine.UntilE(cu.separators, c.Rest(), &cfg, c.prev())
+	if err != nil {
+		return Token{}, false, c.spanError(err)
+	}
+	if !found {
+		return Token{}, false, nil
+	}
+	return c.untilToken(matched, separator, remaining, cfg.Inclusive), true, nil
+}
+
+// prev returns the rune before the cursor, which MustBePrecededBy and WordBoundary look at.
+func (c *Cursor) prev() rune {
+	return engine.PrevRune(c.input, c.pos.Offset)
+}
+
+// untilToken builds the token for a match of an UntilConsumer on the rest of the input and moves past it.
+func (c *Cursor) untilToken(matched, separator, remaining string, inclusive bool) Token {
+	rest := c.Rest()
+	sepStart := len(rest) - len(remaining)
+	if inclusive {
+		sepStart -= len(separator)
+	}
+	t := Token{Text: matched, Separator: separator}
+	t.Span.Start = c.pos
+	t.Span.End = c.pos.Advance(rest[:sepStart])
+	t.SeparatorSpan.Start = t.Span.End
+	t.SeparatorSpan.End = t.Span.End.Advance(separator)
+	if inclusive {
+		t.Span.End = t.SeparatorSpan.End
+	}
+	c.pos = t.SeparatorSpan.End
+	return t
+}
+
+// Prefix reads the longest prefix of pc at the cursor, as pc.LongestPrefix does, and moves past it. If none
+// matches it returns false and the cursor does not move.
+func (c *Cursor) Prefix(pc *PrefixConsumer, ops ...any) (Token, bool) {
+	matched, ok := pc.LongestPrefix(c.Rest(), ops...)
+	if !ok {
+		return Token{}, false
+	}
+	end := c.pos.Advance(matched)
+	t := Token{Text: matched, Span: consume.Span{Start: c.pos, End: end}, SeparatorSpan: consume.Span{Start: end, End: end}}
+	c.pos = end
+	return t, true
+}
+
+// spanError places an error about the rest of the input at its position in the whole input.
+func (c *Cursor) spanError(err error) error {
+	var unterminated *consume.UnterminatedEncasingError
+	var dangling *consume.DanglingEscapeError
+	var offset, length int
+	switch {
+	case errors.As(err, &unterminated):
+		e := *unterminated
+		e.Offset += c.pos.Offset
+		err, offset, length = &e, unterminated.Offset, len(e.Encasing.Start)
+	case errors.As(err, &dangling):
+		e := *dangling
+		e.Offset += c.pos.Offset
+		err, offset, length = &e, dangling.Offset, len(e.Escape)
+	default:
+		return err
+	}
+	rest := c.Rest()
+	start := c.pos.Advance(rest[:offset])
+	return &consume.SpanError{Span: consume.Span{Start: start, End: start.Advance(rest[offset : offset+length])}, Err: err}
+}
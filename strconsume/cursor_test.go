@@ -0,0 +1,123 @@
+package strconsume
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/arran4/go-consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func pos(offset, line, column int) consume.Position {
+	return consume.Position{Offset: offset, Line: line, Column: column}
+}
+
+func TestCursor_Until(t *testing.T) {
+	c := NewCursor("key = välue\nnext = 2")
+	assert.Equal(t, pos(0, 1, 1), c.Pos())
+
+	tok, ok := c.Until(NewUntilConsumer(" = "))
+	assert.True(t, ok)
+	assert.Equal(t, "key", tok.Text)
+	assert.Equal(t, consume.Span{Start: pos(0, 1, 1), End: pos(3, 1, 4)}, tok.Span)
+	assert.Equal(t, " = ", tok.Separator)
+	assert.Equal(t, consume.Span{Start: pos(3, 1, 4), End: pos(6, 1, 7)}, tok.SeparatorSpan)
+
+	// Columns count runes, and the separator starts a new line.
+	tok, ok = c.Until(NewUntilConsumer("\n"))
+	assert.True(t, ok)
+	assert.Equal(t, "välue", tok.Text)
+	assert.Equal(t, consume.Span{Start: pos(6, 1, 7), End: pos(12, 1, 12)}, tok.Span)
+	assert.Equal(t, consume.Span{Start: pos(12, 1, 12), End: pos(13, 2, 1)}, tok.SeparatorSpan)
+	assert.Equal(t, "next = 2", c.Rest())
+
+	tok, ok = c.Until(NewUntilConsumer(" = "), consume.Inclusive(true))
+	assert.True(t, ok)
+	assert.Equal(t, "next = ", tok.Text)
+	assert.Equal(t, consume.Span{Start: pos(13, 2, 1), End: pos(20, 2, 8)}, tok.Span)
+	assert.Equal(t, consume.Span{Start: pos(17, 2, 5), End: pos(20, 2, 8)}, tok.SeparatorSpan)
+
+	// Without a separator the cursor stays put.
+	_, ok = c.Until(NewUntilConsumer(";"))
+	assert.False(t, ok)
+	assert.Equal(t, pos(20, 2, 8), c.Pos())
+
+	tok, ok = c.Until(NewUntilConsumer(";"), consume.ConsumeRemainingIfNotFound(true))
+	assert.True(t, ok)
+	assert.Equal(t, "2", tok.Text)
+	assert.Equal(t, consume.Span{Start: pos(20, 2, 8), End: pos(21, 2, 9)}, tok.Span)
+	assert.Equal(t, consume.Span{Start: pos(21, 2, 9), End: pos(21, 2, 9)}, tok.SeparatorSpan)
+	assert.True(t, c.Done())
+}
+
+func TestCursor_Until_Unescape(t *testing.T) {
+	c := NewCursor(`a\,b,c`)
+	tok, ok := c.Until(NewUntilConsumer(","), consume.Escape(`\`), consume.Unescape(true))
+	assert.True(t, ok)
+	assert.Equal(t, "a,b", tok.Text)
+	assert.Equal(t, consume.Span{Start: pos(0, 1, 1), End: pos(4, 1, 5)}, tok.Span, "the span covers the raw text")
+	assert.Equal(t, "c", c.Rest())
+}
+
+func TestCursor_Until_WordBoundary(t *testing.T) {
+	// The rune before the cursor counts, so "or" straight after "xor" is not a word of its own.
+	c := NewCursor("a xoror b or c")
+	cu := NewUntilConsumer("xor", "or")
+	tok, ok := c.Until(cu, consume.WordBoundary(true))
+	assert.True(t, ok)
+	assert.Equal(t, "a xoror b ", tok.Text)
+	assert.Equal(t, "or", tok.Separator)
+
+	c = NewCursor("xoror")
+	_, ok = c.Until(cu)
+	assert.True(t, ok)
+	_, ok = c.Until(cu, consume.WordBoundary(true))
+	assert.False(t, ok)
+	assert.Equal(t, "or", c.Rest())
+}
+
+func TestCursor_UntilE(t *testing.T) {
+	c := NewCursor("a,\nb \"open")
+	_, ok, err := c.UntilE(NewUntilConsumer(","))
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	c.Until(NewUntilConsumer("\n"))
+
+	encasing := consume.Encasing{Start: "\"", End: "\""}
+	_, ok, err = c.UntilE(NewUntilConsumer(","), encasing)
+	assert.False(t, ok)
+	var spanErr *consume.SpanError
+	assert.ErrorAs(t, err, &spanErr)
+	assert.Equal(t, consume.Span{Start: pos(5, 2, 3), End: pos(6, 2, 4)}, spanErr.Span)
+	var unterminated *consume.UnterminatedEncasingError
+	assert.True(t, errors.As(err, &unterminated))
+	assert.Equal(t, 5, unterminated.Offset, "the offset is into the whole input")
+	assert.EqualError(t, err, `2:3: consume: unterminated encasing "\"" starting at offset 5`)
+	assert.Equal(t, pos(3, 2, 1), c.Pos())
+
+	_, _, err = c.UntilE(NewUntilConsumer(","), consume.NoMatchSkip)
+	var unsupported *consume.UnsupportedOptionError
+	assert.ErrorAs(t, err, &unsupported)
+}
+
+func TestCursor_Prefix(t *testing.T) {
+	keywords := NewPrefixConsumer("let", "letter", " ", "=")
+	c := NewCursor("let x")
+	tok, ok := c.Prefix(keywords)
+	assert.True(t, ok)
+	assert.Equal(t, "let", tok.Text)
+	assert.Equal(t, consume.Span{Start: pos(0, 1, 1), End: pos(3, 1, 4)}, tok.Span)
+	assert.Equal(t, consume.Span{Start: pos(3, 1, 4), End: pos(3, 1, 4)}, tok.SeparatorSpan)
+
+	tok, ok = c.Prefix(keywords)
+	assert.True(t, ok)
+	assert.Equal(t, " ", tok.Text)
+
+	_, ok = c.Prefix(keywords)
+	assert.False(t, ok)
+	assert.Equal(t, pos(4, 1, 5), c.Pos())
+
+	tok, ok = NewCursor("LET").Prefix(keywords, consume.CaseInsensitive(true))
+	assert.True(t, ok)
+	assert.Equal(t, "LET", tok.Text)
+}
@@ -0,0 +1,86 @@
+package strconsume
+
+import (
+	"github.com/arran4/go-consume/internal/engine"
+)
+
+// AppendBinary appends the binary encoding of the consumer, its paths and the options set by With, to b. The
+// encoding keeps the shape of the trie, so UnmarshalBinary loads it without sorting the paths, which suits
+// building a large consumer ahead of time and embedding it. It returns consume.ErrNotEncodable if the
+// options include MustBePrecededBy or MustBeFollowedBy.
+func (ps *PrefixConsumer) AppendBinary(b []byte) ([]byte, error) {
+	return engine.AppendPrefix(b, ps.trie, &ps.config)
+}
+
+// MarshalBinary returns the binary encoding of the consumer, as AppendBinary describes.
+func (ps *PrefixConsumer) MarshalBinary() ([]byte, error) {
+	return ps.AppendBinary(nil)
+}
+
+// UnmarshalBinary replaces the consumer with one encoded by MarshalBinary. It returns consume.ErrBadEncoding
+// if data is not such an encoding. It must not be called concurrently with other methods.
+func (ps *PrefixConsumer) UnmarshalBinary(data []byte) error {
+	trie, cfg, err := engine.DecodePrefix[struct{}](data)
+	if err != nil {
+		return err
+	}
+	ps.trie, ps.config = trie, cfg
+	return nil
+}
+
+// MarshalJSON returns the consumer as a JSON object of its paths, in order, and the options set by With. It
+// returns consume.ErrNotEncodable if the options include MustBePrecededBy or MustBeFollowedBy.
+func (ps *PrefixConsumer) MarshalJSON() ([]byte, error) {
+	return engine.MarshalPrefixJSON(ps.trie, &ps.config)
+}
+
+// UnmarshalJSON replaces the consumer with one encoded by MarshalJSON. It must not be called concurrently
+// with other methods.
+func (ps *PrefixConsumer) UnmarshalJSON(data []byte) error {
+	trie, cfg, err := engine.UnmarshalPrefixJSON[struct{}](data)
+	if err != nil {
+		return err
+	}
+	ps.trie, ps.config = trie, cfg
+	return nil
+}
+
+// AppendBinary appends the binary encoding of the consumer, its separators and the options set by With, to
+// b. It returns consume.ErrNotEncodable if the options include MustBePrecededBy or MustBeFollowedBy, or a
+// separator is a function, as with NewUntilFuncConsumer. A regular expression is saved as its source, so one
+// made leftmost-longest with Longest is leftmost-first once loaded.
+func (cu UntilConsumer) AppendBinary(b []byte) ([]byte, error) {
+	return engine.AppendUntil(b, cu.separators, &cu.config)
+}
+
+// MarshalBinary returns the binary encoding of the consumer, as AppendBinary describes.
+func (cu UntilConsumer) MarshalBinary() ([]byte, error) {
+	return cu.AppendBinary(nil)
+}
+
+// UnmarshalBinary replaces the consumer with one encoded by MarshalBinary. It returns consume.ErrBadEncoding
+// if data is not such an encoding.
+func (cu *UntilConsumer) UnmarshalBinary(data []byte) error {
+	separators, cfg, err := engine.DecodeUntil(data)
+	if err != nil {
+		return err
+	}
+	cu.separators, cu.config = separators, cfg
+	return nil
+}
+
+// MarshalJSON returns the consumer as a JSON object of its separators and the options set by With. It returns
+// consume.ErrNotEncodable as AppendBinary does.
+func (cu UntilConsumer) MarshalJSON() ([]byte, error) {
+	return engine.MarshalUntilJSON(cu.separators, &cu.config)
+}
+
+// UnmarshalJSON replaces the consumer with one encoded by MarshalJSON.
+func (cu *UntilConsumer) UnmarshalJSON(data []byte) error {
+	separators, cfg, err := engine.UnmarshalUntilJSON(data)
+	if err != nil {
+		return err
+	}
+	cu.separators, cu.config = separators, cfg
+	return nil
+}
@@ -0,0 +1,367 @@
+package strconsume
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"testing"
+	"unicode"
+
+	"github.com/arran4/go-consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixConsumer_MarshalBinary(t *testing.T) {
+	ps, err := NewPrefixConsumer("/api", "/api/v1", "/static", "über", "").With(consume.CaseInsensitive(true))
+	assert.NoError(t, err)
+
+	data, err := ps.MarshalBinary()
+	assert.NoError(t, err)
+
+	var got PrefixConsumer
+	assert.NoError(t, got.UnmarshalBinary(data))
+	assert.Equal(t, ps.Len(), got.Len())
+	assert.Equal(t, slices.Collect(ps.KeysWithPrefix("")), slices.Collect(got.KeysWithPrefix("")))
+
+	matched, ok := got.LongestPrefix("/API/V1/users")
+	assert.True(t, ok)
+	assert.Equal(t, "/API/V1", matched)
+	matched, ok = got.LongestPrefix("ÜBERALL")
+	assert.True(t, ok)
+	assert.Equal(t, "ÜBER", matched)
+
+	// The loaded trie can still be changed.
+	assert.True(t, got.Insert("/api/v2"))
+	assert.True(t, got.Delete("/static"))
+	assert.False(t, got.Contains("/static"))
+	matched, _ = got.LongestPrefix("/api/v2/users")
+	assert.Equal(t, "/api/v2", matched)
+	assert.True(t, ps.Contains("/static"))
+
+	appended, err := ps.AppendBinary([]byte("header"))
+	assert.NoError(t, err)
+	assert.Equal(t, "header", string(appended[:6]))
+	assert.Equal(t, data, appended[6:])
+}
+
+func TestPrefixConsumer_MarshalBinary_Random(t *testing.T) {
+	for _, size := range []int{0, 1, 10, 500} {
+		keys := make([]string, size)
+		for i := range keys {
+			keys[i] = fmt.Sprintf("%x", i*7919)
+		}
+		ps := NewPrefixConsumer(keys...)
+		data, err := ps.MarshalBinary()
+		assert.NoError(t, err)
+		var got PrefixConsumer
+		assert.NoError(t, got.UnmarshalBinary(data))
+		assert.Equal(t, slices.Collect(ps.KeysWithPrefix("")), slices.Collect(got.KeysWithPrefix("")))
+		for _, k := range keys {
+			matched, ok := got.LongestPrefix(k + "z")
+			assert.True(t, ok)
+			expected, _ := ps.LongestPrefix(k + "z")
+			assert.Equal(t, expected, matched)
+		}
+	}
+}
+
+func TestPrefixConsumer_UnmarshalBinary_Invalid(t *testing.T) {
+	data, err := NewPrefixConsumer("foo", "foobar", "bar").MarshalBinary()
+	assert.NoError(t, err)
+
+	var ps PrefixConsumer
+	for i := range data {
+		assert.ErrorIs(t, ps.UnmarshalBinary(data[:i]), consume.ErrBadEncoding, "truncated to %d bytes", i)
+	}
+	assert.ErrorIs(t, ps.UnmarshalBinary(append(data, 0)), consume.ErrBadEncoding)
+
+	until, err := NewUntilConsumer("/").MarshalBinary()
+	assert.NoError(t, err)
+	assert.ErrorIs(t, ps.UnmarshalBinary(until), consume.ErrBadEncoding)
+
+	// Corrupting any single byte must never panic, and must not produce a trie which breaks its invariants.
+	for i := range data {
+		corrupt := append([]byte(nil), data...)
+		corrupt[i] ^= 0x5a
+		if err := ps.UnmarshalBinary(corrupt); err == nil {
+			keys := slices.Collect(ps.KeysWithPrefix(""))
+			for _, k := range keys {
+				assert.True(t, ps.Contains(k))
+			}
+		}
+	}
+}
+
+func FuzzUnmarshalBinary(f *testing.F) {
+	for _, ps := range []*PrefixConsumer{NewPrefixConsumer(), NewPrefixConsumer("foo", "foobar", "bar", "")} {
+		data, err := ps.MarshalBinary()
+		assert.NoError(f, err)
+		f.Add(data)
+	}
+	until, err := NewUntilConsumer(",", ";").OrRegexp(regexp.MustCompile(`\s+`)).MarshalBinary()
+	assert.NoError(f, err)
+	f.Add(until)
+	// A child of the root with an empty segment.
+	f.Add([]byte("gconp\x01\x81`\x00\x01\x00\x00\x06\x00\x04\x01\x02\x03\x01\v/x/yaababcb\x06\x00\x01\x03\x04\x01\x00\x00\x01\x01\x01\x01\x01\x01\x01\x00\x01\x01\x00"))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// Corrupt input must be reported, not panic, and whatever decodes must be a working trie.
+		var ps PrefixConsumer
+		if err := ps.UnmarshalBinary(data); err != nil {
+			assert.ErrorIs(t, err, consume.ErrBadEncoding)
+		} else {
+			keys := slices.Collect(ps.KeysWithPrefix(""))
+			for _, k := range keys {
+				assert.True(t, ps.Contains(k))
+			}
+			again, err := ps.MarshalBinary()
+			assert.NoError(t, err)
+			var got PrefixConsumer
+			assert.NoError(t, got.UnmarshalBinary(again))
+			assert.Equal(t, keys, slices.Collect(got.KeysWithPrefix("")))
+		}
+		var cu UntilConsumer
+		if err := cu.UnmarshalBinary(data); err != nil {
+			assert.ErrorIs(t, err, consume.ErrBadEncoding)
+		}
+	})
+}
+
+func TestPrefixConsumer_MarshalJSON(t *testing.T) {
+	ps, err := NewPrefixConsumer("foo", "bar").With(consume.MustMatchWholeString(true))
+	assert.NoError(t, err)
+
+	data, err := json.Marshal(ps)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"paths":["bar","foo"],"options":{"mustMatchWholeString":true}}`, string(data))
+
+	var got *PrefixConsumer
+	assert.NoError(t, json.Unmarshal(data, &got))
+	_, _, _, ok := got.Consume("foobar")
+	assert.False(t, ok)
+	_, matched, _, ok := got.Consume("foo")
+	assert.True(t, ok)
+	assert.Equal(t, "foo", matched)
+
+	data, err = json.Marshal(NewPrefixConsumer("foo"))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"paths":["foo"]}`, string(data))
+}
+
+func TestUntilConsumer_MarshalBinary(t *testing.T) {
+	cu, err := NewUntilConsumer(",", ";").With(
+		consume.Inclusive(true),
+		consume.Escape("\\"),
+		consume.Encasing{Start: "(", End: ")"},
+		consume.Encasing{Start: "\"", End: "\""},
+		consume.StartOffset(1),
+		consume.ConsumeRemainingIfNotFound(true),
+	)
+	assert.NoError(t, err)
+
+	data, err := cu.MarshalBinary()
+	assert.NoError(t, err)
+	var got UntilConsumer
+	assert.NoError(t, got.UnmarshalBinary(data))
+
+	for _, input := range []string{",a\\,b(c,d)\"e;f\";g", ";x,y", "nothing"} {
+		m1, s1, r1, ok1 := cu.Consume(input)
+		m2, s2, r2, ok2 := got.Consume(input)
+		assert.Equal(t, []any{m1, s1, r1, ok1}, []any{m2, s2, r2, ok2}, input)
+	}
+
+	for i := range data {
+		assert.ErrorIs(t, got.UnmarshalBinary(data[:i]), consume.ErrBadEncoding, "truncated to %d bytes", i)
+	}
+}
+
+func TestUnmarshal_InvalidConfig(t *testing.T) {
+	// changedByte returns the index of the only byte which differs between a and b.
+	changedByte := func(a, b []byte) int {
+		assert.Equal(t, len(a), len(b))
+		for i := range a {
+			if a[i] != b[i] {
+				return i
+			}
+		}
+		t.Fatalf("encodings do not differ")
+		return 0
+	}
+
+	t.Run("Negative StartOffset", func(t *testing.T) {
+		one, err := NewUntilConsumer("/").With(consume.StartOffset(1))
+		assert.NoError(t, err)
+		two, err := NewUntilConsumer("/").With(consume.StartOffset(2))
+		assert.NoError(t, err)
+		a, err := one.MarshalBinary()
+		assert.NoError(t, err)
+		b, err := two.MarshalBinary()
+		assert.NoError(t, err)
+		a[changedByte(a, b)] = 53 // -27 as a zig-zag varint
+		var got UntilConsumer
+		assert.ErrorIs(t, got.UnmarshalBinary(a), consume.ErrBadEncoding)
+
+		assert.ErrorIs(t, json.Unmarshal([]byte(`{"separators":["/"],"options":{"startOffset":-27}}`), &got), consume.ErrNegativeStartOffset)
+	})
+
+	t.Run("Unknown NoMatch", func(t *testing.T) {
+		skip, err := NewPrefixConsumer("a").With(consume.NoMatchSkip)
+		assert.NoError(t, err)
+		token, err := NewPrefixConsumer("a").With(consume.NoMatchToken)
+		assert.NoError(t, err)
+		a, err := skip.MarshalBinary()
+		assert.NoError(t, err)
+		b, err := token.MarshalBinary()
+		assert.NoError(t, err)
+		a[changedByte(a, b)] = 9
+		var got PrefixConsumer
+		assert.ErrorIs(t, got.UnmarshalBinary(a), consume.ErrBadEncoding)
+
+		assert.ErrorIs(t, json.Unmarshal([]byte(`{"paths":["a"],"options":{"noMatch":9}}`), &got), consume.ErrUnknownNoMatch)
+	})
+
+	t.Run("Corrupt bytes", func(t *testing.T) {
+		// Corrupting any single byte must never give a consumer which panics.
+		cu, err := NewUntilConsumer(",", ";").With(consume.StartOffset(1), consume.Escape("\\"), consume.Encasing{Start: "(", End: ")"})
+		assert.NoError(t, err)
+		data, err := cu.MarshalBinary()
+		assert.NoError(t, err)
+		for i := range data {
+			corrupt := bytes.Clone(data)
+			corrupt[i] ^= 0x5a
+			var got UntilConsumer
+			if got.UnmarshalBinary(corrupt) == nil {
+				assert.NotPanics(t, func() { got.Consume("a,(b;c)\\;d") }, "byte %d", i)
+			}
+		}
+	})
+}
+
+func TestUntilConsumer_MarshalJSON(t *testing.T) {
+	cu, err := NewUntilConsumer("/").With(consume.Escape("\\"), consume.Encasing{Start: "[", End: "]"})
+	assert.NoError(t, err)
+
+	data, err := json.Marshal(cu)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"separators":["/"],"options":{"escapes":["\\"],"encasings":[{"start":"[","end":"]"}]}}`, string(data))
+
+	var got UntilConsumer
+	assert.NoError(t, json.Unmarshal(data, &got))
+	matched, _, remaining, ok := got.Consume(`a\/b[c/d]/e`)
+	assert.True(t, ok)
+	assert.Equal(t, `a\/b[c/d]`, matched)
+	assert.Equal(t, "/e", remaining)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"separators":["/"],"options":{"escapes":[""]}}`), &got))
+}
+
+func TestMarshal_Normalization(t *testing.T) {
+	ps, err := NewPrefixConsumer("\u00e9t\u00e9", "fi").With(consume.NFKC)
+	assert.NoError(t, err)
+
+	data, err := ps.MarshalBinary()
+	assert.NoError(t, err)
+	var got PrefixConsumer
+	assert.NoError(t, got.UnmarshalBinary(data))
+	matched, ok := got.LongestPrefix("e\u0301te\u0301!")
+	assert.True(t, ok)
+	assert.Equal(t, "e\u0301te\u0301", matched)
+
+	cu, err := NewUntilConsumer("fi").With(consume.NFKC)
+	assert.NoError(t, err)
+	data, err = json.Marshal(cu)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"separators":["fi"],"options":{"normalization":3}}`, string(data))
+	var gotUntil UntilConsumer
+	assert.NoError(t, json.Unmarshal(data, &gotUntil))
+	_, separator, _, ok := gotUntil.Consume("a \ufb01le")
+	assert.True(t, ok)
+	assert.Equal(t, "\ufb01", separator)
+
+	assert.ErrorIs(t, json.Unmarshal([]byte(`{"separators":["/"],"options":{"normalization":9}}`), &gotUntil), consume.ErrUnknownNormalization)
+}
+
+func TestMarshal_MustBeFollowedBy(t *testing.T) {
+	followed := consume.MustBeFollowedBy(func(r rune) bool { return r == ' ' })
+
+	ps, err := NewPrefixConsumer("foo").With(followed)
+	assert.NoError(t, err)
+	_, err = ps.MarshalBinary()
+	assert.ErrorIs(t, err, consume.ErrNotEncodable)
+	_, err = json.Marshal(ps)
+	assert.ErrorIs(t, err, consume.ErrNotEncodable)
+}
+
+func TestMarshal_FuncSeparator(t *testing.T) {
+	cu := NewUntilConsumer(",").OrFunc(unicode.IsSpace)
+	_, err := cu.MarshalBinary()
+	assert.ErrorIs(t, err, consume.ErrNotEncodable)
+	_, err = json.Marshal(cu)
+	assert.ErrorIs(t, err, consume.ErrNotEncodable)
+
+	// CollapseRuns is an ordinary option.
+	cu, err = NewUntilConsumer(",").With(consume.CollapseRuns(true))
+	assert.NoError(t, err)
+	data, err := cu.MarshalBinary()
+	assert.NoError(t, err)
+	var got UntilConsumer
+	assert.NoError(t, got.UnmarshalBinary(data))
+	_, separator, _, _ := got.Consume("a,,b")
+	assert.Equal(t, ",,", separator)
+}
+
+func TestUntilConsumer_MarshalBinary_Regexp(t *testing.T) {
+	cu, err := NewUntilConsumer(";").OrRegexp(regexp.MustCompile(`,\s*`)).With(consume.Escape("\\"))
+	assert.NoError(t, err)
+
+	data, err := cu.MarshalBinary()
+	assert.NoError(t, err)
+	var got UntilConsumer
+	assert.NoError(t, got.UnmarshalBinary(data))
+	for _, input := range []string{"a,  b;c", `a\, b;c`, "a;b, c", "nothing"} {
+		m1, s1, r1, ok1 := cu.Consume(input)
+		m2, s2, r2, ok2 := got.Consume(input)
+		assert.Equal(t, []any{m1, s1, r1, ok1}, []any{m2, s2, r2, ok2}, input)
+	}
+	for i := range data {
+		assert.ErrorIs(t, got.UnmarshalBinary(data[:i]), consume.ErrBadEncoding, "truncated to %d bytes", i)
+	}
+
+	data, err = json.Marshal(NewUntilRegexpConsumer(regexp.MustCompile(`\s+`)))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"separators":[],"regexps":["\\s+"]}`, string(data))
+	assert.NoError(t, json.Unmarshal(data, &got))
+	matched, separator, _, ok := got.Consume("a \t b")
+	assert.True(t, ok)
+	assert.Equal(t, "a", matched)
+	assert.Equal(t, " \t ", separator)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"separators":[],"regexps":["("]}`), &got))
+}
+
+func TestMarshal_Bounds(t *testing.T) {
+	cu, err := NewUntilConsumer("and").With(consume.MustBePrecededBy(unicode.IsSpace))
+	assert.NoError(t, err)
+	_, err = cu.MarshalBinary()
+	assert.ErrorIs(t, err, consume.ErrNotEncodable)
+	_, err = json.Marshal(cu)
+	assert.ErrorIs(t, err, consume.ErrNotEncodable)
+
+	cu, err = NewUntilConsumer("and").With(consume.WordBoundary(true), consume.MustBeAtEnd(true))
+	assert.NoError(t, err)
+	for _, marshal := range []func(UntilConsumer) ([]byte, error){UntilConsumer.MarshalBinary, func(cu UntilConsumer) ([]byte, error) { return json.Marshal(cu) }} {
+		data, err := marshal(cu)
+		assert.NoError(t, err)
+		var got UntilConsumer
+		if bytes.HasPrefix(data, []byte("{")) {
+			assert.NoError(t, json.Unmarshal(data, &got))
+		} else {
+			assert.NoError(t, got.UnmarshalBinary(data))
+		}
+		matched, _, _, ok := got.Consume("sand and")
+		assert.True(t, ok)
+		assert.Equal(t, "sand ", matched)
+	}
+}
@@ -2,142 +2,139 @@ package strconsume
 
 import (
 	"bufio"
-	"slices"
-	"unicode/utf8"
+	"iter"
 
 	"github.com/arran4/go-consume"
+	"github.com/arran4/go-consume/internal/engine"
 )
 
-type trieNode struct {
-	segment  string
-	children []*trieNode
-	isEnd    bool
-	fullPath string
-}
-
 type PrefixConsumer struct {
-	root *trieNode
+	trie   *engine.Trie[struct{}]
+	config consume.Config
 }
 
 func NewPrefixConsumer(paths ...string) *PrefixConsumer {
-	if len(paths) == 0 {
-		return &PrefixConsumer{root: &trieNode{}}
+	return &PrefixConsumer{trie: engine.NewTrie[struct{}](paths, nil)}
+}
+
+// With returns a copy of the consumer which applies the given options to every call, before any options
+// passed to the call itself. The copy shares the trie. The options are validated once here rather than on
+// each call.
+func (ps *PrefixConsumer) With(ops ...any) (*PrefixConsumer, error) {
+	c := *ps
+	if err := c.config.Apply(ops...); err != nil {
+		return nil, err
 	}
-	sorted := make([]string, len(paths))
-	copy(sorted, paths)
-	slices.Sort(sorted)
-
-	root := &trieNode{}
-
-	// Build trie from sorted list
-	var insert func(parent *trieNode, start, end int, depth int)
-	insert = func(parent *trieNode, start, end int, depth int) {
-		if start >= end {
-			return
-		}
-
-		i := start
-		// Check if the items end here (are a prefix of others in this group)
-		// Use loop to handle duplicates
-		for i < end && len(sorted[i]) == depth {
-			parent.fullPath = sorted[i]
-			parent.isEnd = true
-			i++
-		}
-
-		for i < end {
-			// Identify group starting with same char
-			char := sorted[i][depth]
-			groupStart := i
-			i++
-			// Scan to find end of group
-			for i < end && len(sorted[i]) > depth && sorted[i][depth] == char {
-				i++
-			}
-
-			// Found a group [groupStart, i)
-			// Find common prefix for this group to compress the trie (Patricia Trie optimization)
-			lcp := 0
-			s1 := sorted[groupStart]
-			s2 := sorted[i-1]
-			minLen := len(s1)
-			if len(s2) < minLen {
-				minLen = len(s2)
-			}
-
-			for k := 0; k < minLen-depth; k++ {
-				if s1[depth+k] == s2[depth+k] {
-					lcp++
-				} else {
-					break
-				}
-			}
-
-			// Create node
-			segment := s1[depth : depth+lcp]
-			node := &trieNode{segment: segment}
-			parent.children = append(parent.children, node)
-
-			// Recurse
-			insert(node, groupStart, i, depth+lcp)
-		}
+	if err := consume.CheckSupported("PrefixConsumer.With", engine.PrefixWithSupports, ops...); err != nil {
+		return nil, err
 	}
+	return &c, nil
+}
+
+// Insert adds a path to the set and reports whether it was not already there. It only copies the nodes along
+// the path, so it is much cheaper than building a new consumer. Insert and Delete may be called concurrently
+// with each other and with any method which reads the set, which sees the set either before or after each
+// change. Copies made by With share the set, copies made by Clone do not. The first Consume after a change
+// rebuilds the automaton it searches with.
+func (ps *PrefixConsumer) Insert(path string) bool {
+	return ps.trie.Insert(path, struct{}{})
+}
 
-	insert(root, 0, len(sorted), 0)
+// Delete removes a path from the set and reports whether it was there. It has the same concurrency rules as
+// Insert.
+func (ps *PrefixConsumer) Delete(path string) bool {
+	return ps.trie.Delete(path)
+}
+
+// Contains reports whether path is in the set.
+func (ps *PrefixConsumer) Contains(path string) bool {
+	return ps.trie.Contains(path)
+}
 
-	return &PrefixConsumer{root: root}
+// Len returns the number of paths in the set.
+func (ps *PrefixConsumer) Len() int {
+	return ps.trie.Len()
+}
+
+// Clone returns a copy of the consumer with its own set of paths, which later changes to either do not
+// affect. It takes constant time, as the copies share nodes until they change them, so it can be used to take
+// a snapshot of a set which is being updated.
+func (ps *PrefixConsumer) Clone() *PrefixConsumer {
+	c := *ps
+	c.trie = ps.trie.Clone()
+	return &c
 }
 
 // LongestPrefix finds the longest string in the set of paths that is a prefix of the input text.
 // It returns the matching prefix and true if found, otherwise empty string and false.
-func (ps *PrefixConsumer) LongestPrefix(text string) (string, bool) {
-	curr := ps.root
-	match := ""
-	hasMatch := false
-
-	// Check match at root (empty string)
-	if curr.isEnd {
-		match = curr.fullPath
-		hasMatch = true
-	}
+// Options:
+// - consume.CaseInsensitive(true): Matches paths using Unicode simple case folding. The returned prefix is the
+// matching text from the input rather than the stored path.
+// - consume.NFC, consume.NFD, consume.NFKC or consume.NFKD: Matches paths under a Unicode normalization form. The
+// match is taken from the input.
+// - consume.Strict(true): Ignored, as LongestPrefix cannot return an error. Use With to have unsupported options
+// reported.
+func (ps *PrefixConsumer) LongestPrefix(text string, ops ...any) (string, bool) {
+	cfg := engine.MustApplyOptions(ps.config, ops)
+	n, found := engine.LongestPrefix(ps.trie, text, engine.MappingOf(&cfg))
+	return text[:n], found
+}
 
-	idx := 0
-	for idx < len(text) {
-		var next *trieNode
-		// Linear scan of children. Since we used CommonPaths logic (sorted), children are sorted by first char.
-		for _, child := range curr.children {
-			if len(child.segment) == 0 {
-				continue // Should not happen with valid LCP logic
-			}
-			if child.segment[0] == text[idx] {
-				segLen := len(child.segment)
-				// Check if full segment matches
-				if idx+segLen <= len(text) && text[idx:idx+segLen] == child.segment {
-					next = child
-					idx += segLen
-					break
-				} else {
-					// Mismatch within segment or text too short
-					return match, hasMatch
-				}
-			}
-		}
-
-		if next == nil {
-			return match, hasMatch
-		}
-		curr = next
-		if curr.isEnd {
-			match = curr.fullPath
-			hasMatch = true
-		}
+// ShortestPrefix finds the shortest string in the set of paths that is a prefix of the input text.
+// It returns the matching prefix and true if found, otherwise empty string and false.
+// Options:
+// - consume.CaseInsensitive(true): Matches paths using Unicode simple case folding. The returned prefix is the
+// matching text from the input rather than the stored path.
+// - consume.NFC, consume.NFD, consume.NFKC or consume.NFKD: Matches paths under a Unicode normalization form. The
+// match is taken from the input.
+// - consume.Strict(true): Ignored, as ShortestPrefix cannot return an error. Use With to have unsupported options
+// reported.
+func (ps *PrefixConsumer) ShortestPrefix(text string, ops ...any) (string, bool) {
+	cfg := engine.MustApplyOptions(ps.config, ops)
+	n, _, _, found := engine.ShortestPrefixKey(ps.trie, text, engine.MappingOf(&cfg))
+	return text[:n], found
+}
+
+// AllPrefixes returns an iterator over every string in the set of paths that is a prefix of the input text,
+// shortest first. The last one yielded is the one LongestPrefix returns.
+// Options:
+// - consume.CaseInsensitive(true): Matches paths using Unicode simple case folding. The yielded prefixes are the
+// matching text from the input rather than the stored paths.
+// - consume.NFC, consume.NFD, consume.NFKC or consume.NFKD: Matches paths under a Unicode normalization form. The
+// match is taken from the input.
+// - consume.Strict(true): Ignored, as AllPrefixes cannot return an error. Use With to have unsupported options
+// reported.
+func (ps *PrefixConsumer) AllPrefixes(text string, ops ...any) iter.Seq[string] {
+	cfg := engine.MustApplyOptions(ps.config, ops)
+	return func(yield func(string) bool) {
+		engine.AllPrefixes(ps.trie, text, engine.MappingOf(&cfg), func(n int, _ string, _ struct{}) bool {
+			return yield(text[:n])
+		})
 	}
+}
 
-	return match, hasMatch
+// KeysWithPrefix returns an iterator over every path in the set that starts with prefix, in sorted order,
+// for uses such as completion. An empty prefix yields every path.
+// Options:
+// - consume.CaseInsensitive(true): Matches paths whose Unicode simple case folding starts with that of prefix.
+// The matching paths are collected before the first is yielded.
+// - consume.NFC, consume.NFD, consume.NFKC or consume.NFKD: Matches paths whose normalized form starts with that
+// of prefix.
+// - consume.Strict(true): Ignored, as KeysWithPrefix cannot return an error. Use With to have unsupported options
+// reported.
+func (ps *PrefixConsumer) KeysWithPrefix(prefix string, ops ...any) iter.Seq[string] {
+	cfg := engine.MustApplyOptions(ps.config, ops)
+	return func(yield func(string) bool) {
+		engine.KeysWithPrefix(ps.trie, prefix, engine.MappingOf(&cfg), func(key string, _ struct{}) bool {
+			return yield(key)
+		})
+	}
 }
 
 // Consume scans the input string 'from' to find the longest matching prefix from the configured set.
-// It searches starting from 'StartOffset' (default 0) and returns the first match found.
+// It searches starting from 'StartOffset' (default 0) and returns the first match found. Matches start on
+// rune boundaries, and the search reads each byte once using an Aho-Corasick automaton of the paths, so it
+// takes time linear in the length of the input rather than in its product with the length of the paths.
 // It returns four values:
 // 1. before: The string before the match (or the match itself if Inclusive is true).
 // 2. match: The matched prefix.
@@ -147,92 +144,69 @@ func (ps *PrefixConsumer) LongestPrefix(text string) (string, bool) {
 // - consume.Inclusive(true): If true, 'before' includes the matched prefix, and 'remaining' starts after it.
 // - consume.StartOffset(n): Starts the search at offset n.
 // - consume.Ignore0PositionMatch(true): Ignores matches at the very start of the search (offset).
+// - consume.MustBePrecededBy(func(rune) bool): The match must come after a rune satisfying the predicate, or at
+// the start of the input.
 // - consume.MustBeFollowedBy(func(rune) bool): The match must be followed by a rune satisfying the predicate.
 // - consume.MustBeAtEnd(true): The match must be at the end of the string.
+// - consume.WordBoundary(true): The match must start and end at word boundaries.
+// - consume.CaseInsensitive(true): Matches prefixes case-insensitively. The returned match is taken from the input.
+// - consume.NFC, consume.NFD, consume.NFKC or consume.NFKD: Matches prefixes under a Unicode normalization form. The
+// match is taken from the input.
+// - consume.MustMatchWholeString(true): The input must be exactly one of the configured prefixes.
+// - consume.Strict(true): Ignored, as Consume cannot return an error. Use ConsumeE or With to have unsupported
+// options reported.
 func (ps *PrefixConsumer) Consume(from string, ops ...any) (string, string, string, bool) {
-	inclusive := false
-	startOffset := 0
-	ignore0PositionMatch := false
-	var mustBeFollowedBy consume.MustBeFollowedBy
-	mustBeAtEnd := false
-	for _, op := range ops {
-		switch v := op.(type) {
-		case consume.Inclusive:
-			inclusive = bool(v)
-		case consume.StartOffset:
-			startOffset = int(v)
-		case consume.Ignore0PositionMatch:
-			ignore0PositionMatch = bool(v)
-		case consume.MustBeFollowedBy:
-			mustBeFollowedBy = v
-		case consume.MustBeAtEnd:
-			mustBeAtEnd = bool(v)
-		}
+	cfg := engine.MustApplyOptions(ps.config, ops)
+	return engine.Prefix(ps.trie, from, &cfg)
+}
+
+// ConsumeE is like Consume, but reports invalid or unsupported options as an error instead of panicking or
+// ignoring them.
+func (ps *PrefixConsumer) ConsumeE(from string, ops ...any) (string, string, string, bool, error) {
+	cfg, err := engine.ApplyOptions(ps.config, "PrefixConsumer.ConsumeE", engine.PrefixConsumeSupports, ops)
+	if err == nil {
+		err = consume.CheckSupported("PrefixConsumer.ConsumeE", engine.PrefixConsumeSupports, ops...)
 	}
-	for i := startOffset; i < len(from); i++ {
-		match, found := ps.LongestPrefix(from[i:])
-		if found {
-			nextIdx := i + len(match)
-			if mustBeAtEnd {
-				if nextIdx != len(from) {
-					continue
-				}
-			}
-
-			if mustBeFollowedBy != nil {
-				if nextIdx < len(from) {
-					r, _ := utf8.DecodeRuneInString(from[nextIdx:])
-					if !mustBeFollowedBy(r) {
-						continue
-					}
-				}
-				// If at end of string, we assume match is valid (boundary reached) unless controlled by another option?
-				// mustBeFollowedBy checks "if followed by X". EOF is valid boundary.
-			}
-
-			if i == 0 && ignore0PositionMatch {
-				continue
-			}
-			matched := from[:i]
-			if inclusive {
-				return matched + match, match, from[i+len(match):], true
-			}
-			return matched, match, from[i:], true
-		}
+	if err != nil {
+		return "", "", from, false, err
 	}
-	return "", "", from, false
+	matched, separator, remaining, found := engine.Prefix(ps.trie, from, &cfg)
+	return matched, separator, remaining, found, nil
 }
 
+// Iterator yields each consecutive prefix found at the start of the input along with the text remaining after it.
+// Iteration stops at the first position where no prefix matches.
+// Options:
+// - consume.CaseInsensitive(true): Matches prefixes case-insensitively. The yielded match is taken from the input.
+// - consume.NFC, consume.NFD, consume.NFKC or consume.NFKD: Matches prefixes under a Unicode normalization form. The
+// match is taken from the input.
+// - consume.Strict(true): Ignored, as Iterator cannot return an error. Use With to have unsupported options
+// reported.
 func (ps *PrefixConsumer) Iterator(from string, ops ...any) func(yield func(string, string) bool) {
-	return func(yield func(string, string) bool) {
-		for {
-			matched, found := ps.LongestPrefix(from)
-			if !found {
-				return
-			}
-			remaining := from[len(matched):]
-			if !yield(matched, remaining) {
-				return
-			}
-			if len(matched) == 0 {
-				return
-			}
-			from = remaining
-		}
-	}
+	cfg := engine.MustApplyOptions(ps.config, ops)
+	return engine.PrefixIterator(ps.trie, from, cfg)
 }
 
+// SplitFunc returns a bufio.SplitFunc which yields each consecutive prefix found at the start of the data.
+// The longest prefix is always taken, so the split function asks for more data while a longer one might still
+// match. An empty prefix is never yielded.
+// Options:
+// - consume.CaseInsensitive(true): Matches prefixes case-insensitively. The token is taken from the input.
+// - consume.NFC, consume.NFD, consume.NFKC or consume.NFKD: Matches prefixes under a Unicode normalization form. The
+// match is taken from the input.
+// - consume.MustBeFollowedBy(func(rune) bool): A prefix must be followed by a rune satisfying the predicate, or
+// the end of the input.
+// - consume.MustBePrecededBy(func(rune) bool) and consume.WordBoundary(true): As for Consume, where the rune
+// before a prefix is the end of the previous token, so the split function must only be used by one scanner.
+// - consume.NoMatchError, consume.NoMatchSkip or consume.NoMatchToken: What to do with input which does not start
+// with a prefix. By default the scan stops with consume.ErrNoMatch. NoMatchSkip discards the input up to the next
+// rune where a prefix may match, and NoMatchToken yields it as a token.
+// - consume.Strict(true): The split function fails with a *consume.UnsupportedOptionError if any other option
+// is not supported.
 func (ps *PrefixConsumer) SplitFunc(ops ...any) bufio.SplitFunc {
-	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
-		if atEOF && len(data) == 0 {
-			return 0, nil, nil
-		}
-		// This is inefficient but functional for now: convert to string
-		text := string(data)
-		matched, found := ps.LongestPrefix(text)
-		if found {
-			return len(matched), []byte(matched), nil
-		}
-		return 0, nil, nil
+	cfg, err := engine.SplitOptions(ps.config, "PrefixConsumer.SplitFunc", engine.PrefixSplitFuncSupports, ops)
+	if err != nil {
+		return engine.FailingSplitFunc(err)
 	}
+	return engine.PrefixSplitFunc(ps.trie, cfg)
 }
@@ -2,7 +2,10 @@ package strconsume
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+
+	"github.com/arran4/go-consume"
 )
 
 // Helper to generate paths
@@ -50,6 +53,100 @@ func BenchmarkPrefixConsumer_LongestPrefix(b *testing.B) {
 	})
 }
 
+// BenchmarkPrefixConsumer_LongestPrefix_WideNodes matches against keys whose nodes have many children each.
+func BenchmarkPrefixConsumer_LongestPrefix_WideNodes(b *testing.B) {
+	chars := "abcdefghijklmnopqrstuvwxyz0123456789"
+	var paths []string
+	for _, c1 := range chars {
+		for _, c2 := range chars {
+			paths = append(paths, string(c1)+string(c2))
+		}
+	}
+	ps := NewPrefixConsumer(paths...)
+	inputs := make([]string, len(paths))
+	for i, p := range paths {
+		inputs[i] = p + "/suffix"
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ps.LongestPrefix(inputs[i%len(inputs)])
+	}
+}
+
+// BenchmarkPrefixConsumer_Consume_LongText searches long texts whose only accepted match is at the end.
+func BenchmarkPrefixConsumer_Consume_LongText(b *testing.B) {
+	paths := generatePaths(10000, 5, 5)
+	long := make([]string, 100)
+	for i := range long {
+		long[i] = strings.Repeat("a", 200) + fmt.Sprint(i)
+	}
+	benchmarks := []struct {
+		name  string
+		ps    *PrefixConsumer
+		text  string
+		match string
+	}{
+		// Text which never starts a key.
+		{name: "Prose", ps: NewPrefixConsumer(paths...), text: strings.Repeat("lorem ipsum dolor sit amet ", 1<<12), match: paths[len(paths)-1]},
+		// Text which starts a key at every other byte.
+		{name: "ShortPartials", ps: NewPrefixConsumer(paths...), text: strings.Repeat("/a/b/c/d/", 1<<12), match: paths[len(paths)-1]},
+		// Text which follows a long way along a key at every byte.
+		{name: "LongPartials", ps: NewPrefixConsumer(long...), text: strings.Repeat("a", 1<<16), match: long[len(long)-1]},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			text := bm.text + " " + bm.match
+			b.SetBytes(int64(len(text)))
+			for i := 0; i < b.N; i++ {
+				if _, _, _, found := bm.ps.Consume(text, consume.MustBeAtEnd(true)); !found {
+					b.Fatal("no match")
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkPrefixConsumer_InsertDelete changes one path in a large set, against rebuilding the set.
+func BenchmarkPrefixConsumer_InsertDelete(b *testing.B) {
+	paths := generatePaths(10000, 5, 5)
+
+	b.Run("InsertDelete", func(b *testing.B) {
+		ps := NewPrefixConsumer(paths...)
+		for i := 0; i < b.N; i++ {
+			ps.Insert("/route/added")
+			ps.Delete("/route/added")
+		}
+	})
+	b.Run("Rebuild", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			NewPrefixConsumer(append(paths[:len(paths):len(paths)], "/route/added")...)
+		}
+	})
+}
+
+// BenchmarkPrefixConsumer_Load compares loading an encoded consumer with building one from its paths.
+func BenchmarkPrefixConsumer_Load(b *testing.B) {
+	paths := generatePaths(100000, 5, 5)
+	data, err := NewPrefixConsumer(paths...).MarshalBinary()
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Run("New", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			NewPrefixConsumer(paths...)
+		}
+	})
+	b.Run("UnmarshalBinary", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			var ps PrefixConsumer
+			if err := ps.UnmarshalBinary(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
 func runPrefixConsumerBenchmark(b *testing.B, fn func(*PrefixConsumer, string)) {
 	inputSizes := []int{10, 100, 1000, 10000}
 
@@ -1,8 +1,18 @@
 package strconsume
 
 import (
-	"github.com/arran4/go-consume"
+	"errors"
+	"fmt"
+	"maps"
+	"math/rand"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/arran4/go-consume"
 )
 
 func TestPrefixConsumer(t *testing.T) {
@@ -237,3 +247,815 @@ func TestPrefixConsumer_Consume_MustBeAtEnd(t *testing.T) {
 		t.Errorf("Consume (MustBeAtEnd) found match not at end")
 	}
 }
+
+func TestPrefixConsumer_Consume_MustBePrecededBy(t *testing.T) {
+	pc := NewPrefixConsumer("sep")
+	slash := func(r rune) bool { return r == '/' }
+
+	before, separator, _, found := pc.Consume("asep/sep", consume.MustBePrecededBy(slash))
+	if !found || before != "asep/" || separator != "sep" {
+		t.Errorf("Consume() = (%q, %q, %v), expected the match after the slash", before, separator, found)
+	}
+
+	// The start of the input needs no rune before it.
+	if _, _, _, found := pc.Consume("sep", consume.MustBePrecededBy(slash)); !found {
+		t.Errorf("Consume() found no match at the start of the input")
+	}
+
+	// StartOffset does not hide the rune before it.
+	if _, _, _, found := pc.Consume("asep", consume.MustBePrecededBy(slash), consume.StartOffset(1)); found {
+		t.Errorf("Consume() found a match after a rune which is not a slash")
+	}
+}
+
+func TestPrefixConsumer_Consume_WordBoundary(t *testing.T) {
+	pc := NewPrefixConsumer("if", "else")
+	tests := []struct {
+		from, before string
+		found        bool
+	}{
+		{"gifted if", "gifted ", true},
+		{"if_x elsewhere else", "if_x elsewhere ", true},
+		{"(if)", "(", true},
+		{"éif if", "éif ", true},
+		{"iffy", "", false},
+	}
+	for _, tt := range tests {
+		before, _, _, found := pc.Consume(tt.from, consume.WordBoundary(true))
+		if before != tt.before || found != tt.found {
+			t.Errorf("Consume(%q) = (%q, %v), expected (%q, %v)", tt.from, before, found, tt.before, tt.found)
+		}
+	}
+}
+
+func TestPrefixConsumer_Consume_RuneBoundaries(t *testing.T) {
+	// "\xa9" is the second byte of "é", so it only occurs in the middle of a rune.
+	pc := NewPrefixConsumer("\xa9", "b")
+	before, separator, _, found := pc.Consume("éb")
+	if !found || before != "é" || separator != "b" {
+		t.Errorf("Consume() = (%q, %q, %v), expected a match of b after é", before, separator, found)
+	}
+
+	pc = NewPrefixConsumer("")
+	before, _, _, found = pc.Consume("éa", consume.Ignore0PositionMatch(true))
+	if !found || before != "é" {
+		t.Errorf("Consume() before = %q, %v, expected the empty prefix to match after é", before, found)
+	}
+}
+
+func firstRune(s string) rune {
+	r, _ := utf8.DecodeRuneInString(s)
+	return r
+}
+
+func lastRune(s string) rune {
+	r, _ := utf8.DecodeLastRuneInString(s)
+	return r
+}
+
+// wordBoundary reports whether there is a word rune on one side of from[i] and not the other.
+func wordBoundary(from string, i int) bool {
+	isWord := func(s string, r rune) bool {
+		return s != "" && (r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r))
+	}
+	return isWord(from[:i], lastRune(from[:i])) != isWord(from[i:], firstRune(from[i:]))
+}
+
+// referenceConsume is PrefixConsumer.Consume as a search for the longest prefix at each rune in turn.
+func referenceConsume(pc *PrefixConsumer, from string, cfg consume.Config) (string, string, string, bool) {
+	for i := cfg.StartOffset; i < len(from); {
+		_, w := utf8.DecodeRuneInString(from[i:])
+		matched, found := pc.LongestPrefix(from[i:], consume.CaseInsensitive(cfg.CaseInsensitive))
+		end := i + len(matched)
+		switch {
+		case !found, i == 0 && cfg.Ignore0PositionMatch, cfg.MustBeAtEnd && end != len(from):
+		case cfg.MustBeFollowedBy != nil && end < len(from) && !cfg.MustBeFollowedBy(firstRune(from[end:])):
+		case cfg.MustBePrecededBy != nil && i > 0 && !cfg.MustBePrecededBy(lastRune(from[:i])):
+		case cfg.WordBoundary && (!wordBoundary(from, i) || !wordBoundary(from, end)):
+		case cfg.Inclusive:
+			return from[:end], from[i:end], from[end:], true
+		default:
+			return from[:i], from[i:end], from[i:], true
+		}
+		i += w
+	}
+	return "", "", from, false
+}
+
+func TestPrefixConsumer_Consume_MatchesReference(t *testing.T) {
+	alphabet := []string{"a", "b", "A", " ", "é", "É", "\u212a", "k"}
+	randString := func(r *rand.Rand, maxLen int) string {
+		var sb strings.Builder
+		for n := r.Intn(maxLen + 1); n > 0; n-- {
+			sb.WriteString(alphabet[r.Intn(len(alphabet))])
+		}
+		return sb.String()
+	}
+	space := func(r rune) bool { return r == ' ' }
+
+	check := func(t *testing.T, r *rand.Rand, pc *PrefixConsumer, text string) {
+		t.Helper()
+		ops := []any{
+			consume.CaseInsensitive(r.Intn(2) == 0),
+			consume.Inclusive(r.Intn(2) == 0),
+			consume.Ignore0PositionMatch(r.Intn(4) == 0),
+			consume.MustBeAtEnd(r.Intn(4) == 0),
+			consume.StartOffset(r.Intn(3)),
+		}
+		if r.Intn(3) == 0 {
+			ops = append(ops, consume.MustBeFollowedBy(space))
+		}
+		if r.Intn(3) == 0 {
+			ops = append(ops, consume.MustBePrecededBy(space))
+		}
+		if r.Intn(3) == 0 {
+			ops = append(ops, consume.WordBoundary(true))
+		}
+		cfg, _ := consume.Compile(ops...)
+		cfg.StartOffset = min(cfg.StartOffset, len(text))
+		ops[4] = consume.StartOffset(cfg.StartOffset)
+		before, separator, remaining, found := pc.Consume(text, ops...)
+		eBefore, eSeparator, eRemaining, eFound := referenceConsume(pc, text, *cfg)
+		if before != eBefore || separator != eSeparator || remaining != eRemaining || found != eFound {
+			t.Fatalf("Consume(%q, %+v) = (%q, %q, %q, %v), expected (%q, %q, %q, %v)", text, *cfg, before, separator, remaining, found, eBefore, eSeparator, eRemaining, eFound)
+		}
+	}
+
+	t.Run("Small sets", func(t *testing.T) {
+		r := rand.New(rand.NewSource(1))
+		for n := 0; n < 3000; n++ {
+			var keys []string
+			for k := r.Intn(5); k > 0; k-- {
+				keys = append(keys, randString(r, 3))
+			}
+			pc := NewPrefixConsumer(keys...)
+			check(t, r, pc, randString(r, 12))
+		}
+	})
+
+	t.Run("Large set", func(t *testing.T) {
+		// Enough keys that the search automaton keeps sparse transitions.
+		r := rand.New(rand.NewSource(2))
+		var keys []string
+		for n := 0; n < 20000; n++ {
+			b := make([]byte, 4+r.Intn(12))
+			for i := range b {
+				b[i] = byte('0' + r.Intn(70))
+			}
+			keys = append(keys, string(b))
+		}
+		pc := NewPrefixConsumer(keys...)
+		for n := 0; n < 500; n++ {
+			key := keys[r.Intn(len(keys))]
+			text := key[r.Intn(len(key)):] + randString(r, 4) + key[:r.Intn(len(key))] + key
+			check(t, r, pc, text)
+		}
+	})
+}
+
+func TestPrefixConsumer_CaseInsensitive(t *testing.T) {
+	tests := []struct {
+		name     string
+		paths    []string
+		input    string
+		expected string
+		found    bool
+	}{
+		{
+			name:     "ASCII",
+			paths:    []string{"foo", "Foobar"},
+			input:    "FOOBARbaz",
+			expected: "FOOBAR",
+			found:    true,
+		},
+		{
+			name:     "Shorter match",
+			paths:    []string{"foo", "foobar"},
+			input:    "FooBaz",
+			expected: "Foo",
+			found:    true,
+		},
+		{
+			name:     "No match",
+			paths:    []string{"foo"},
+			input:    "bar",
+			expected: "",
+			found:    false,
+		},
+		{
+			name:     "Unicode",
+			paths:    []string{"straße", "ελλάδα"},
+			input:    "ΕΛΛΆΔΑ!",
+			expected: "ΕΛΛΆΔΑ",
+			found:    true,
+		},
+		{
+			name:     "Different encoded lengths",
+			paths:    []string{"k"},
+			input:    "Kelvin", // KELVIN SIGN folds to k
+			expected: "K",
+			found:    true,
+		},
+		{
+			name:     "Multiple fold orbit",
+			paths:    []string{"σ"}, // σ, Σ and ς fold together
+			input:    "ς",
+			expected: "ς",
+			found:    true,
+		},
+		{
+			name:     "Empty path match",
+			paths:    []string{""},
+			input:    "abc",
+			expected: "",
+			found:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps := NewPrefixConsumer(tt.paths...)
+			got, found := ps.LongestPrefix(tt.input, consume.CaseInsensitive(true))
+			if found != tt.found {
+				t.Errorf("LongestPrefix() found = %v, expected %v", found, tt.found)
+			}
+			if got != tt.expected {
+				t.Errorf("LongestPrefix() got = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+
+	t.Run("Case sensitive by default", func(t *testing.T) {
+		ps := NewPrefixConsumer("foo")
+		if _, found := ps.LongestPrefix("FOO"); found {
+			t.Errorf("LongestPrefix() matched case-insensitively without the option")
+		}
+	})
+
+	t.Run("Consume", func(t *testing.T) {
+		ps := NewPrefixConsumer("/Sep")
+		matched, separator, remaining, found := ps.Consume("prefix/SEP/suffix", consume.CaseInsensitive(true), consume.Inclusive(true))
+		if !found {
+			t.Fatalf("Consume failed")
+		}
+		if matched != "prefix/SEP" {
+			t.Errorf("Consume matched = %q, expected %q", matched, "prefix/SEP")
+		}
+		if separator != "/SEP" {
+			t.Errorf("Consume separator = %q, expected %q", separator, "/SEP")
+		}
+		if remaining != "/suffix" {
+			t.Errorf("Consume remaining = %q, expected %q", remaining, "/suffix")
+		}
+	})
+
+	t.Run("Iterator", func(t *testing.T) {
+		ps := NewPrefixConsumer("foo", "bar")
+		var got []string
+		for matched := range ps.Iterator("FooBARfoo", consume.CaseInsensitive(true)) {
+			got = append(got, matched)
+		}
+		expected := []string{"Foo", "BAR", "foo"}
+		if !slices.Equal(got, expected) {
+			t.Errorf("Iterator got = %q, expected %q", got, expected)
+		}
+	})
+}
+
+func TestPrefixConsumer_Normalization(t *testing.T) {
+	tests := []struct {
+		name     string
+		paths    []string
+		input    string
+		form     consume.Normalization
+		expected string
+		found    bool
+	}{
+		{
+			name:     "Decomposed input",
+			paths:    []string{"caf\u00e9", "caf"},
+			input:    "cafe\u0301s",
+			form:     consume.NFC,
+			expected: "cafe\u0301",
+			found:    true,
+		},
+		{
+			name:     "Composed input",
+			paths:    []string{"cafe\u0301"},
+			input:    "caf\u00e9s",
+			form:     consume.NFD,
+			expected: "caf\u00e9",
+			found:    true,
+		},
+		{
+			name:     "Shorter path not taken inside a rune and its marks",
+			paths:    []string{"cafe", "caf"},
+			input:    "caf\u00e9",
+			form:     consume.NFC,
+			expected: "caf",
+			found:    true,
+		},
+		{
+			name:     "Hangul syllables and jamo",
+			paths:    []string{"\ud55c\uad6d"},
+			input:    "\u1112\u1161\u11ab\uad6d\uc5b4",
+			form:     consume.NFC,
+			expected: "\u1112\u1161\u11ab\uad6d",
+			found:    true,
+		},
+		{
+			name:     "Compatibility",
+			paths:    []string{"file", "fi"},
+			input:    "\ufb01le",
+			form:     consume.NFKC,
+			expected: "\ufb01le",
+			found:    true,
+		},
+		{
+			name:     "No compatibility under NFC",
+			paths:    []string{"fi"},
+			input:    "\ufb01",
+			form:     consume.NFC,
+			expected: "",
+			found:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ps := NewPrefixConsumer(tt.paths...)
+			got, found := ps.LongestPrefix(tt.input, tt.form)
+			if found != tt.found {
+				t.Errorf("LongestPrefix() found = %v, expected %v", found, tt.found)
+			}
+			if got != tt.expected {
+				t.Errorf("LongestPrefix() got = %q, expected %q", got, tt.expected)
+			}
+		})
+	}
+
+	t.Run("Consume", func(t *testing.T) {
+		ps := NewPrefixConsumer("\u00e9t\u00e9")
+		matched, separator, remaining, found := ps.Consume("l'e\u0301te\u0301!", consume.NFC)
+		if !found || matched != "l'" || separator != "e\u0301te\u0301" || remaining != "e\u0301te\u0301!" {
+			t.Errorf("Consume() = (%q, %q, %q, %v)", matched, separator, remaining, found)
+		}
+	})
+
+	t.Run("Consume with case folding", func(t *testing.T) {
+		ps := NewPrefixConsumer("\u00e9t\u00e9")
+		_, separator, _, found := ps.Consume("l'E\u0301TE\u0301!", consume.NFD, consume.CaseInsensitive(true))
+		if !found || separator != "E\u0301TE\u0301" {
+			t.Errorf("Consume() separator = %q, found = %v", separator, found)
+		}
+	})
+
+	t.Run("KeysWithPrefix", func(t *testing.T) {
+		ps := NewPrefixConsumer("caf\u00e9", "cafe\u0301s", "cafe", "tea")
+		got := slices.Collect(ps.KeysWithPrefix("cafe\u0301", consume.NFC))
+		expected := []string{"cafe\u0301s", "caf\u00e9"}
+		if !slices.Equal(got, expected) {
+			t.Errorf("KeysWithPrefix() got = %q, expected %q", got, expected)
+		}
+	})
+
+	t.Run("Iterator", func(t *testing.T) {
+		ps := NewPrefixConsumer("\u00e9", "a")
+		var got []string
+		for matched := range ps.Iterator("e\u0301a\u00e9", consume.NFC) {
+			got = append(got, matched)
+		}
+		expected := []string{"e\u0301", "a", "\u00e9"}
+		if !slices.Equal(got, expected) {
+			t.Errorf("Iterator got = %q, expected %q", got, expected)
+		}
+	})
+}
+
+func TestPrefixConsumer_With(t *testing.T) {
+	ps, err := NewPrefixConsumer("/sep").With(consume.Inclusive(true), consume.CaseInsensitive(true))
+	if err != nil {
+		t.Fatalf("With() error = %v", err)
+	}
+	matched, separator, remaining, found := ps.Consume("prefix/SEP/suffix")
+	if !found || matched != "prefix/SEP" || separator != "/SEP" || remaining != "/suffix" {
+		t.Errorf("Consume() = (%q, %q, %q, %v)", matched, separator, remaining, found)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		ps.Consume("prefix/SEP/suffix")
+	})
+	if allocs != 0 {
+		t.Errorf("Consume() allocated %v times", allocs)
+	}
+
+	if _, err := NewPrefixConsumer("/sep").With(consume.Escape("")); err == nil {
+		t.Errorf("With() accepted an empty escape")
+	}
+}
+
+func TestPrefixConsumer_UnsupportedOptions(t *testing.T) {
+	ps := NewPrefixConsumer("foo")
+
+	if _, _, _, found := ps.Consume("foo", consume.Escape("\\")); !found {
+		t.Errorf("Consume() did not ignore an unsupported option")
+	}
+
+	_, _, _, _, err := ps.ConsumeE("foo", consume.Escape("\\"))
+	var unsupported *consume.UnsupportedOptionError
+	if !errors.As(err, &unsupported) || unsupported.Consumer != "PrefixConsumer.ConsumeE" {
+		t.Errorf("ConsumeE() error = %v, expected an UnsupportedOptionError", err)
+	}
+
+	if _, _, _, found, err := ps.ConsumeE("xfoo", consume.MustBeAtEnd(true)); err != nil || !found {
+		t.Errorf("ConsumeE() = %v, %v, expected a match", found, err)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Errorf("LongestPrefix() panicked on an unsupported option in strict mode: %v", r)
+			}
+		}()
+		if _, found := ps.LongestPrefix("foo", consume.Strict(true), consume.Inclusive(true)); !found {
+			t.Errorf("LongestPrefix() did not ignore an unsupported option in strict mode")
+		}
+	}()
+
+	if _, err := ps.With(consume.Encasing{Start: "("}); err == nil {
+		t.Errorf("With() accepted an unsupported option")
+	}
+}
+
+func TestPrefixConsumer_Consume_MustMatchWholeString(t *testing.T) {
+	pc := NewPrefixConsumer("get", "getall", "set")
+
+	tests := []struct {
+		name     string
+		input    string
+		ops      []any
+		expected string
+		found    bool
+	}{
+		{name: "Member", input: "getall", expected: "getall", found: true},
+		{name: "Shorter member", input: "get", expected: "get", found: true},
+		{name: "Prefix of member", input: "ge", found: false},
+		{name: "Member is prefix", input: "getter", found: false},
+		{name: "Member inside input", input: "xset", found: false},
+		{name: "Case insensitive", input: "GetAll", ops: []any{consume.CaseInsensitive(true)}, expected: "GetAll", found: true},
+		{name: "Start offset", input: "get", ops: []any{consume.StartOffset(1)}, found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops := append([]any{consume.MustMatchWholeString(true)}, tt.ops...)
+			before, match, remaining, found := pc.Consume(tt.input, ops...)
+			if found != tt.found {
+				t.Fatalf("Consume() found = %v, expected %v", found, tt.found)
+			}
+			if !found {
+				if remaining != tt.input {
+					t.Errorf("Consume() remaining = %q, expected %q", remaining, tt.input)
+				}
+				return
+			}
+			if before != "" || match != tt.expected || remaining != tt.input {
+				t.Errorf("Consume() = (%q, %q, %q), expected (%q, %q, %q)", before, match, remaining, "", tt.expected, tt.input)
+			}
+		})
+	}
+
+	t.Run("Empty key", func(t *testing.T) {
+		if _, _, _, found := NewPrefixConsumer("").Consume("", consume.MustMatchWholeString(true)); !found {
+			t.Errorf("Consume() did not match empty input against an empty key")
+		}
+	})
+}
+
+func TestPrefixConsumer_AllPrefixes(t *testing.T) {
+	pc := NewPrefixConsumer("", "/", "/api", "/api/v1", "/apple")
+	tests := []struct {
+		input    string
+		ops      []any
+		expected []string
+	}{
+		{input: "/api/v1/users", expected: []string{"", "/", "/api", "/api/v1"}},
+		{input: "/api/v2", expected: []string{"", "/", "/api"}},
+		{input: "/ap", expected: []string{"", "/"}},
+		{input: "api", expected: []string{""}},
+		{input: "/API/V1", ops: []any{consume.CaseInsensitive(true)}, expected: []string{"", "/", "/API", "/API/V1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := slices.Collect(pc.AllPrefixes(tt.input, tt.ops...))
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("AllPrefixes() = %q, expected %q", got, tt.expected)
+			}
+			shortest, found := pc.ShortestPrefix(tt.input, tt.ops...)
+			if !found || shortest != tt.expected[0] {
+				t.Errorf("ShortestPrefix() = %q, %v, expected %q", shortest, found, tt.expected[0])
+			}
+			longest, _ := pc.LongestPrefix(tt.input, tt.ops...)
+			if longest != tt.expected[len(tt.expected)-1] {
+				t.Errorf("LongestPrefix() = %q, expected the last of AllPrefixes() %q", longest, tt.expected[len(tt.expected)-1])
+			}
+		})
+	}
+
+	t.Run("Not found", func(t *testing.T) {
+		pc := NewPrefixConsumer("GET", "GETALL")
+		if got := slices.Collect(pc.AllPrefixes("POST")); len(got) != 0 {
+			t.Errorf("AllPrefixes() = %q, expected none", got)
+		}
+		if shortest, found := pc.ShortestPrefix("POST"); found || shortest != "" {
+			t.Errorf("ShortestPrefix() = %q, %v, expected no match", shortest, found)
+		}
+		if shortest, _ := pc.ShortestPrefix("getall", consume.CaseInsensitive(true)); shortest != "get" {
+			t.Errorf("ShortestPrefix() = %q, expected get", shortest)
+		}
+	})
+
+	t.Run("Stops early", func(t *testing.T) {
+		var got []string
+		for p := range pc.AllPrefixes("/api/v1") {
+			got = append(got, p)
+			if p == "/" {
+				break
+			}
+		}
+		if !slices.Equal(got, []string{"", "/"}) {
+			t.Errorf("AllPrefixes() = %q after break, expected [\"\" /]", got)
+		}
+	})
+
+	t.Run("Case folded lengths differ", func(t *testing.T) {
+		// U+212A KELVIN SIGN folds to the one byte "k".
+		pc := NewPrefixConsumer("k", "kk")
+		got := slices.Collect(pc.AllPrefixes("\u212aKx", consume.CaseInsensitive(true)))
+		if !slices.Equal(got, []string{"\u212a", "\u212aK"}) {
+			t.Errorf("AllPrefixes() = %q, expected the matching input text", got)
+		}
+	})
+}
+
+func TestPrefixConsumer_KeysWithPrefix(t *testing.T) {
+	pc := NewPrefixConsumer("/api/v2", "/api", "/apple", "/api/v1", "/static", "", "/ap")
+	tests := []struct {
+		prefix   string
+		ops      []any
+		expected []string
+	}{
+		{prefix: "/api", expected: []string{"/api", "/api/v1", "/api/v2"}},
+		{prefix: "/ap", expected: []string{"/ap", "/api", "/api/v1", "/api/v2", "/apple"}},
+		{prefix: "/app", expected: []string{"/apple"}},
+		{prefix: "/api/", expected: []string{"/api/v1", "/api/v2"}},
+		{prefix: "", expected: []string{"", "/ap", "/api", "/api/v1", "/api/v2", "/apple", "/static"}},
+		{prefix: "/apx", expected: nil},
+		{prefix: "/api/v1/users", expected: nil},
+		{prefix: "/API/V", ops: []any{consume.CaseInsensitive(true)}, expected: []string{"/api/v1", "/api/v2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			got := slices.Collect(pc.KeysWithPrefix(tt.prefix, tt.ops...))
+			if !slices.Equal(got, tt.expected) {
+				t.Errorf("KeysWithPrefix(%q) = %q, expected %q", tt.prefix, got, tt.expected)
+			}
+		})
+	}
+
+	t.Run("Case insensitive keeps every stored key", func(t *testing.T) {
+		pc := NewPrefixConsumer("get", "GET", "GetAll", "post")
+		got := slices.Collect(pc.KeysWithPrefix("gE", consume.CaseInsensitive(true)))
+		if !slices.Equal(got, []string{"GET", "GetAll", "get"}) {
+			t.Errorf("KeysWithPrefix() = %q, expected [GET GetAll get]", got)
+		}
+	})
+
+	t.Run("Stops early", func(t *testing.T) {
+		var got []string
+		for key := range pc.KeysWithPrefix("/api") {
+			got = append(got, key)
+			break
+		}
+		if !slices.Equal(got, []string{"/api"}) {
+			t.Errorf("KeysWithPrefix() = %q after break, expected [/api]", got)
+		}
+	})
+
+	t.Run("Matches filtering every key", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(1))
+		randomKey := func() string {
+			b := make([]byte, rng.Intn(6))
+			for i := range b {
+				b[i] = "abcAB"[rng.Intn(5)]
+			}
+			return string(b)
+		}
+		var keys []string
+		for range 200 {
+			keys = append(keys, randomKey())
+		}
+		pc := NewPrefixConsumer(keys...)
+		for range 200 {
+			pc.Delete(randomKey())
+			pc.Insert(randomKey())
+		}
+		for range 500 {
+			prefix := randomKey()
+			prefix = prefix[:min(len(prefix), rng.Intn(3))]
+			var expected, expectedFold []string
+			for key := range pc.KeysWithPrefix("") {
+				if strings.HasPrefix(key, prefix) {
+					expected = append(expected, key)
+				}
+				if strings.HasPrefix(strings.ToLower(key), strings.ToLower(prefix)) {
+					expectedFold = append(expectedFold, key)
+				}
+			}
+			if got := slices.Collect(pc.KeysWithPrefix(prefix)); !slices.Equal(got, expected) {
+				t.Fatalf("KeysWithPrefix(%q) = %q, expected %q", prefix, got, expected)
+			}
+			if got := slices.Collect(pc.KeysWithPrefix(prefix, consume.CaseInsensitive(true))); !slices.Equal(got, expectedFold) {
+				t.Fatalf("KeysWithPrefix(%q, CaseInsensitive) = %q, expected %q", prefix, got, expectedFold)
+			}
+		}
+	})
+}
+
+func TestPrefixConsumer_InsertDelete(t *testing.T) {
+	pc := NewPrefixConsumer("/api", "/api/v1")
+
+	if !pc.Insert("/app") || pc.Insert("/app") {
+		t.Errorf("Insert() should only report a new path")
+	}
+	if !pc.Contains("/app") || pc.Contains("/ap") || pc.Len() != 3 {
+		t.Errorf("after Insert(), Contains(/app) = %v, Contains(/ap) = %v, Len() = %d", pc.Contains("/app"), pc.Contains("/ap"), pc.Len())
+	}
+	if matched, found := pc.LongestPrefix("/apple"); !found || matched != "/app" {
+		t.Errorf("LongestPrefix() = %q, %v, expected the inserted path", matched, found)
+	}
+
+	if !pc.Delete("/api") || pc.Delete("/api") || pc.Delete("/nope") {
+		t.Errorf("Delete() should only report a path which was there")
+	}
+	if matched, found := pc.LongestPrefix("/api/v1/users"); !found || matched != "/api/v1" {
+		t.Errorf("LongestPrefix() = %q, %v, expected /api/v1", matched, found)
+	}
+	if _, found := pc.LongestPrefix("/api/v2"); found {
+		t.Errorf("LongestPrefix() matched a deleted path")
+	}
+	if pc.Len() != 2 {
+		t.Errorf("Len() = %d, expected 2", pc.Len())
+	}
+
+	t.Run("Case insensitive", func(t *testing.T) {
+		pc := NewPrefixConsumer("Foo")
+		if _, found := pc.LongestPrefix("fooBAR", consume.CaseInsensitive(true)); !found {
+			t.Fatalf("LongestPrefix() did not match")
+		}
+		pc.Insert("foobar")
+		pc.Insert("FOOBAR")
+		if matched, _ := pc.LongestPrefix("fooBARbaz", consume.CaseInsensitive(true)); matched != "fooBAR" {
+			t.Errorf("LongestPrefix() = %q after Insert(), expected fooBAR", matched)
+		}
+		pc.Delete("foobar")
+		if matched, _ := pc.LongestPrefix("fooBARbaz", consume.CaseInsensitive(true)); matched != "fooBAR" {
+			t.Errorf("LongestPrefix() = %q after deleting one of two folded paths, expected fooBAR", matched)
+		}
+		pc.Delete("FOOBAR")
+		if matched, _ := pc.LongestPrefix("fooBARbaz", consume.CaseInsensitive(true)); matched != "foo" {
+			t.Errorf("LongestPrefix() = %q after deleting both folded paths, expected foo", matched)
+		}
+	})
+
+	t.Run("Clone", func(t *testing.T) {
+		pc := NewPrefixConsumer("a", "ab")
+		snapshot := pc.Clone()
+		pc.Insert("abc")
+		snapshot.Delete("a")
+		if !pc.Contains("a") || snapshot.Contains("abc") || pc.Len() != 3 || snapshot.Len() != 1 {
+			t.Errorf("Clone() shares changes: %d paths and %d paths", pc.Len(), snapshot.Len())
+		}
+	})
+}
+
+func TestPrefixConsumer_InsertDelete_MatchesRebuild(t *testing.T) {
+	alphabet := []string{"a", "b", "/", "é", "É"}
+	randString := func(r *rand.Rand, maxLen int) string {
+		var sb strings.Builder
+		for n := r.Intn(maxLen + 1); n > 0; n-- {
+			sb.WriteString(alphabet[r.Intn(len(alphabet))])
+		}
+		return sb.String()
+	}
+
+	r := rand.New(rand.NewSource(1))
+	pc := NewPrefixConsumer()
+	keys := map[string]bool{}
+	for n := 0; n < 3000; n++ {
+		key := randString(r, 5)
+		if r.Intn(3) == 0 {
+			if pc.Delete(key) != keys[key] {
+				t.Fatalf("Delete(%q) disagrees with the reference", key)
+			}
+			delete(keys, key)
+		} else {
+			if pc.Insert(key) == keys[key] {
+				t.Fatalf("Insert(%q) disagrees with the reference", key)
+			}
+			keys[key] = true
+		}
+
+		rebuilt := NewPrefixConsumer(slices.Collect(maps.Keys(keys))...)
+		if pc.Len() != len(keys) {
+			t.Fatalf("Len() = %d, expected %d", pc.Len(), len(keys))
+		}
+		text := randString(r, 8)
+		for _, ci := range []bool{false, true} {
+			matched, found := pc.LongestPrefix(text, consume.CaseInsensitive(ci))
+			eMatched, eFound := rebuilt.LongestPrefix(text, consume.CaseInsensitive(ci))
+			if matched != eMatched || found != eFound {
+				t.Fatalf("LongestPrefix(%q, %v) = %q, %v, expected %q, %v with keys %q", text, ci, matched, found, eMatched, eFound, slices.Sorted(maps.Keys(keys)))
+			}
+		}
+	}
+}
+
+// TestPrefixConsumer_WideNodes grows and shrinks nodes past the number of children which are indexed by first
+// byte rather than scanned, checking lookups against every key.
+func TestPrefixConsumer_WideNodes(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	randString := func(maxLen int) string {
+		b := make([]byte, r.Intn(maxLen+1))
+		for i := range b {
+			b[i] = byte(r.Intn(20)) * 13 // spread over the whole byte range
+		}
+		return string(b)
+	}
+	longest := func(keys map[string]bool, text string) (string, bool) {
+		matched, found := "", false
+		for key := range keys {
+			if strings.HasPrefix(text, key) && (!found || len(key) > len(matched)) {
+				matched, found = key, true
+			}
+		}
+		return matched, found
+	}
+
+	var initial []string
+	for n := 0; n < 30; n++ {
+		initial = append(initial, randString(2))
+	}
+	pc := NewPrefixConsumer(initial...)
+	keys := map[string]bool{}
+	for _, key := range initial {
+		keys[key] = true
+	}
+	for n := 0; n < 2000; n++ {
+		key := randString(3)
+		if r.Intn(2) == 0 {
+			pc.Delete(key)
+			delete(keys, key)
+		} else {
+			pc.Insert(key)
+			keys[key] = true
+		}
+		for m := 0; m < 5; m++ {
+			text := randString(4)
+			matched, found := pc.LongestPrefix(text)
+			eMatched, eFound := longest(keys, text)
+			if matched != eMatched || found != eFound {
+				t.Fatalf("LongestPrefix(%q) = %q, %v, expected %q, %v", text, matched, found, eMatched, eFound)
+			}
+		}
+	}
+}
+
+func TestPrefixConsumer_InsertDelete_ConcurrentReaders(t *testing.T) {
+	pc := NewPrefixConsumer("/static")
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				if matched, found := pc.LongestPrefix("/static/css/site.css", consume.CaseInsensitive(true)); !found || !strings.HasPrefix(matched, "/static") {
+					t.Errorf("LongestPrefix() = %q, %v while paths change", matched, found)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 1000; i++ {
+		pc.Insert(fmt.Sprintf("/static/css/%d", i%10))
+		pc.Delete(fmt.Sprintf("/static/css/%d", (i+5)%10))
+	}
+	close(done)
+	wg.Wait()
+}
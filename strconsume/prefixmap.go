@@ -0,0 +1,125 @@
+package strconsume
+
+import (
+	"iter"
+
+	"github.com/arran4/go-consume"
+	"github.com/arran4/go-consume/internal/engine"
+)
+
+// PrefixMap is a set of keys, each with a value, which finds the key matching the start of a text in a single
+// walk of the same compressed trie as PrefixConsumer. It suits routers and command dispatchers, which would
+// otherwise follow PrefixConsumer.LongestPrefix with a map lookup. The zero value is not usable, create one
+// with NewPrefixMap.
+type PrefixMap[V any] struct {
+	trie *engine.Trie[V]
+}
+
+// NewPrefixMap returns a PrefixMap holding the entries of m, which may be nil.
+func NewPrefixMap[V any](m map[string]V) *PrefixMap[V] {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return &PrefixMap[V]{trie: engine.NewTrie(keys, func(i int) V {
+		return m[keys[i]]
+	})}
+}
+
+// Insert sets the value of key and reports whether key was not already in the map. Insert and Delete may be
+// called concurrently with each other and with any method which reads the map, as for PrefixConsumer.Insert.
+func (pm *PrefixMap[V]) Insert(key string, value V) bool {
+	return pm.trie.Insert(key, value)
+}
+
+// Delete removes key from the map and reports whether it was there.
+func (pm *PrefixMap[V]) Delete(key string) bool {
+	return pm.trie.Delete(key)
+}
+
+// Get returns the value of key and whether key is in the map.
+func (pm *PrefixMap[V]) Get(key string) (V, bool) {
+	return pm.trie.Get(key)
+}
+
+// Len returns the number of keys in the map.
+func (pm *PrefixMap[V]) Len() int {
+	return pm.trie.Len()
+}
+
+// Clone returns a copy of the map which later changes to either do not affect. It takes constant time.
+func (pm *PrefixMap[V]) Clone() *PrefixMap[V] {
+	return &PrefixMap[V]{trie: pm.trie.Clone()}
+}
+
+// LongestPrefix finds the longest key in the map that is a prefix of text. It returns the key, its value and
+// true if found, otherwise an empty key, the zero value and false.
+// Options:
+// - consume.CaseInsensitive(true): Matches keys using Unicode simple case folding. If several keys match, such as
+// "GET" and "get", the first in sorted order is returned.
+// - consume.NFC, consume.NFD, consume.NFKC or consume.NFKD: Matches keys under a Unicode normalization form.
+// - consume.Strict(true): Ignored, as LongestPrefix cannot return an error, so unsupported options
+// are always ignored.
+func (pm *PrefixMap[V]) LongestPrefix(text string, ops ...any) (string, V, bool) {
+	key, value, _, found := pm.longestPrefix(text, ops)
+	return key, value, found
+}
+
+// Consume is like LongestPrefix, but also returns the text after the match. With CaseInsensitive the matching
+// text may not be the same length as the key, so remaining is the way to find where it ends.
+func (pm *PrefixMap[V]) Consume(text string, ops ...any) (string, V, string, bool) {
+	return pm.longestPrefix(text, ops)
+}
+
+// ShortestPrefix finds the shortest key in the map that is a prefix of text. It returns the key, its value
+// and true if found, as LongestPrefix does.
+// Options:
+// - consume.CaseInsensitive(true): Matches keys using Unicode simple case folding.
+// - consume.NFC, consume.NFD, consume.NFKC or consume.NFKD: Matches keys under a Unicode normalization form.
+// - consume.Strict(true): Ignored, as ShortestPrefix cannot return an error, so unsupported options
+// are always ignored.
+func (pm *PrefixMap[V]) ShortestPrefix(text string, ops ...any) (string, V, bool) {
+	cfg := engine.MustApplyOptions(consume.Config{}, ops)
+	_, key, value, found := engine.ShortestPrefixKey(pm.trie, text, engine.MappingOf(&cfg))
+	return key, value, found
+}
+
+// AllPrefixes returns an iterator over every key in the map that is a prefix of text, with its value,
+// shortest first.
+// Options:
+// - consume.CaseInsensitive(true): Matches keys using Unicode simple case folding. If several keys match the same
+// text, such as "GET" and "get", only the first in sorted order is yielded.
+// - consume.NFC, consume.NFD, consume.NFKC or consume.NFKD: Matches keys under a Unicode normalization form.
+// - consume.Strict(true): Ignored, as AllPrefixes cannot return an error, so unsupported options
+// are always ignored.
+func (pm *PrefixMap[V]) AllPrefixes(text string, ops ...any) iter.Seq2[string, V] {
+	cfg := engine.MustApplyOptions(consume.Config{}, ops)
+	return func(yield func(string, V) bool) {
+		engine.AllPrefixes(pm.trie, text, engine.MappingOf(&cfg), func(_ int, key string, value V) bool {
+			return yield(key, value)
+		})
+	}
+}
+
+// KeysWithPrefix returns an iterator over every key in the map that starts with prefix, in sorted order.
+// Options:
+// - consume.CaseInsensitive(true): Matches keys whose Unicode simple case folding starts with that of prefix.
+// The matching keys are collected before the first is yielded.
+// - consume.NFC, consume.NFD, consume.NFKC or consume.NFKD: Matches keys whose normalized form starts with that
+// of prefix.
+// - consume.Strict(true): Ignored, as KeysWithPrefix cannot return an error, so unsupported options
+// are always ignored.
+func (pm *PrefixMap[V]) KeysWithPrefix(prefix string, ops ...any) iter.Seq[string] {
+	cfg := engine.MustApplyOptions(consume.Config{}, ops)
+	return func(yield func(string) bool) {
+		engine.KeysWithPrefix(pm.trie, prefix, engine.MappingOf(&cfg), func(key string, _ V) bool {
+			return yield(key)
+		})
+	}
+}
+
+func (pm *PrefixMap[V]) longestPrefix(text string, ops []any) (string, V, string, bool) {
+	cfg := engine.MustApplyOptions(consume.Config{}, ops)
+	n, key, value, found := engine.LongestPrefixKey(pm.trie, text, engine.MappingOf(&cfg))
+	return key, value, text[n:], found
+}
@@ -0,0 +1,171 @@
+package strconsume
+
+import (
+	"maps"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/arran4/go-consume"
+)
+
+func TestPrefixMap_LongestPrefix(t *testing.T) {
+	pm := NewPrefixMap(map[string]int{"/": 1, "/api": 2, "/api/v1": 3})
+
+	tests := []struct {
+		text      string
+		ops       []any
+		key       string
+		value     int
+		remaining string
+		found     bool
+	}{
+		{text: "/api/v1/users", key: "/api/v1", value: 3, remaining: "/users", found: true},
+		{text: "/api/v2", key: "/api", value: 2, remaining: "/v2", found: true},
+		{text: "/static", key: "/", value: 1, remaining: "static", found: true},
+		{text: "api", remaining: "api", found: false},
+		{text: "/API/V1/users", ops: []any{consume.CaseInsensitive(true)}, key: "/api/v1", value: 3, remaining: "/users", found: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			key, value, found := pm.LongestPrefix(tt.text, tt.ops...)
+			if key != tt.key || value != tt.value || found != tt.found {
+				t.Errorf("LongestPrefix() = (%q, %d, %v), expected (%q, %d, %v)", key, value, found, tt.key, tt.value, tt.found)
+			}
+			_, _, remaining, _ := pm.Consume(tt.text, tt.ops...)
+			if remaining != tt.remaining {
+				t.Errorf("Consume() remaining = %q, expected %q", remaining, tt.remaining)
+			}
+		})
+	}
+
+	t.Run("Case insensitive picks the first key", func(t *testing.T) {
+		pm := NewPrefixMap(map[string]string{"get": "lower", "GET": "upper"})
+		key, value, _ := pm.LongestPrefix("Get x", consume.CaseInsensitive(true))
+		if key != "GET" || value != "upper" {
+			t.Errorf("LongestPrefix() = (%q, %q), expected (GET, upper)", key, value)
+		}
+		pm.Delete("GET")
+		key, value, _ = pm.LongestPrefix("Get x", consume.CaseInsensitive(true))
+		if key != "get" || value != "lower" {
+			t.Errorf("LongestPrefix() = (%q, %q) after Delete(), expected (get, lower)", key, value)
+		}
+	})
+
+	t.Run("Case folded lengths differ", func(t *testing.T) {
+		// U+212A KELVIN SIGN folds to the one byte "k".
+		pm := NewPrefixMap(map[string]bool{"k": true})
+		key, _, remaining, found := pm.Consume("Kx", consume.CaseInsensitive(true))
+		if !found || key != "k" || remaining != "x" {
+			t.Errorf("Consume() = (%q, %q, %v), expected (k, x, true)", key, remaining, found)
+		}
+	})
+}
+
+func TestPrefixMap_AllPrefixes(t *testing.T) {
+	pm := NewPrefixMap(map[string]int{"/": 1, "/api": 2, "/api/v1": 3, "/apple": 4})
+
+	var keys []string
+	var values []int
+	for key, value := range pm.AllPrefixes("/api/v1/users") {
+		keys = append(keys, key)
+		values = append(values, value)
+	}
+	if !slices.Equal(keys, []string{"/", "/api", "/api/v1"}) || !slices.Equal(values, []int{1, 2, 3}) {
+		t.Errorf("AllPrefixes() = %q %v, expected [/ /api /api/v1] [1 2 3]", keys, values)
+	}
+
+	key, value, found := pm.ShortestPrefix("/apple/pie")
+	if !found || key != "/" || value != 1 {
+		t.Errorf("ShortestPrefix() = (%q, %d, %v), expected (/, 1, true)", key, value, found)
+	}
+	if _, _, found := pm.ShortestPrefix("apple"); found {
+		t.Errorf("ShortestPrefix() found a key which is not a prefix")
+	}
+
+	t.Run("Case insensitive", func(t *testing.T) {
+		pm := NewPrefixMap(map[string]string{"get": "lower", "GET": "upper", "GetAll": "all"})
+		var keys []string
+		for key := range pm.AllPrefixes("getALL", consume.CaseInsensitive(true)) {
+			keys = append(keys, key)
+		}
+		if !slices.Equal(keys, []string{"GET", "GetAll"}) {
+			t.Errorf("AllPrefixes() = %q, expected the stored keys [GET GetAll]", keys)
+		}
+		key, value, _ := pm.ShortestPrefix("getALL", consume.CaseInsensitive(true))
+		if key != "GET" || value != "upper" {
+			t.Errorf("ShortestPrefix() = (%q, %q), expected (GET, upper)", key, value)
+		}
+	})
+}
+
+func TestPrefixMap_KeysWithPrefix(t *testing.T) {
+	pm := NewPrefixMap(map[string]int{"/": 1, "/api": 2, "/api/v1": 3, "/apple": 4})
+	pm.Insert("/api/v0", 5)
+	got := slices.Collect(pm.KeysWithPrefix("/api"))
+	if !slices.Equal(got, []string{"/api", "/api/v0", "/api/v1"}) {
+		t.Errorf("KeysWithPrefix() = %q, expected [/api /api/v0 /api/v1]", got)
+	}
+	got = slices.Collect(pm.KeysWithPrefix("/APP", consume.CaseInsensitive(true)))
+	if !slices.Equal(got, []string{"/apple"}) {
+		t.Errorf("KeysWithPrefix() = %q, expected [/apple]", got)
+	}
+}
+
+func TestPrefixMap_InsertDelete(t *testing.T) {
+	pm := NewPrefixMap[string](nil)
+	if !pm.Insert("quit", "a") || pm.Insert("quit", "b") {
+		t.Errorf("Insert() should only report a new key")
+	}
+	if v, ok := pm.Get("quit"); !ok || v != "b" {
+		t.Errorf("Get() = %q, %v, expected the replaced value", v, ok)
+	}
+	snapshot := pm.Clone()
+	if !pm.Delete("quit") || pm.Len() != 0 {
+		t.Errorf("Delete() did not remove the key")
+	}
+	if _, v, ok := snapshot.LongestPrefix("quit now"); !ok || v != "b" || snapshot.Len() != 1 {
+		t.Errorf("Clone() saw a later Delete()")
+	}
+}
+
+func TestPrefixMap_MatchesMap(t *testing.T) {
+	alphabet := []string{"a", "b", "/", "é", "É"}
+	randString := func(r *rand.Rand, maxLen int) string {
+		var sb strings.Builder
+		for n := r.Intn(maxLen + 1); n > 0; n-- {
+			sb.WriteString(alphabet[r.Intn(len(alphabet))])
+		}
+		return sb.String()
+	}
+
+	r := rand.New(rand.NewSource(1))
+	reference := map[string]int{}
+	pm := NewPrefixMap(reference)
+	for n := 0; n < 3000; n++ {
+		key := randString(r, 5)
+		if r.Intn(3) == 0 {
+			pm.Delete(key)
+			delete(reference, key)
+		} else {
+			pm.Insert(key, n)
+			reference[key] = n
+		}
+		if n%100 == 0 {
+			pm = NewPrefixMap(maps.Clone(reference))
+		}
+
+		text := randString(r, 8)
+		eKey, eValue, eFound := "", 0, false
+		for k, v := range reference {
+			if strings.HasPrefix(text, k) && (!eFound || len(k) > len(eKey)) {
+				eKey, eValue, eFound = k, v, true
+			}
+		}
+		key, value, found := pm.LongestPrefix(text)
+		if key != eKey || value != eValue || found != eFound {
+			t.Fatalf("LongestPrefix(%q) = (%q, %d, %v), expected (%q, %d, %v)", text, key, value, found, eKey, eValue, eFound)
+		}
+	}
+}
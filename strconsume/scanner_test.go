@@ -0,0 +1,227 @@
+package strconsume
+
+import (
+	"bufio"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unicode"
+
+	"github.com/arran4/go-consume"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUntilScanner(t *testing.T) {
+	cu := NewUntilConsumer(",", ";")
+	s := cu.Scanner(strings.NewReader(`a,"b,c";d`), consume.Encasing{Start: "\"", End: "\""})
+	var tokens, separators []string
+	for s.Scan() {
+		tokens = append(tokens, s.Text())
+		separators = append(separators, s.Separator())
+	}
+	assert.NoError(t, s.Err())
+	assert.Equal(t, []string{"a", `"b,c"`, "d"}, tokens)
+	assert.Equal(t, []string{",", ";", ""}, separators)
+}
+
+func TestUntilScanner_MatchesSplitFunc(t *testing.T) {
+	alphabet := []string{"a", "b", "A", "/", ":", "\\", "\"", "(", ")", "é", "É", "世"}
+	randString := func(r *rand.Rand, maxLen int) string {
+		var sb strings.Builder
+		for n := r.Intn(maxLen + 1); n > 0; n-- {
+			sb.WriteString(alphabet[r.Intn(len(alphabet))])
+		}
+		return sb.String()
+	}
+	optionSets := [][]any{
+		nil,
+		{consume.Inclusive(true)},
+		{consume.CaseInsensitive(true)},
+		{consume.Ignore0PositionMatch(true)},
+		{consume.StartOffset(2)},
+		{consume.Escape("\\"), consume.Unescape(true)},
+		{consume.Encasing{Start: "\"", End: "\""}, consume.Encasing{Start: "(", End: ")"}, consume.StripEncasing(true), consume.Inclusive(true)},
+		{consume.Encasing{Start: "\"", End: "\""}, consume.Escape("\\"), consume.EscapeBreaksEncasing(true), consume.CaseInsensitive(true)},
+		{consume.CollapseRuns(true)},
+		{consume.CollapseRuns(true), consume.CaseInsensitive(true), consume.Escape("\\")},
+		{consume.WordBoundary(true), consume.Encasing{Start: "(", End: ")"}},
+		{consume.MustBePrecededBy(unicode.IsLetter), consume.MustBeFollowedBy(unicode.IsLetter), consume.CaseInsensitive(true)},
+		{consume.MustBeAtEnd(true), consume.Inclusive(true)},
+		{consume.CollapseRuns(true), consume.NFC, consume.Encasing{Start: "(", End: ")"}},
+		{consume.CollapseRuns(true), consume.WordBoundary(true), consume.MustBePrecededBy(unicode.IsLetter)},
+		{consume.WordBoundary(true), consume.Escape("\\"), consume.NFKD, consume.CaseInsensitive(true)},
+	}
+	readers := []func(io.Reader) io.Reader{
+		func(r io.Reader) io.Reader { return r },
+		iotest.OneByteReader,
+		iotest.HalfReader,
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n < 2000; n++ {
+		var seps []string
+		for k := r.Intn(4); k >= 0; k-- {
+			if sep := randString(r, 3); sep != "" {
+				seps = append(seps, sep)
+			}
+		}
+		input := randString(r, 40)
+		ops := optionSets[r.Intn(len(optionSets))]
+		cu := NewUntilConsumer(seps...)
+		if r.Intn(3) == 0 {
+			cu = cu.OrFunc(unicode.IsUpper)
+		}
+
+		var expected []string
+		bs := bufio.NewScanner(strings.NewReader(input))
+		bs.Split(cu.SplitFunc(ops...))
+		for bs.Scan() {
+			expected = append(expected, bs.Text())
+		}
+
+		var tokens []string
+		s := cu.Scanner(readers[r.Intn(len(readers))](strings.NewReader(input)), ops...)
+		for s.Scan() {
+			tokens = append(tokens, s.Text())
+		}
+		if !assert.NoError(t, s.Err()) || !assert.Equal(t, expected, tokens, "input %q, separators %q, options %#v", input, seps, ops) {
+			return
+		}
+	}
+}
+
+func TestUntilScanner_LongToken(t *testing.T) {
+	// A quoted record much larger than each read, which a split function would rescan on every refill.
+	record := `"` + strings.Repeat(`a\"b,`, 200000) + `"`
+	input := record + ",tail"
+	cu := NewUntilConsumer(",")
+	s := cu.Scanner(iotest.HalfReader(strings.NewReader(input)), consume.Encasing{Start: "\"", End: "\""}, consume.Escape("\\"), consume.EscapeBreaksEncasing(true))
+	s.Buffer(nil, 2*len(input))
+	var tokens []string
+	for s.Scan() {
+		tokens = append(tokens, s.Text())
+	}
+	assert.NoError(t, s.Err())
+	if assert.Len(t, tokens, 2) {
+		assert.True(t, tokens[0] == record, "first token is not the whole record")
+		assert.Equal(t, "tail", tokens[1])
+	}
+}
+
+func TestUntilScanner_CollapseRunsWithBounds(t *testing.T) {
+	// A rejected run of separators must not be found again from inside it, where its end looks like a run.
+	notB := func(r rune) bool { return r != 'b' }
+	tests := []struct {
+		name string
+		ops  []any
+	}{
+		{name: "MustBePrecededBy", ops: []any{consume.CollapseRuns(true), consume.MustBePrecededBy(notB)}},
+		{name: "MustBeFollowedBy", ops: []any{consume.CollapseRuns(true), consume.MustBeFollowedBy(notB)}},
+		{name: "WordBoundary", ops: []any{consume.CollapseRuns(true), consume.WordBoundary(true)}},
+		{name: "MustBeAtEnd", ops: []any{consume.CollapseRuns(true), consume.MustBeAtEnd(true)}},
+	}
+	cu := NewUntilConsumer(" ", "-=")
+	inputs := []string{"b    c", "a    b", "a -=  -= b", "b -=-= c-=", "x  ", "b" + strings.Repeat(" ", 40) + "c d"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, input := range inputs {
+				var expected []string
+				bs := bufio.NewScanner(strings.NewReader(input))
+				bs.Split(cu.SplitFunc(tt.ops...))
+				for bs.Scan() {
+					expected = append(expected, bs.Text())
+				}
+				for _, rd := range []io.Reader{strings.NewReader(input), iotest.OneByteReader(strings.NewReader(input))} {
+					var tokens []string
+					s := cu.Scanner(rd, tt.ops...)
+					for s.Scan() {
+						tokens = append(tokens, s.Text())
+					}
+					assert.NoError(t, s.Err())
+					assert.Equal(t, expected, tokens, "input %q", input)
+				}
+			}
+		})
+	}
+}
+
+func TestUntilScanner_LongToken_Separators(t *testing.T) {
+	// Tokens much larger than each read, which with separators the scanner searches on from near where the
+	// previous search ended.
+	tests := []struct {
+		name     string
+		cu       UntilConsumer
+		ops      []any
+		input    string
+		expected []string
+	}{
+		{
+			name:     "Rune pattern",
+			cu:       NewUntilFuncConsumer(unicode.IsSpace),
+			input:    strings.Repeat("ab", 100000) + " tail",
+			expected: []string{strings.Repeat("ab", 100000), "tail"},
+		},
+		{
+			name:     "Collapsed runs",
+			cu:       NewUntilConsumer(",", ";"),
+			ops:      []any{consume.CollapseRuns(true)},
+			input:    strings.Repeat("a", 100000) + ",;," + strings.Repeat("b", 100000) + ",;",
+			expected: []string{strings.Repeat("a", 100000), strings.Repeat("b", 100000)},
+		},
+		{
+			name:     "Bounds",
+			cu:       NewUntilConsumer("and"),
+			ops:      []any{consume.WordBoundary(true)},
+			input:    strings.Repeat("band ", 50000) + "and tail",
+			expected: []string{strings.Repeat("band ", 50000), " tail"},
+		},
+		{
+			name:     "Encasing",
+			cu:       NewUntilConsumer(",").OrFunc(unicode.IsSpace),
+			ops:      []any{consume.Encasing{Start: "(", End: ")"}, consume.Escape("\\")},
+			input:    strings.Repeat(`(a, b)\,`, 50000) + ",tail",
+			expected: []string{strings.Repeat(`(a, b)\,`, 50000), "tail"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.cu.Scanner(iotest.OneByteReader(strings.NewReader(tt.input)), tt.ops...)
+			s.Buffer(nil, 2*len(tt.input))
+			var tokens []string
+			for s.Scan() {
+				tokens = append(tokens, s.Text())
+			}
+			assert.NoError(t, s.Err())
+			assert.True(t, assert.ObjectsAreEqual(tt.expected, tokens), "tokens differ from %d expected", len(tt.expected))
+		})
+	}
+}
+
+func TestUntilScanner_Errors(t *testing.T) {
+	t.Run("Token too long", func(t *testing.T) {
+		s := NewUntilConsumer(",").Scanner(strings.NewReader(strings.Repeat("a", 100) + ",b"))
+		s.Buffer(make([]byte, 0, 16), 32)
+		assert.False(t, s.Scan())
+		assert.ErrorIs(t, s.Err(), bufio.ErrTooLong)
+	})
+
+	t.Run("Read error", func(t *testing.T) {
+		s := NewUntilConsumer(",").Scanner(iotest.TimeoutReader(iotest.OneByteReader(strings.NewReader("ab,c"))))
+		assert.False(t, s.Scan())
+		assert.ErrorIs(t, s.Err(), iotest.ErrTimeout)
+	})
+
+	t.Run("Empty separator", func(t *testing.T) {
+		s := NewUntilConsumer("").Scanner(strings.NewReader("abc"))
+		assert.False(t, s.Scan())
+		assert.ErrorIs(t, s.Err(), consume.ErrNoProgress)
+	})
+
+	t.Run("Buffer after Scan panics", func(t *testing.T) {
+		s := NewUntilConsumer(",").Scanner(strings.NewReader("a,b"))
+		s.Scan()
+		assert.Panics(t, func() { s.Buffer(nil, 10) })
+	})
+}
@@ -2,8 +2,11 @@ package strconsume
 
 import (
 	"bufio"
+	"io"
 	"strings"
 	"testing"
+	"testing/iotest"
+	"unicode"
 
 	"github.com/arran4/go-consume"
 	"github.com/stretchr/testify/assert"
@@ -26,7 +29,6 @@ func TestPrefixConsumer_SplitFunc(t *testing.T) {
 	})
 
 	t.Run("Case Insensitive", func(t *testing.T) {
-		t.Skip("Case Insensitive not supported in new PrefixConsumer")
 		pc := NewPrefixConsumer("foo")
 		input := "FooFOOfoo"
 		scanner := bufio.NewScanner(strings.NewReader(input))
@@ -40,6 +42,114 @@ func TestPrefixConsumer_SplitFunc(t *testing.T) {
 		assert.Equal(t, []string{"Foo", "FOO", "foo"}, tokens)
 		assert.NoError(t, scanner.Err())
 	})
+
+	scan := func(r io.Reader, split bufio.SplitFunc) ([]string, error) {
+		scanner := bufio.NewScanner(r)
+		scanner.Split(split)
+		var tokens []string
+		for scanner.Scan() {
+			tokens = append(tokens, scanner.Text())
+		}
+		return tokens, scanner.Err()
+	}
+
+	t.Run("Unmatched input is an error by default", func(t *testing.T) {
+		pc := NewPrefixConsumer("foo", "bar")
+		tokens, err := scan(strings.NewReader("foo?bar"), pc.SplitFunc())
+		assert.Equal(t, []string{"foo"}, tokens)
+		assert.ErrorIs(t, err, consume.ErrNoMatch)
+	})
+
+	t.Run("Unmatched input at EOF", func(t *testing.T) {
+		pc := NewPrefixConsumer("foo")
+		tokens, err := scan(strings.NewReader("foofo"), pc.SplitFunc())
+		assert.Equal(t, []string{"foo"}, tokens)
+		assert.ErrorIs(t, err, consume.ErrNoMatch)
+	})
+
+	t.Run("NoMatchSkip", func(t *testing.T) {
+		pc := NewPrefixConsumer("foo", "bar")
+		tokens, err := scan(strings.NewReader("?foo--bar!"), pc.SplitFunc(consume.NoMatchSkip))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"foo", "bar"}, tokens)
+	})
+
+	t.Run("NoMatchToken", func(t *testing.T) {
+		pc := NewPrefixConsumer("foo", "bar")
+		tokens, err := scan(iotest.OneByteReader(strings.NewReader("?foo世界bar!")), pc.SplitFunc(consume.NoMatchToken))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"?", "foo", "世界", "bar", "!"}, tokens)
+	})
+
+	t.Run("Waits for a longer prefix", func(t *testing.T) {
+		pc := NewPrefixConsumer("=", "==", "===")
+		tokens, err := scan(iotest.OneByteReader(strings.NewReader("=====")), pc.SplitFunc())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"===", "=="}, tokens)
+	})
+
+	t.Run("Waits for a longer case folded prefix", func(t *testing.T) {
+		pc := NewPrefixConsumer("straße", "stra")
+		tokens, err := scan(iotest.OneByteReader(strings.NewReader("STRAßEstra")), pc.SplitFunc(consume.CaseInsensitive(true)))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"STRAßE", "stra"}, tokens)
+	})
+
+	t.Run("Waits for the marks after a normalized prefix", func(t *testing.T) {
+		pc := NewPrefixConsumer("\u00e9", "e", "x")
+		tokens, err := scan(iotest.OneByteReader(strings.NewReader("e\u0301xe\u0301\u0323")), pc.SplitFunc(consume.NFC, consume.NoMatchToken))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"e\u0301", "x", "e\u0301\u0323"}, tokens)
+	})
+
+	t.Run("MustBeFollowedBy", func(t *testing.T) {
+		pc := NewPrefixConsumer("if", " ")
+		notLetter := func(r rune) bool { return !unicode.IsLetter(r) }
+		// A space before a letter is turned down too, so it joins the unmatched text, however the input is read.
+		for _, r := range []io.Reader{strings.NewReader("if iffy if"), iotest.OneByteReader(strings.NewReader("if iffy if"))} {
+			tokens, err := scan(r, pc.SplitFunc(consume.MustBeFollowedBy(notLetter), consume.NoMatchToken))
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"if", " iffy ", "if"}, tokens)
+		}
+	})
+
+	t.Run("WordBoundary", func(t *testing.T) {
+		// The rune before each keyword is the end of the token before it, even across reads.
+		pc := NewPrefixConsumer("if", " ", "x")
+		tokens, err := scan(iotest.OneByteReader(strings.NewReader("if xif if")), pc.SplitFunc(consume.WordBoundary(true), consume.NoMatchToken))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"if", " ", "xif", " ", "if"}, tokens)
+	})
+
+	t.Run("MustBePrecededBy", func(t *testing.T) {
+		pc := NewPrefixConsumer("a", "-")
+		dash := func(r rune) bool { return r == '-' }
+		tokens, err := scan(strings.NewReader("a-aa"), pc.SplitFunc(consume.MustBePrecededBy(dash), consume.NoMatchToken))
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "-", "a", "a"}, tokens)
+	})
+
+	t.Run("Empty prefix does not stall", func(t *testing.T) {
+		pc := NewPrefixConsumer("", "a")
+		tokens, err := scan(strings.NewReader("ab"), pc.SplitFunc())
+		assert.Equal(t, []string{"a"}, tokens)
+		assert.ErrorIs(t, err, consume.ErrNoMatch)
+	})
+
+	t.Run("Options from With", func(t *testing.T) {
+		pc, err := NewPrefixConsumer("a").With(consume.NoMatchSkip)
+		assert.NoError(t, err)
+		tokens, err := scan(strings.NewReader("xaxa"), pc.SplitFunc())
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a", "a"}, tokens)
+	})
+
+	t.Run("Strict", func(t *testing.T) {
+		pc := NewPrefixConsumer("a")
+		tokens, err := scan(strings.NewReader("aa"), pc.SplitFunc(consume.Strict(true), consume.Inclusive(true)))
+		assert.Empty(t, tokens)
+		assert.EqualError(t, err, "consume: PrefixConsumer.SplitFunc does not support option consume.Inclusive")
+	})
 }
 
 func TestUntilConsumer_SplitFunc(t *testing.T) {
@@ -2,36 +2,68 @@ package strconsume
 
 import (
 	"bufio"
-	"bytes"
-	"sort"
-	"strings"
-	"unicode/utf8"
+	"regexp"
+	"unicode"
 
 	"github.com/arran4/go-consume"
+	"github.com/arran4/go-consume/internal/engine"
 )
 
 func NewUntilConsumer(s ...string) UntilConsumer {
-	matchers := map[int]map[string]struct{}{}
-	var sizes []int
-	for _, se := range s {
-		me, ok := matchers[len(se)]
-		if !ok || me == nil {
-			me = map[string]struct{}{}
-			sizes = append(sizes, len(se))
-		}
-		me[se] = struct{}{}
-		matchers[len(se)] = me
-	}
-	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
-	return UntilConsumer{
-		matchers: matchers,
-		sizes:    sizes,
-	}
+	return UntilConsumer{separators: engine.NewSeparators(s)}
+}
+
+// NewUntilRegexpConsumer returns an UntilConsumer which splits on the matches of regular expressions. A match
+// can only start where a literal separator could, outside escapes and encasings, and the longest match starting
+// at the first such position wins. Each expression is matched against the input from the position it is
+// searched from, so ^ and \b treat that position as the start of the text. CaseInsensitive does not apply to
+// expressions, which can use the (?i) flag instead. Empty matches are ignored. SplitFunc and Scanner ask for
+// more input while a match reaches the end of what has been read, as it might go on. Use OrRegexp to split on
+// literal separators too.
+func NewUntilRegexpConsumer(patterns ...*regexp.Regexp) UntilConsumer {
+	return UntilConsumer{separators: engine.NewSeparators(nil, engine.RegexpPatterns(patterns)...)}
+}
+
+// OrRegexp returns a copy of the consumer which also splits on the matches of regular expressions, as
+// NewUntilRegexpConsumer describes. At the same position, the longest of the literal and expression matches
+// wins.
+func (cu UntilConsumer) OrRegexp(patterns ...*regexp.Regexp) UntilConsumer {
+	cu.separators = engine.WithPatterns(cu.separators, engine.RegexpPatterns(patterns))
+	return cu
+}
+
+// NewUntilFuncConsumer returns an UntilConsumer which splits on any one rune for which one of fs returns true,
+// such as unicode.IsSpace. A rune can only be a separator where a literal separator could be, outside escapes
+// and encasings. With consume.CollapseRuns, a run of such runes is one separator, as strings.FieldsFunc
+// splits. Bytes which are not valid UTF-8 are passed to fs as utf8.RuneError. A consumer with functions for
+// separators cannot be encoded. Use OrFunc to split on literal separators too.
+func NewUntilFuncConsumer(fs ...func(rune) bool) UntilConsumer {
+	return UntilConsumer{separators: engine.NewSeparators(nil, engine.RunePatterns(fs)...)}
+}
+
+// NewUntilRangeTableConsumer returns an UntilConsumer which splits on any one rune in tables, such as
+// unicode.Punct, as NewUntilFuncConsumer describes.
+func NewUntilRangeTableConsumer(tables ...*unicode.RangeTable) UntilConsumer {
+	return UntilConsumer{separators: engine.NewSeparators(nil, engine.RangeTablePattern(tables))}
+}
+
+// OrFunc returns a copy of the consumer which also splits on any one rune for which one of fs returns true, as
+// NewUntilFuncConsumer describes. At the same position, the longest separator wins.
+func (cu UntilConsumer) OrFunc(fs ...func(rune) bool) UntilConsumer {
+	cu.separators = engine.WithPatterns(cu.separators, engine.RunePatterns(fs))
+	return cu
+}
+
+// OrRangeTable returns a copy of the consumer which also splits on any one rune in tables, as
+// NewUntilFuncConsumer describes.
+func (cu UntilConsumer) OrRangeTable(tables ...*unicode.RangeTable) UntilConsumer {
+	cu.separators = engine.WithPatterns(cu.separators, []engine.Pattern{engine.RangeTablePattern(tables)})
+	return cu
 }
 
 type UntilConsumer struct {
-	matchers map[int]map[string]struct{}
-	sizes    []int
+	separators *engine.Separators
+	config     consume.Config
 }
 
 // Consume scans the input string 'from' for any of the configured separators.
@@ -45,351 +77,69 @@ type UntilConsumer struct {
 // - consume.Inclusive(true): If true, matched includes the separator, and remaining starts after it.
 // - consume.StartOffset(n): Starts the search at offset n.
 // - consume.Ignore0PositionMatch(true): Ignores matches at the start of the string.
+// - consume.MustBePrecededBy(func(rune) bool): The separator must come after a rune satisfying the predicate, or
+// at the start of the string.
+// - consume.MustBeFollowedBy(func(rune) bool): The separator must be followed by a rune satisfying the predicate,
+// or by the end of the string.
+// - consume.MustBeAtEnd(true): The separator must be at the end of the string.
+// - consume.WordBoundary(true): The separator must start and end at word boundaries, so "and" only matches a
+// whole word.
 // - consume.CaseInsensitive(true): Matches separators case-insensitively.
+// - consume.NFC, consume.NFD, consume.NFKC or consume.NFKD: Matches separators under a Unicode normalization form.
 // - consume.ConsumeRemainingIfNotFound(true): If no separator is found, return the whole string as matched, empty separator, and true.
 // - consume.Escape("string"): Specifies an escape string (e.g. "\\"). Can be specified multiple times.
 // - consume.Encasing{Start: "(", End: ")"}: Specifies an encasing pair. Can be specified multiple times.
 // - consume.EscapeBreaksEncasing(true): If true, escape strings work inside encasings.
+// - consume.MustMatchWholeString(true): The separator must span the entire input.
+// - consume.Unescape(true): Removes escape strings from matched, keeping the runes they escape.
+// - consume.StripEncasing(true): Removes the start and end of outermost encasings from matched.
+// - consume.Strict(true): Ignored, as Consume cannot return an error. Use ConsumeE or With to have unsupported
+// options reported.
+// Unescape and StripEncasing only change matched. The raw text it was decoded from is always
+// from[:len(from)-len(remaining)], which also includes the separator when Inclusive is set.
 func (cu UntilConsumer) Consume(from string, ops ...any) (string, string, string, bool) {
-	inclusive := false
-	startOffset := 0
-	ignore0PositionMatch := false
-	caseInsensitive := false
-	consumeRemainingIfNotFound := false
-	var escapes []string
-	var encasings []consume.Encasing
-	escapeBreaksEncasing := false
-	for _, op := range ops {
-		switch v := op.(type) {
-		case consume.Inclusive:
-			inclusive = bool(v)
-		case consume.StartOffset:
-			startOffset = int(v)
-		case consume.Ignore0PositionMatch:
-			ignore0PositionMatch = bool(v)
-		case consume.CaseInsensitive:
-			caseInsensitive = bool(v)
-		case consume.ConsumeRemainingIfNotFound:
-			consumeRemainingIfNotFound = bool(v)
-		case consume.Escape:
-			if len(string(v)) == 0 {
-				panic("consume: escape string cannot be empty")
-			}
-			escapes = append(escapes, string(v))
-		case consume.Encasing:
-			if len(v.Start) == 0 {
-				panic("consume: encasing start cannot be empty")
-			}
-			encasings = append(encasings, v)
-		case consume.EscapeBreaksEncasing:
-			escapeBreaksEncasing = bool(v)
-		}
-	}
-
-	var encasingStack []consume.Encasing
-
-	for i := startOffset; i < len(from); {
-		if len(encasingStack) > 0 {
-			current := encasingStack[len(encasingStack)-1]
-
-			if escapeBreaksEncasing && len(escapes) > 0 {
-				foundEscape := false
-				for _, esc := range escapes {
-					if strings.HasPrefix(from[i:], esc) {
-						i += len(esc)
-						if i < len(from) {
-							_, w := utf8.DecodeRuneInString(from[i:])
-							i += w
-						}
-						foundEscape = true
-						break
-					}
-				}
-				if foundEscape {
-					continue
-				}
-			}
-
-			if strings.HasPrefix(from[i:], current.End) {
-				encasingStack = encasingStack[:len(encasingStack)-1]
-				i += len(current.End)
-				continue
-			}
-
-			if current.Start != current.End {
-				foundStart := false
-				for _, enc := range encasings {
-					if strings.HasPrefix(from[i:], enc.Start) {
-						encasingStack = append(encasingStack, enc)
-						i += len(enc.Start)
-						foundStart = true
-						break
-					}
-				}
-				if foundStart {
-					continue
-				}
-			}
-
-			_, w := utf8.DecodeRuneInString(from[i:])
-			i += w
-			continue
-		}
-
-		if len(escapes) > 0 {
-			foundEscape := false
-			for _, esc := range escapes {
-				if strings.HasPrefix(from[i:], esc) {
-					i += len(esc)
-					if i < len(from) {
-						_, w := utf8.DecodeRuneInString(from[i:])
-						i += w
-					}
-					foundEscape = true
-					break
-				}
-			}
-			if foundEscape {
-				continue
-			}
-		}
-
-		if len(encasings) > 0 {
-			foundStart := false
-			for _, enc := range encasings {
-				if strings.HasPrefix(from[i:], enc.Start) {
-					encasingStack = append(encasingStack, enc)
-					i += len(enc.Start)
-					foundStart = true
-					break
-				}
-			}
-			if foundStart {
-				continue
-			}
-		}
-
-		for _, size := range cu.sizes {
-			if i+size > len(from) {
-				continue
-			}
-			extract := from[i : i+size]
-
-			match := false
-			separator := ""
-
-			if !caseInsensitive {
-				if _, ok := cu.matchers[size][extract]; ok {
-					match = true
-					separator = extract
-				}
-			} else {
-				for s := range cu.matchers[size] {
-					if strings.EqualFold(extract, s) {
-						match = true
-						separator = extract // matched from input
-						break
-					}
-				}
-			}
+	cfg := engine.MustApplyOptions(cu.config, ops)
+	return engine.Until(cu.separators, from, &cfg, engine.NoRune)
+}
 
-			if match {
-				if i == 0 && ignore0PositionMatch {
-					break
-				}
-				matched := from[:i]
-				if inclusive {
-					return matched + separator, separator, from[i+size:], true
-				}
-				return matched, separator, from[i:], true
-			}
-		}
-		_, w := utf8.DecodeRuneInString(from[i:])
-		i += w
+// ConsumeE is like Consume, but reports invalid or unsupported options as an error instead of panicking or
+// ignoring them. When no separator is found because the input ends inside an encasing, it returns a
+// *consume.UnterminatedEncasingError, and when it ends with an escape which has nothing to escape, a
+// *consume.DanglingEscapeError. Either way the results are those of a failed match, even with
+// ConsumeRemainingIfNotFound.
+func (cu UntilConsumer) ConsumeE(from string, ops ...any) (string, string, string, bool, error) {
+	cfg, err := engine.ApplyOptions(cu.config, "UntilConsumer.ConsumeE", engine.UntilConsumeSupports, ops)
+	if err == nil {
+		err = consume.CheckSupported("UntilConsumer.ConsumeE", engine.UntilConsumeSupports, ops...)
 	}
-	if consumeRemainingIfNotFound {
-		return from, "", "", true
+	if err != nil {
+		return "", "", from, false, err
 	}
-	return "", "", from, false
+	return engine.UntilE(cu.separators, from, &cfg, engine.NoRune)
 }
 
-func (cu UntilConsumer) SplitFunc(ops ...any) bufio.SplitFunc {
-	inclusive := false
-	startOffset := 0
-	ignore0PositionMatch := false
-	caseInsensitive := false
-	var escapes []string
-	var encasings []consume.Encasing
-	escapeBreaksEncasing := false
-	for _, op := range ops {
-		switch v := op.(type) {
-		case consume.Inclusive:
-			inclusive = bool(v)
-		case consume.StartOffset:
-			startOffset = int(v)
-		case consume.Ignore0PositionMatch:
-			ignore0PositionMatch = bool(v)
-		case consume.CaseInsensitive:
-			caseInsensitive = bool(v)
-		case consume.Escape:
-			if len(string(v)) == 0 {
-				panic("consume: escape string cannot be empty")
-			}
-			escapes = append(escapes, string(v))
-		case consume.Encasing:
-			if len(v.Start) == 0 {
-				panic("consume: encasing start cannot be empty")
-			}
-			encasings = append(encasings, v)
-		case consume.EscapeBreaksEncasing:
-			escapeBreaksEncasing = bool(v)
-		}
-	}
-	var escapesBytes [][]byte
-	for _, e := range escapes {
-		escapesBytes = append(escapesBytes, []byte(e))
+// With returns a copy of the consumer which applies the given options to every call, before any options
+// passed to the call itself. The options are validated once here rather than on each call, and an
+// option the consumer does not support is reported as a *consume.UnsupportedOptionError.
+func (cu UntilConsumer) With(ops ...any) (UntilConsumer, error) {
+	if err := cu.config.Apply(ops...); err != nil {
+		return UntilConsumer{}, err
 	}
-	type encasingBytes struct {
-		Start, End []byte
+	if err := consume.CheckSupported("UntilConsumer.With", engine.UntilConsumeSupports, ops...); err != nil {
+		return UntilConsumer{}, err
 	}
-	var encasingsBytes []encasingBytes
-	for _, e := range encasings {
-		encasingsBytes = append(encasingsBytes, encasingBytes{[]byte(e.Start), []byte(e.End)})
-	}
-
-	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
-		if atEOF && len(data) == 0 {
-			return 0, nil, nil
-		}
-
-		var encasingStack []encasingBytes
-
-		for i := startOffset; i < len(data); {
-			if len(encasingStack) > 0 {
-				current := encasingStack[len(encasingStack)-1]
-
-				if escapeBreaksEncasing && len(escapesBytes) > 0 {
-					foundEscape := false
-					for _, esc := range escapesBytes {
-						if bytes.HasPrefix(data[i:], esc) {
-							i += len(esc)
-							if i < len(data) {
-								_, w := utf8.DecodeRune(data[i:])
-								i += w
-							}
-							foundEscape = true
-							break
-						}
-					}
-					if foundEscape {
-						continue
-					}
-				}
-
-				if bytes.HasPrefix(data[i:], current.End) {
-					encasingStack = encasingStack[:len(encasingStack)-1]
-					i += len(current.End)
-					continue
-				}
-
-				if !bytes.Equal(current.Start, current.End) {
-					foundStart := false
-					for _, enc := range encasingsBytes {
-						if bytes.HasPrefix(data[i:], enc.Start) {
-							encasingStack = append(encasingStack, enc)
-							i += len(enc.Start)
-							foundStart = true
-							break
-						}
-					}
-					if foundStart {
-						continue
-					}
-				}
-
-				_, w := utf8.DecodeRune(data[i:])
-				i += w
-				continue
-			}
-
-			if len(escapesBytes) > 0 {
-				foundEscape := false
-				for _, esc := range escapesBytes {
-					if bytes.HasPrefix(data[i:], esc) {
-						i += len(esc)
-						if i < len(data) {
-							_, w := utf8.DecodeRune(data[i:])
-							i += w
-						}
-						foundEscape = true
-						break
-					}
-				}
-				if foundEscape {
-					continue
-				}
-			}
-
-			if len(encasingsBytes) > 0 {
-				foundStart := false
-				for _, enc := range encasingsBytes {
-					if bytes.HasPrefix(data[i:], enc.Start) {
-						encasingStack = append(encasingStack, enc)
-						i += len(enc.Start)
-						foundStart = true
-						break
-					}
-				}
-				if foundStart {
-					continue
-				}
-			}
-
-			for _, size := range cu.sizes {
-				if i+size > len(data) {
-					continue
-				}
-				extract := data[i : i+size]
-				extractStr := string(extract)
-
-				match := false
-
-				if !caseInsensitive {
-					if _, ok := cu.matchers[size][extractStr]; ok {
-						match = true
-					}
-				} else {
-					for s := range cu.matchers[size] {
-						if strings.EqualFold(extractStr, s) {
-							match = true
-							break
-						}
-					}
-				}
-
-				if match {
-					if i == 0 && ignore0PositionMatch {
-						break
-					}
-
-					advance = i + size
-					if inclusive {
-						token = data[:i+size]
-					} else {
-						token = data[:i]
-					}
-					return advance, token, nil
-				}
-			}
-			_, w := utf8.DecodeRune(data[i:])
-			i += w
-		}
+	return cu, nil
+}
 
-		if atEOF {
-			return len(data), data, nil
-		}
-		return 0, nil, nil
+// SplitFunc returns a bufio.SplitFunc which yields the text between separators. Each call scans the data from
+// the start of the token, so for tokens which span many reads Scanner is faster. MustBePrecededBy and
+// WordBoundary see the end of the previous token, so a split function must only be used by one scanner.
+func (cu UntilConsumer) SplitFunc(ops ...any) bufio.SplitFunc {
+	cfg, err := engine.SplitOptions(cu.config, "UntilConsumer.SplitFunc", engine.UntilSplitFuncSupports, ops)
+	if err != nil {
+		return engine.FailingSplitFunc(err)
 	}
+	return engine.UntilSplitFunc(cu.separators, cfg)
 }
 
 // Iterator provides a func(yield func(string, string) bool) iterator pattern.
@@ -401,62 +151,10 @@ func (cu UntilConsumer) SplitFunc(ops ...any) bufio.SplitFunc {
 // - consume.StartOffset(n): Starts the first search at offset n. Subsequent searches start from the beginning of the remaining string.
 // - consume.Ignore0PositionMatch(true): Ignores matches at the start of the string (for each iteration step).
 // - consume.CaseInsensitive(true): Matches separators case-insensitively.
+// - consume.NFC, consume.NFD, consume.NFKC or consume.NFKD: Matches separators under a Unicode normalization form.
+// - consume.Strict(true): Ignored, as Iterator cannot return an error. Use With to have unsupported options
+// reported.
 func (cu UntilConsumer) Iterator(from string, ops ...any) func(yield func(string, string) bool) {
-	return func(yield func(string, string) bool) {
-		inclusive := false
-
-		var loopOps []any
-		firstRun := true
-
-		for _, op := range ops {
-			switch v := op.(type) {
-			case consume.Inclusive:
-				inclusive = bool(v)
-				loopOps = append(loopOps, op)
-			case consume.StartOffset:
-				// StartOffset only applies to the first run, so we don't add it to loopOps
-			default:
-				loopOps = append(loopOps, op)
-			}
-		}
-
-		for {
-			var currentOps []any
-			if firstRun {
-				currentOps = ops
-			} else {
-				currentOps = loopOps
-			}
-
-			matched, separator, remaining, found := cu.Consume(from, currentOps...)
-			firstRun = false
-
-			if !found {
-				yield(from, "")
-				return
-			}
-
-			if !yield(matched, separator) {
-				return
-			}
-
-			if inclusive {
-				from = remaining
-			} else {
-				if len(separator) > 0 {
-					from = remaining[len(separator):]
-				} else {
-					if len(matched) > 0 {
-						from = remaining
-					} else {
-						if len(from) > 0 {
-							from = from[1:]
-						} else {
-							return
-						}
-					}
-				}
-			}
-		}
-	}
+	cfg := engine.MustApplyOptions(cu.config, ops)
+	return engine.UntilIterator(cu.separators, from, cfg)
 }
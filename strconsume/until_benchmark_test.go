@@ -0,0 +1,74 @@
+package strconsume
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/arran4/go-consume"
+)
+
+// generateKeywords returns count distinct keywords of varying lengths.
+func generateKeywords(count int) []string {
+	keywords := make([]string, 0, count)
+	for i := 0; len(keywords) < count; i++ {
+		keywords = append(keywords, fmt.Sprintf("kw%s%d", strings.Repeat("x", i%7), i))
+	}
+	return keywords
+}
+
+func BenchmarkUntilConsumer_Consume(b *testing.B) {
+	separatorCounts := []int{1, 10, 100, 1000}
+
+	for _, count := range separatorCounts {
+		cu := NewUntilConsumer(generateKeywords(count)...)
+		miss := strings.Repeat("lorem ipsum dolor sit amet ", 40)
+		hit := miss + "kw0"
+
+		b.Run(fmt.Sprintf("Separators_%d_Hit", count), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				cu.Consume(hit)
+			}
+		})
+		b.Run(fmt.Sprintf("Separators_%d_Miss", count), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				cu.Consume(miss)
+			}
+		})
+		b.Run(fmt.Sprintf("Separators_%d_CaseInsensitive", count), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				cu.Consume(hit, consume.CaseInsensitive(true))
+			}
+		})
+	}
+}
+
+// BenchmarkUntilConsumer_LongRecord reads a single quoted record of the given size in small reads.
+func BenchmarkUntilConsumer_LongRecord(b *testing.B) {
+	cu := NewUntilConsumer("\n")
+	ops := []any{consume.Encasing{Start: "\"", End: "\""}, consume.Escape("\\")}
+
+	for _, size := range []int{1 << 16, 1 << 20} {
+		input := `"` + strings.Repeat("x", size) + "\"\n"
+
+		b.Run(fmt.Sprintf("SplitFunc_%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				s := bufio.NewScanner(iotest.HalfReader(strings.NewReader(input)))
+				s.Buffer(nil, 2*len(input))
+				s.Split(cu.SplitFunc(ops...))
+				for s.Scan() {
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("Scanner_%d", size), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				s := cu.Scanner(iotest.HalfReader(strings.NewReader(input)), ops...)
+				s.Buffer(nil, 2*len(input))
+				for s.Scan() {
+				}
+			}
+		})
+	}
+}
@@ -1,7 +1,16 @@
 package strconsume
 
 import (
+	"bufio"
+	"io"
+	"math/rand"
+	"regexp"
+	"sort"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/arran4/go-consume"
 	"github.com/stretchr/testify/assert"
@@ -308,6 +317,136 @@ func TestUntilConsumer_Consume(t *testing.T) {
 			expectedRemaining: `(")")`,
 			expectedOk:        false,
 		},
+		{
+			name:              "MustMatchWholeString - Whole separator",
+			seps:              []string{"and", "or"},
+			input:             "and",
+			ops:               []any{consume.MustMatchWholeString(true)},
+			expectedMatched:   "",
+			expectedSeparator: "and",
+			expectedRemaining: "and",
+			expectedOk:        true,
+		},
+		{
+			name:              "MustMatchWholeString - Separator within input",
+			seps:              []string{"and", "or"},
+			input:             "this and that",
+			ops:               []any{consume.MustMatchWholeString(true)},
+			expectedMatched:   "",
+			expectedSeparator: "",
+			expectedRemaining: "this and that",
+			expectedOk:        false,
+		},
+		{
+			name:              "MustMatchWholeString - Separator prefix of input",
+			seps:              []string{"and"},
+			input:             "andy",
+			ops:               []any{consume.MustMatchWholeString(true)},
+			expectedMatched:   "",
+			expectedSeparator: "",
+			expectedRemaining: "andy",
+			expectedOk:        false,
+		},
+		{
+			name:              "MustMatchWholeString - Case insensitive inclusive",
+			seps:              []string{"and"},
+			input:             "AND",
+			ops:               []any{consume.MustMatchWholeString(true), consume.CaseInsensitive(true), consume.Inclusive(true)},
+			expectedMatched:   "AND",
+			expectedSeparator: "AND",
+			expectedRemaining: "",
+			expectedOk:        true,
+		},
+		{
+			name:              "MustMatchWholeString - Empty separator and input",
+			seps:              []string{""},
+			input:             "",
+			ops:               []any{consume.MustMatchWholeString(true)},
+			expectedMatched:   "",
+			expectedSeparator: "",
+			expectedRemaining: "",
+			expectedOk:        true,
+		},
+		{
+			name:              "Unescape",
+			seps:              []string{":"},
+			input:             "foo\\:bar\\\\:baz",
+			ops:               []any{consume.Escape("\\"), consume.Unescape(true)},
+			expectedMatched:   "foo:bar\\",
+			expectedSeparator: ":",
+			expectedRemaining: ":baz",
+			expectedOk:        true,
+		},
+		{
+			name:              "Unescape inclusive",
+			seps:              []string{":"},
+			input:             "foo\\:bar:baz",
+			ops:               []any{consume.Escape("\\"), consume.Unescape(true), consume.Inclusive(true)},
+			expectedMatched:   "foo:bar:",
+			expectedSeparator: ":",
+			expectedRemaining: "baz",
+			expectedOk:        true,
+		},
+		{
+			name:              "StripEncasing",
+			seps:              []string{" "},
+			input:             `say"hello world" now`,
+			ops:               []any{consume.Encasing{Start: "\"", End: "\""}, consume.StripEncasing(true)},
+			expectedMatched:   "sayhello world",
+			expectedSeparator: " ",
+			expectedRemaining: " now",
+			expectedOk:        true,
+		},
+		{
+			name:              "StripEncasing keeps nested encasings",
+			seps:              []string{":"},
+			input:             `((a:b)):c`,
+			ops:               []any{consume.Encasing{Start: "(", End: ")"}, consume.StripEncasing(true)},
+			expectedMatched:   "(a:b)",
+			expectedSeparator: ":",
+			expectedRemaining: ":c",
+			expectedOk:        true,
+		},
+		{
+			name:              "Unescape and StripEncasing",
+			seps:              []string{","},
+			input:             `"a \"quoted\" value",next`,
+			ops:               []any{consume.Encasing{Start: "\"", End: "\""}, consume.Escape("\\"), consume.EscapeBreaksEncasing(true), consume.Unescape(true), consume.StripEncasing(true)},
+			expectedMatched:   `a "quoted" value`,
+			expectedSeparator: ",",
+			expectedRemaining: ",next",
+			expectedOk:        true,
+		},
+		{
+			name:              "Escape inside encasing is kept without EscapeBreaksEncasing",
+			seps:              []string{","},
+			input:             `"a\b",c`,
+			ops:               []any{consume.Encasing{Start: "\"", End: "\""}, consume.Escape("\\"), consume.Unescape(true), consume.StripEncasing(true)},
+			expectedMatched:   `a\b`,
+			expectedSeparator: ",",
+			expectedRemaining: ",c",
+			expectedOk:        true,
+		},
+		{
+			name:              "Unescape trailing escape",
+			seps:              []string{":"},
+			input:             "foo\\",
+			ops:               []any{consume.Escape("\\"), consume.Unescape(true), consume.ConsumeRemainingIfNotFound(true)},
+			expectedMatched:   "foo\\",
+			expectedSeparator: "",
+			expectedRemaining: "",
+			expectedOk:        true,
+		},
+		{
+			name:              "Unescape remaining if not found",
+			seps:              []string{":"},
+			input:             "a\\:b",
+			ops:               []any{consume.Escape("\\"), consume.Unescape(true), consume.ConsumeRemainingIfNotFound(true)},
+			expectedMatched:   "a:b",
+			expectedSeparator: "",
+			expectedRemaining: "",
+			expectedOk:        true,
+		},
 		{
 			name:              "Mixed Nesting: Brackets inside quotes",
 			seps:              []string{":"},
@@ -424,3 +563,1388 @@ func TestUntilConsumer_Validation(t *testing.T) {
 		})
 	})
 }
+
+// referenceUntilConsume is the size grouped map lookup UntilConsumer used before it was backed by an
+// automaton. It is kept to check the automaton returns identical results.
+func referenceUntilConsume(seps []string, from string, ops ...any) (string, string, string, bool) {
+	matchers := map[int]map[string]struct{}{}
+	var sizes []int
+	for _, se := range seps {
+		if _, ok := matchers[len(se)]; !ok {
+			matchers[len(se)] = map[string]struct{}{}
+			sizes = append(sizes, len(se))
+		}
+		matchers[len(se)][se] = struct{}{}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+
+	inclusive := false
+	startOffset := 0
+	ignore0PositionMatch := false
+	caseInsensitive := false
+	var escapes []string
+	var encasings []consume.Encasing
+	escapeBreaksEncasing := false
+	for _, op := range ops {
+		switch v := op.(type) {
+		case consume.Inclusive:
+			inclusive = bool(v)
+		case consume.StartOffset:
+			startOffset = int(v)
+		case consume.Ignore0PositionMatch:
+			ignore0PositionMatch = bool(v)
+		case consume.CaseInsensitive:
+			caseInsensitive = bool(v)
+		case consume.Escape:
+			escapes = append(escapes, string(v))
+		case consume.Encasing:
+			encasings = append(encasings, v)
+		case consume.EscapeBreaksEncasing:
+			escapeBreaksEncasing = bool(v)
+		}
+	}
+
+	skipEscape := func(i int) (int, bool) {
+		for _, esc := range escapes {
+			if strings.HasPrefix(from[i:], esc) {
+				i += len(esc)
+				if i < len(from) {
+					_, w := utf8.DecodeRuneInString(from[i:])
+					i += w
+				}
+				return i, true
+			}
+		}
+		return i, false
+	}
+	var encasingStack []consume.Encasing
+	startEncasing := func(i int) (int, bool) {
+		for _, enc := range encasings {
+			if strings.HasPrefix(from[i:], enc.Start) {
+				encasingStack = append(encasingStack, enc)
+				return i + len(enc.Start), true
+			}
+		}
+		return i, false
+	}
+
+	for i := startOffset; i < len(from); {
+		if len(encasingStack) > 0 {
+			current := encasingStack[len(encasingStack)-1]
+			if escapeBreaksEncasing {
+				if next, ok := skipEscape(i); ok {
+					i = next
+					continue
+				}
+			}
+			if strings.HasPrefix(from[i:], current.End) {
+				encasingStack = encasingStack[:len(encasingStack)-1]
+				i += len(current.End)
+				continue
+			}
+			if current.Start != current.End {
+				if next, ok := startEncasing(i); ok {
+					i = next
+					continue
+				}
+			}
+			_, w := utf8.DecodeRuneInString(from[i:])
+			i += w
+			continue
+		}
+		if next, ok := skipEscape(i); ok {
+			i = next
+			continue
+		}
+		if next, ok := startEncasing(i); ok {
+			i = next
+			continue
+		}
+		for _, size := range sizes {
+			if i+size > len(from) {
+				continue
+			}
+			extract := from[i : i+size]
+			match := false
+			if !caseInsensitive {
+				_, match = matchers[size][extract]
+			} else {
+				for s := range matchers[size] {
+					if strings.EqualFold(extract, s) {
+						match = true
+						break
+					}
+				}
+			}
+			if match {
+				if i == 0 && ignore0PositionMatch {
+					break
+				}
+				if inclusive {
+					return from[:i+size], extract, from[i+size:], true
+				}
+				return from[:i], extract, from[i:], true
+			}
+		}
+		_, w := utf8.DecodeRuneInString(from[i:])
+		i += w
+	}
+	return "", "", from, false
+}
+
+func TestUntilConsumer_Consume_MatchesReference(t *testing.T) {
+	alphabet := []string{"a", "b", "A", "/", ":", "\\", "\"", "(", ")", "é", "É", "世"}
+	randString := func(r *rand.Rand, maxLen int) string {
+		var sb strings.Builder
+		for n := r.Intn(maxLen + 1); n > 0; n-- {
+			sb.WriteString(alphabet[r.Intn(len(alphabet))])
+		}
+		return sb.String()
+	}
+	optionSets := [][]any{
+		nil,
+		{consume.Inclusive(true)},
+		{consume.CaseInsensitive(true)},
+		{consume.Ignore0PositionMatch(true)},
+		{consume.StartOffset(2)},
+		{consume.Escape("\\")},
+		{consume.Encasing{Start: "\"", End: "\""}, consume.Encasing{Start: "(", End: ")"}},
+		{consume.Encasing{Start: "\"", End: "\""}, consume.Escape("\\"), consume.EscapeBreaksEncasing(true), consume.CaseInsensitive(true)},
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n < 5000; n++ {
+		var seps []string
+		for k := r.Intn(6); k >= 0; k-- {
+			sep := randString(r, 4)
+			if sep == "" && r.Intn(4) != 0 {
+				continue
+			}
+			seps = append(seps, sep)
+		}
+		input := randString(r, 16)
+		ops := optionSets[r.Intn(len(optionSets))]
+
+		cu := NewUntilConsumer(seps...)
+		matched, sep, remaining, ok := cu.Consume(input, ops...)
+		eMatched, eSep, eRemaining, eOk := referenceUntilConsume(seps, input, ops...)
+		if matched != eMatched || sep != eSep || remaining != eRemaining || ok != eOk {
+			t.Fatalf("Consume(%q, %#v) with separators %q = (%q, %q, %q, %v), expected (%q, %q, %q, %v)",
+				input, ops, seps, matched, sep, remaining, ok, eMatched, eSep, eRemaining, eOk)
+		}
+	}
+}
+
+func TestUntilConsumer_With(t *testing.T) {
+	t.Run("Applies options to every call", func(t *testing.T) {
+		cu, err := NewUntilConsumer(":").With(consume.Escape("\\"), consume.Inclusive(true))
+		assert.NoError(t, err)
+		matched, sep, remaining, ok := cu.Consume("foo\\:bar:baz")
+		assert.True(t, ok)
+		assert.Equal(t, "foo\\:bar:", matched)
+		assert.Equal(t, ":", sep)
+		assert.Equal(t, "baz", remaining)
+	})
+
+	t.Run("Call options override", func(t *testing.T) {
+		cu, err := NewUntilConsumer(":").With(consume.Inclusive(true))
+		assert.NoError(t, err)
+		matched, _, remaining, ok := cu.Consume("foo:bar", consume.Inclusive(false))
+		assert.True(t, ok)
+		assert.Equal(t, "foo", matched)
+		assert.Equal(t, ":bar", remaining)
+	})
+
+	t.Run("Does not change the original", func(t *testing.T) {
+		cu := NewUntilConsumer(":")
+		_, err := cu.With(consume.Inclusive(true))
+		assert.NoError(t, err)
+		matched, _, _, _ := cu.Consume("foo:bar")
+		assert.Equal(t, "foo", matched)
+	})
+
+	t.Run("Compiled config", func(t *testing.T) {
+		cfg, err := consume.Compile(consume.Encasing{Start: "(", End: ")"})
+		assert.NoError(t, err)
+		cu, err := NewUntilConsumer(":").With(cfg)
+		assert.NoError(t, err)
+		var tokens []string
+		for matched := range cu.Iterator("a(b:c):d") {
+			tokens = append(tokens, matched)
+		}
+		assert.Equal(t, []string{"a(b:c)", "d"}, tokens)
+	})
+
+	t.Run("Invalid options", func(t *testing.T) {
+		_, err := NewUntilConsumer(":").With(consume.Escape(""))
+		assert.ErrorIs(t, err, consume.ErrEmptyEscape)
+		_, err = NewUntilConsumer(":").With(consume.Encasing{End: "x"})
+		assert.ErrorIs(t, err, consume.ErrEmptyEncasingStart)
+	})
+
+	t.Run("Does not allocate", func(t *testing.T) {
+		cu, err := NewUntilConsumer(":", ";").With(consume.Escape("\\"), consume.Encasing{Start: "\"", End: "\""}, consume.CaseInsensitive(true))
+		assert.NoError(t, err)
+		allocs := testing.AllocsPerRun(100, func() {
+			cu.Consume(`foo\:"bar;baz";qux`)
+		})
+		assert.Equal(t, 0.0, allocs)
+	})
+}
+
+func TestUntilConsumer_UnsupportedOptions(t *testing.T) {
+	cu := NewUntilConsumer(":")
+
+	t.Run("Ignored by default", func(t *testing.T) {
+		_, _, _, ok := cu.Consume("foo:bar", consume.NoMatchSkip)
+		assert.True(t, ok)
+	})
+
+	t.Run("Strict is ignored by Consume", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			_, _, _, ok := cu.Consume("foo:bar", consume.Strict(true), consume.NoMatchSkip)
+			assert.True(t, ok)
+		})
+	})
+
+	t.Run("Strict accepts supported options", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			cu.Consume("foo:bar", consume.Strict(true), consume.Inclusive(true), consume.Escape("\\"))
+		})
+	})
+
+	t.Run("ConsumeE", func(t *testing.T) {
+		_, _, remaining, ok, err := cu.ConsumeE("foo:bar", consume.NoMatchSkip)
+		var unsupported *consume.UnsupportedOptionError
+		assert.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, consume.NoMatchSkip, unsupported.Option)
+		assert.False(t, ok)
+		assert.Equal(t, "foo:bar", remaining)
+
+		_, _, _, _, err = cu.ConsumeE("foo:bar", consume.Escape(""))
+		assert.ErrorIs(t, err, consume.ErrEmptyEscape)
+
+		matched, sep, remaining, ok, err := cu.ConsumeE("foo:bar", consume.Inclusive(true))
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "foo:", matched)
+		assert.Equal(t, ":", sep)
+		assert.Equal(t, "bar", remaining)
+	})
+
+	t.Run("SplitFunc", func(t *testing.T) {
+		split := cu.SplitFunc(consume.Strict(true), consume.ConsumeRemainingIfNotFound(true))
+		_, _, err := split([]byte("foo:bar"), true)
+		var unsupported *consume.UnsupportedOptionError
+		assert.ErrorAs(t, err, &unsupported)
+		assert.Equal(t, "UntilConsumer.SplitFunc", unsupported.Consumer)
+	})
+
+	t.Run("Scanner", func(t *testing.T) {
+		s := cu.Scanner(strings.NewReader("foo:bar"), consume.Strict(true), consume.ConsumeRemainingIfNotFound(true))
+		assert.False(t, s.Scan())
+		var unsupported *consume.UnsupportedOptionError
+		assert.ErrorAs(t, s.Err(), &unsupported)
+		assert.Equal(t, "UntilConsumer.Scanner", unsupported.Consumer)
+	})
+
+	t.Run("With", func(t *testing.T) {
+		_, err := cu.With(consume.NoMatchToken)
+		assert.Error(t, err)
+
+		cfg, err := consume.Compile(consume.NoMatchSkip)
+		assert.NoError(t, err)
+		_, err = cu.With(cfg)
+		assert.Error(t, err)
+	})
+}
+
+func TestUntilConsumer_Unescape(t *testing.T) {
+	cu, err := NewUntilConsumer(" ").With(
+		consume.Encasing{Start: "'", End: "'"},
+		consume.Escape("\\"),
+		consume.Unescape(true),
+		consume.StripEncasing(true),
+	)
+	assert.NoError(t, err)
+	input := `cp 'my file.txt' other\ file.txt`
+
+	t.Run("Iterator", func(t *testing.T) {
+		var words []string
+		for word := range cu.Iterator(input) {
+			words = append(words, word)
+		}
+		assert.Equal(t, []string{"cp", "my file.txt", "other file.txt"}, words)
+	})
+
+	t.Run("SplitFunc", func(t *testing.T) {
+		scanner := bufio.NewScanner(strings.NewReader(input))
+		scanner.Split(cu.SplitFunc())
+		var words []string
+		for scanner.Scan() {
+			words = append(words, scanner.Text())
+		}
+		assert.NoError(t, scanner.Err())
+		assert.Equal(t, []string{"cp", "my file.txt", "other file.txt"}, words)
+	})
+
+	t.Run("Raw token", func(t *testing.T) {
+		from := input[3:]
+		matched, _, remaining, ok := cu.Consume(from)
+		assert.True(t, ok)
+		assert.Equal(t, "my file.txt", matched)
+		assert.Equal(t, "'my file.txt'", from[:len(from)-len(remaining)])
+	})
+
+	t.Run("Unchanged token is not copied", func(t *testing.T) {
+		allocs := testing.AllocsPerRun(100, func() {
+			cu.Consume("plain words")
+		})
+		assert.Equal(t, 0.0, allocs)
+	})
+}
+
+func TestUntilConsumer_ConsumeE_Unterminated(t *testing.T) {
+	quotes := consume.Encasing{Start: "\"", End: "\""}
+	parens := consume.Encasing{Start: "(", End: ")"}
+
+	t.Run("Unterminated encasing", func(t *testing.T) {
+		cu := NewUntilConsumer(":")
+		_, _, remaining, ok, err := cu.ConsumeE(`key:"value`, quotes, consume.StartOffset(4))
+		var unterminated *consume.UnterminatedEncasingError
+		assert.ErrorAs(t, err, &unterminated)
+		assert.Equal(t, quotes, unterminated.Encasing)
+		assert.Equal(t, 4, unterminated.Offset)
+		assert.EqualError(t, err, `consume: unterminated encasing "\"" starting at offset 4`)
+		assert.False(t, ok)
+		assert.Equal(t, `key:"value`, remaining)
+	})
+
+	t.Run("Innermost encasing is reported", func(t *testing.T) {
+		cu := NewUntilConsumer(":")
+		_, _, _, _, err := cu.ConsumeE(`a(b"c)`, quotes, parens)
+		var unterminated *consume.UnterminatedEncasingError
+		assert.ErrorAs(t, err, &unterminated)
+		assert.Equal(t, quotes, unterminated.Encasing)
+		assert.Equal(t, 3, unterminated.Offset)
+	})
+
+	t.Run("Dangling escape", func(t *testing.T) {
+		cu := NewUntilConsumer(":")
+		_, _, _, ok, err := cu.ConsumeE(`foo\`, consume.Escape("\\"))
+		var dangling *consume.DanglingEscapeError
+		assert.ErrorAs(t, err, &dangling)
+		assert.Equal(t, "\\", dangling.Escape)
+		assert.Equal(t, 3, dangling.Offset)
+		assert.False(t, ok)
+	})
+
+	t.Run("Error even with ConsumeRemainingIfNotFound", func(t *testing.T) {
+		cu := NewUntilConsumer(":")
+		_, _, _, ok, err := cu.ConsumeE(`"foo`, quotes, consume.ConsumeRemainingIfNotFound(true))
+		assert.Error(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("No separator", func(t *testing.T) {
+		cu := NewUntilConsumer(":")
+		_, _, remaining, ok, err := cu.ConsumeE(`"foo:bar"`, quotes)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, `"foo:bar"`, remaining)
+
+		matched, _, _, ok, err := cu.ConsumeE(`"foo:bar"`, quotes, consume.ConsumeRemainingIfNotFound(true), consume.StripEncasing(true))
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "foo:bar", matched)
+	})
+
+	t.Run("Separator before unterminated encasing", func(t *testing.T) {
+		cu := NewUntilConsumer(":")
+		matched, _, _, ok, err := cu.ConsumeE(`foo:"bar`, quotes)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "foo", matched)
+	})
+}
+
+func TestUntilRegexpConsumer_Consume(t *testing.T) {
+	tests := []struct {
+		name              string
+		cu                UntilConsumer
+		input             string
+		ops               []any
+		expectedMatched   string
+		expectedSeparator string
+		expectedRemaining string
+		expectedOk        bool
+	}{
+		{
+			name:              "Whitespace run",
+			cu:                NewUntilRegexpConsumer(regexp.MustCompile(`\s+`)),
+			input:             "foo \t bar",
+			expectedMatched:   "foo",
+			expectedSeparator: " \t ",
+			expectedRemaining: " \t bar",
+			expectedOk:        true,
+		},
+		{
+			name:              "Comma with optional space, inclusive",
+			cu:                NewUntilRegexpConsumer(regexp.MustCompile(`,\s*`)),
+			input:             "a,  b",
+			ops:               []any{consume.Inclusive(true)},
+			expectedMatched:   "a,  ",
+			expectedSeparator: ",  ",
+			expectedRemaining: "b",
+			expectedOk:        true,
+		},
+		{
+			name:              "Optional carriage return",
+			cu:                NewUntilRegexpConsumer(regexp.MustCompile(`\r?\n`)),
+			input:             "line\r\nnext",
+			expectedMatched:   "line",
+			expectedSeparator: "\r\n",
+			expectedRemaining: "\r\nnext",
+			expectedOk:        true,
+		},
+		{
+			name:              "Escaped match is skipped",
+			cu:                NewUntilRegexpConsumer(regexp.MustCompile(`,\s*`)),
+			input:             `a\, b, c`,
+			ops:               []any{consume.Escape(`\`)},
+			expectedMatched:   `a\, b`,
+			expectedSeparator: ", ",
+			expectedRemaining: ", c",
+			expectedOk:        true,
+		},
+		{
+			name:              "Encased match is skipped",
+			cu:                NewUntilRegexpConsumer(regexp.MustCompile(`\s+`)),
+			input:             `"my file" other`,
+			ops:               []any{consume.Encasing{Start: `"`, End: `"`}, consume.StripEncasing(true)},
+			expectedMatched:   "my file",
+			expectedSeparator: " ",
+			expectedRemaining: " other",
+			expectedOk:        true,
+		},
+		{
+			name:              "Match starting inside an encasing is skipped even if it ends outside",
+			cu:                NewUntilRegexpConsumer(regexp.MustCompile(`b\)c`)),
+			input:             "(ab)cb)c",
+			ops:               []any{consume.Encasing{Start: "(", End: ")"}},
+			expectedMatched:   "(ab)c",
+			expectedSeparator: "b)c",
+			expectedRemaining: "b)c",
+			expectedOk:        true,
+		},
+		{
+			name:              "Ignore0PositionMatch",
+			cu:                NewUntilRegexpConsumer(regexp.MustCompile(`\s+`)),
+			input:             " a b",
+			ops:               []any{consume.Ignore0PositionMatch(true)},
+			expectedMatched:   " a",
+			expectedSeparator: " ",
+			expectedRemaining: " b",
+			expectedOk:        true,
+		},
+		{
+			name:              "StartOffset",
+			cu:                NewUntilRegexpConsumer(regexp.MustCompile(`[0-9]+`)),
+			input:             "12ab34",
+			ops:               []any{consume.StartOffset(1)},
+			expectedMatched:   "1",
+			expectedSeparator: "2",
+			expectedRemaining: "2ab34",
+			expectedOk:        true,
+		},
+		{
+			name:              "Longest of literal and expression wins",
+			cu:                NewUntilConsumer("-").OrRegexp(regexp.MustCompile(`-+>`)),
+			input:             "a-->b",
+			expectedMatched:   "a",
+			expectedSeparator: "-->",
+			expectedRemaining: "-->b",
+			expectedOk:        true,
+		},
+		{
+			name:              "Earliest of literal and expression wins",
+			cu:                NewUntilConsumer(";").OrRegexp(regexp.MustCompile(`,\s*`)),
+			input:             "a, b; c",
+			expectedMatched:   "a",
+			expectedSeparator: ", ",
+			expectedRemaining: ", b; c",
+			expectedOk:        true,
+		},
+		{
+			name:              "Case insensitive expression",
+			cu:                NewUntilRegexpConsumer(regexp.MustCompile(`(?i)\s+and\s+`)),
+			input:             "salt AND pepper",
+			expectedMatched:   "salt",
+			expectedSeparator: " AND ",
+			expectedRemaining: " AND pepper",
+			expectedOk:        true,
+		},
+		{
+			name:              "MustMatchWholeString",
+			cu:                NewUntilRegexpConsumer(regexp.MustCompile(`\s+`)),
+			input:             "  ",
+			ops:               []any{consume.MustMatchWholeString(true)},
+			expectedMatched:   "",
+			expectedSeparator: "  ",
+			expectedRemaining: "  ",
+			expectedOk:        true,
+		},
+		{
+			name:              "Empty matches are ignored",
+			cu:                NewUntilRegexpConsumer(regexp.MustCompile(`x*`)),
+			input:             "abxxc",
+			expectedMatched:   "ab",
+			expectedSeparator: "xx",
+			expectedRemaining: "xxc",
+			expectedOk:        true,
+		},
+		{
+			name:              "No match",
+			cu:                NewUntilRegexpConsumer(regexp.MustCompile(`\d`)),
+			input:             "abc",
+			expectedMatched:   "",
+			expectedSeparator: "",
+			expectedRemaining: "abc",
+			expectedOk:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, separator, remaining, ok := tt.cu.Consume(tt.input, tt.ops...)
+			assert.Equal(t, tt.expectedMatched, matched, "matched")
+			assert.Equal(t, tt.expectedSeparator, separator, "separator")
+			assert.Equal(t, tt.expectedRemaining, remaining, "remaining")
+			assert.Equal(t, tt.expectedOk, ok, "ok")
+		})
+	}
+}
+
+func TestUntilRegexpConsumer_Iterator(t *testing.T) {
+	cu := NewUntilRegexpConsumer(regexp.MustCompile(`,\s*`))
+	var tokens, separators []string
+	for token, separator := range cu.Iterator(`a, "b, c",d`, consume.Encasing{Start: `"`, End: `"`}) {
+		tokens = append(tokens, token)
+		separators = append(separators, separator)
+	}
+	assert.Equal(t, []string{"a", `"b, c"`, "d"}, tokens)
+	assert.Equal(t, []string{", ", ",", ""}, separators)
+}
+
+func TestUntilRegexpConsumer_StartOffsetPastEnd(t *testing.T) {
+	// A StartOffset past the end of the data finds nothing, as it does for literal separators, and one past
+	// the end of a read makes the split function wait for more.
+	re := NewUntilRegexpConsumer(regexp.MustCompile(`,\s*`))
+	lit := NewUntilConsumer(",")
+
+	matched, separator, remaining, ok := re.Consume("ab", consume.StartOffset(5))
+	assert.False(t, ok)
+	assert.Equal(t, []string{"", "", "ab"}, []string{matched, separator, remaining})
+
+	input := "ab,cdefg, h"
+	scanAll := func(cu UntilConsumer, r io.Reader) []string {
+		bs := bufio.NewScanner(r)
+		bs.Split(cu.SplitFunc(consume.StartOffset(5)))
+		var tokens []string
+		for bs.Scan() {
+			tokens = append(tokens, bs.Text())
+		}
+		assert.NoError(t, bs.Err())
+		return tokens
+	}
+	scanStream := func(cu UntilConsumer, r io.Reader) []string {
+		s := cu.Scanner(r, consume.StartOffset(5))
+		var tokens []string
+		for s.Scan() {
+			tokens = append(tokens, s.Text())
+		}
+		assert.NoError(t, s.Err())
+		return tokens
+	}
+	// The offset applies to each token, so the separator in the short last one is not found.
+	expected := []string{"ab,cdefg", "h"}
+	assert.NotPanics(t, func() {
+		assert.Equal(t, expected, scanAll(re, strings.NewReader(input)))
+		assert.Equal(t, expected, scanAll(re, iotest.OneByteReader(strings.NewReader(input))))
+		assert.Equal(t, expected, scanStream(re, iotest.OneByteReader(strings.NewReader(input))))
+	})
+	assert.Equal(t, []string{"ab,cdefg", " h"}, scanAll(lit, strings.NewReader(input)))
+}
+
+func TestUntilRegexpConsumer_SplitFunc(t *testing.T) {
+	cu := NewUntilRegexpConsumer(regexp.MustCompile(`\s+`))
+	input := "alpha   beta\t\tgamma \"d e\"  "
+	expected := []string{"alpha", "beta", "gamma", `"d e"`}
+
+	for name, r := range map[string]func(io.Reader) io.Reader{
+		"Whole":   func(r io.Reader) io.Reader { return r },
+		"OneByte": iotest.OneByteReader,
+	} {
+		t.Run(name, func(t *testing.T) {
+			// A run of spaces split across reads is still one separator.
+			bs := bufio.NewScanner(r(strings.NewReader(input)))
+			bs.Split(cu.SplitFunc(consume.Encasing{Start: `"`, End: `"`}))
+			var tokens []string
+			for bs.Scan() {
+				tokens = append(tokens, bs.Text())
+			}
+			assert.NoError(t, bs.Err())
+			assert.Equal(t, expected, tokens)
+
+			s := cu.Scanner(r(strings.NewReader(input)), consume.Encasing{Start: `"`, End: `"`})
+			tokens = nil
+			for s.Scan() {
+				tokens = append(tokens, s.Text())
+			}
+			assert.NoError(t, s.Err())
+			assert.Equal(t, expected, tokens)
+		})
+	}
+}
+
+func TestUntilFuncConsumer_Consume(t *testing.T) {
+	tests := []struct {
+		name              string
+		cu                UntilConsumer
+		input             string
+		ops               []any
+		expectedMatched   string
+		expectedSeparator string
+		expectedRemaining string
+		expectedOk        bool
+	}{
+		{
+			name:              "Space",
+			cu:                NewUntilFuncConsumer(unicode.IsSpace),
+			input:             "foo \tbar",
+			expectedMatched:   "foo",
+			expectedSeparator: " ",
+			expectedRemaining: " \tbar",
+			expectedOk:        true,
+		},
+		{
+			name:              "Collapsed run",
+			cu:                NewUntilFuncConsumer(unicode.IsSpace),
+			input:             "foo \t bar",
+			ops:               []any{consume.CollapseRuns(true)},
+			expectedMatched:   "foo",
+			expectedSeparator: " \t ",
+			expectedRemaining: " \t bar",
+			expectedOk:        true,
+		},
+		{
+			name:              "Collapsed run, inclusive",
+			cu:                NewUntilRangeTableConsumer(unicode.Punct),
+			input:             "wait...what?!",
+			ops:               []any{consume.CollapseRuns(true), consume.Inclusive(true)},
+			expectedMatched:   "wait...",
+			expectedSeparator: "...",
+			expectedRemaining: "what?!",
+			expectedOk:        true,
+		},
+		{
+			name:              "Several tables",
+			cu:                NewUntilRangeTableConsumer(unicode.Digit, unicode.Han),
+			input:             "ab世1",
+			expectedMatched:   "ab",
+			expectedSeparator: "世",
+			expectedRemaining: "世1",
+			expectedOk:        true,
+		},
+		{
+			name:              "Escaped rune is skipped",
+			cu:                NewUntilFuncConsumer(unicode.IsSpace),
+			input:             `my\ file other`,
+			ops:               []any{consume.Escape(`\`), consume.Unescape(true)},
+			expectedMatched:   "my file",
+			expectedSeparator: " ",
+			expectedRemaining: " other",
+			expectedOk:        true,
+		},
+		{
+			name:              "Run stops at an escape",
+			cu:                NewUntilFuncConsumer(unicode.IsSpace),
+			input:             "a  \\ b",
+			ops:               []any{consume.Escape(`\`), consume.CollapseRuns(true)},
+			expectedMatched:   "a",
+			expectedSeparator: "  ",
+			expectedRemaining: "  \\ b",
+			expectedOk:        true,
+		},
+		{
+			name:              "Encased runes are skipped",
+			cu:                NewUntilFuncConsumer(unicode.IsSpace),
+			input:             `"a b"  c`,
+			ops:               []any{consume.Encasing{Start: `"`, End: `"`}, consume.CollapseRuns(true)},
+			expectedMatched:   `"a b"`,
+			expectedSeparator: "  ",
+			expectedRemaining: "  c",
+			expectedOk:        true,
+		},
+		{
+			name:              "Ignore0PositionMatch",
+			cu:                NewUntilFuncConsumer(unicode.IsSpace),
+			input:             "  a b",
+			ops:               []any{consume.Ignore0PositionMatch(true)},
+			expectedMatched:   " ",
+			expectedSeparator: " ",
+			expectedRemaining: " a b",
+			expectedOk:        true,
+		},
+		{
+			name:              "Run of literal and rune separators",
+			cu:                NewUntilConsumer("--").OrFunc(unicode.IsSpace),
+			input:             "a -- --b",
+			ops:               []any{consume.CollapseRuns(true)},
+			expectedMatched:   "a",
+			expectedSeparator: " -- --",
+			expectedRemaining: " -- --b",
+			expectedOk:        true,
+		},
+		{
+			name:              "Collapsed literal separators",
+			cu:                NewUntilConsumer(","),
+			input:             "a,,,b",
+			ops:               []any{consume.CollapseRuns(true)},
+			expectedMatched:   "a",
+			expectedSeparator: ",,,",
+			expectedRemaining: ",,,b",
+			expectedOk:        true,
+		},
+		{
+			name:              "Collapsed case insensitive separators",
+			cu:                NewUntilConsumer("x").OrRangeTable(unicode.Digit),
+			input:             "aX1x2b",
+			ops:               []any{consume.CollapseRuns(true), consume.CaseInsensitive(true)},
+			expectedMatched:   "a",
+			expectedSeparator: "X1x2",
+			expectedRemaining: "X1x2b",
+			expectedOk:        true,
+		},
+		{
+			name:              "MustMatchWholeString with a run",
+			cu:                NewUntilFuncConsumer(unicode.IsSpace),
+			input:             " \t ",
+			ops:               []any{consume.CollapseRuns(true), consume.MustMatchWholeString(true)},
+			expectedMatched:   "",
+			expectedSeparator: " \t ",
+			expectedRemaining: " \t ",
+			expectedOk:        true,
+		},
+		{
+			name:              "Invalid UTF-8",
+			cu:                NewUntilFuncConsumer(func(r rune) bool { return r == utf8.RuneError }),
+			input:             "ab\xffc",
+			expectedMatched:   "ab",
+			expectedSeparator: "\xff",
+			expectedRemaining: "\xffc",
+			expectedOk:        true,
+		},
+		{
+			name:              "No match",
+			cu:                NewUntilFuncConsumer(unicode.IsDigit),
+			input:             "abc",
+			expectedMatched:   "",
+			expectedSeparator: "",
+			expectedRemaining: "abc",
+			expectedOk:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, separator, remaining, ok := tt.cu.Consume(tt.input, tt.ops...)
+			assert.Equal(t, tt.expectedMatched, matched, "matched")
+			assert.Equal(t, tt.expectedSeparator, separator, "separator")
+			assert.Equal(t, tt.expectedRemaining, remaining, "remaining")
+			assert.Equal(t, tt.expectedOk, ok, "ok")
+		})
+	}
+}
+
+func TestUntilFuncConsumer_Iterator(t *testing.T) {
+	cu := NewUntilFuncConsumer(unicode.IsSpace)
+	input := `say  "hello world"   twice`
+	enc := consume.Encasing{Start: `"`, End: `"`}
+
+	var words []string
+	for word := range cu.Iterator(input, enc, consume.CollapseRuns(true)) {
+		words = append(words, word)
+	}
+	assert.Equal(t, []string{"say", `"hello world"`, "twice"}, words)
+
+	words = nil
+	for word := range cu.Iterator(input, enc) {
+		words = append(words, word)
+	}
+	assert.Equal(t, []string{"say", "", `"hello world"`, "", "", "twice"}, words)
+}
+
+func TestUntilFuncConsumer_SplitFunc(t *testing.T) {
+	// Runs split across reads must still be one separator.
+	seps := []string{",", "--", "Xy"}
+	alphabet := []string{"a", "b", " ", "\t", ",", "-", "x", "X", "y", "\\", "世"}
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n < 500; n++ {
+		var sb strings.Builder
+		for k := r.Intn(30); k > 0; k-- {
+			sb.WriteString(alphabet[r.Intn(len(alphabet))])
+		}
+		input := sb.String()
+		cu := NewUntilConsumer(seps...).OrFunc(unicode.IsSpace)
+		ops := []any{consume.CollapseRuns(true), consume.Escape("\\")}
+		if r.Intn(2) == 0 {
+			ops = append(ops, consume.CaseInsensitive(true))
+		}
+
+		scan := func(r io.Reader) []string {
+			bs := bufio.NewScanner(r)
+			bs.Split(cu.SplitFunc(ops...))
+			var tokens []string
+			for bs.Scan() {
+				tokens = append(tokens, bs.Text())
+			}
+			assert.NoError(t, bs.Err())
+			return tokens
+		}
+		expected := scan(strings.NewReader(input))
+		tokens := scan(iotest.OneByteReader(strings.NewReader(input)))
+		if !assert.Equal(t, expected, tokens, "input %q, options %#v", input, ops) {
+			return
+		}
+	}
+}
+
+func TestUntilConsumer_Consume_Bounds(t *testing.T) {
+	digit := consume.MustBePrecededBy(unicode.IsDigit)
+	space := consume.MustBeFollowedBy(unicode.IsSpace)
+	tests := []struct {
+		name              string
+		cu                UntilConsumer
+		input             string
+		ops               []any
+		expectedMatched   string
+		expectedSeparator string
+		expectedRemaining string
+		expectedOk        bool
+	}{
+		{
+			name:              "Whole word",
+			cu:                NewUntilConsumer("and"),
+			input:             "sandy and rocky",
+			ops:               []any{consume.WordBoundary(true)},
+			expectedMatched:   "sandy ",
+			expectedSeparator: "and",
+			expectedRemaining: "and rocky",
+			expectedOk:        true,
+		},
+		{
+			name:              "Whole word at the start",
+			cu:                NewUntilConsumer("and"),
+			input:             "and so",
+			ops:               []any{consume.WordBoundary(true)},
+			expectedMatched:   "",
+			expectedSeparator: "and",
+			expectedRemaining: "and so",
+			expectedOk:        true,
+		},
+		{
+			name:              "Case insensitive whole word",
+			cu:                NewUntilConsumer("and"),
+			input:             "Andes AND alps",
+			ops:               []any{consume.WordBoundary(true), consume.CaseInsensitive(true)},
+			expectedMatched:   "Andes ",
+			expectedSeparator: "AND",
+			expectedRemaining: "AND alps",
+			expectedOk:        true,
+		},
+		{
+			name:              "Word boundary around punctuation",
+			cu:                NewUntilConsumer("-"),
+			input:             "a - b-c",
+			ops:               []any{consume.WordBoundary(true)},
+			expectedMatched:   "a - b",
+			expectedSeparator: "-",
+			expectedRemaining: "-c",
+			expectedOk:        true,
+		},
+		{
+			name:              "Word boundary with a regular expression",
+			cu:                NewUntilRegexpConsumer(regexp.MustCompile(`or|xor`)),
+			input:             "color xor",
+			ops:               []any{consume.WordBoundary(true), consume.Inclusive(true)},
+			expectedMatched:   "color xor",
+			expectedSeparator: "xor",
+			expectedRemaining: "",
+			expectedOk:        true,
+		},
+		{
+			name:              "No whole word",
+			cu:                NewUntilConsumer("and"),
+			input:             "sandy andes",
+			ops:               []any{consume.WordBoundary(true)},
+			expectedMatched:   "",
+			expectedSeparator: "",
+			expectedRemaining: "sandy andes",
+			expectedOk:        false,
+		},
+		{
+			name:              "MustBePrecededBy",
+			cu:                NewUntilConsumer("x"),
+			input:             "ax1x2",
+			ops:               []any{digit},
+			expectedMatched:   "ax1",
+			expectedSeparator: "x",
+			expectedRemaining: "x2",
+			expectedOk:        true,
+		},
+		{
+			name:              "MustBePrecededBy at the start",
+			cu:                NewUntilConsumer("x"),
+			input:             "xa",
+			ops:               []any{digit},
+			expectedMatched:   "",
+			expectedSeparator: "x",
+			expectedRemaining: "xa",
+			expectedOk:        true,
+		},
+		{
+			name:              "MustBePrecededBy sees before StartOffset",
+			cu:                NewUntilConsumer("x"),
+			input:             "1xax",
+			ops:               []any{digit, consume.StartOffset(1)},
+			expectedMatched:   "1",
+			expectedSeparator: "x",
+			expectedRemaining: "xax",
+			expectedOk:        true,
+		},
+		{
+			name:              "MustBeFollowedBy",
+			cu:                NewUntilConsumer(":"),
+			input:             "a:b: c",
+			ops:               []any{space},
+			expectedMatched:   "a:b",
+			expectedSeparator: ":",
+			expectedRemaining: ": c",
+			expectedOk:        true,
+		},
+		{
+			name:              "MustBeFollowedBy at the end",
+			cu:                NewUntilConsumer(":"),
+			input:             "a:b:",
+			ops:               []any{space, consume.Inclusive(true)},
+			expectedMatched:   "a:b:",
+			expectedSeparator: ":",
+			expectedRemaining: "",
+			expectedOk:        true,
+		},
+		{
+			name:              "MustBeAtEnd",
+			cu:                NewUntilConsumer("."),
+			input:             "a.b.",
+			ops:               []any{consume.MustBeAtEnd(true)},
+			expectedMatched:   "a.b",
+			expectedSeparator: ".",
+			expectedRemaining: ".",
+			expectedOk:        true,
+		},
+		{
+			name:              "MustBeAtEnd overlapping",
+			cu:                NewUntilConsumer("aa"),
+			input:             "aaa",
+			ops:               []any{consume.MustBeAtEnd(true)},
+			expectedMatched:   "a",
+			expectedSeparator: "aa",
+			expectedRemaining: "aa",
+			expectedOk:        true,
+		},
+		{
+			name:              "MustBeAtEnd not found",
+			cu:                NewUntilConsumer("."),
+			input:             "a.b",
+			ops:               []any{consume.MustBeAtEnd(true), consume.ConsumeRemainingIfNotFound(true)},
+			expectedMatched:   "a.b",
+			expectedSeparator: "",
+			expectedRemaining: "",
+			expectedOk:        true,
+		},
+		{
+			name:              "Escaped separator is still skipped",
+			cu:                NewUntilConsumer(" "),
+			input:             `a\  b`,
+			ops:               []any{consume.Escape(`\`), consume.MustBeFollowedBy(unicode.IsLetter)},
+			expectedMatched:   `a\ `,
+			expectedSeparator: " ",
+			expectedRemaining: " b",
+			expectedOk:        true,
+		},
+		{
+			name:              "Run is checked as a whole",
+			cu:                NewUntilFuncConsumer(unicode.IsSpace),
+			input:             "1  a  2 b",
+			ops:               []any{consume.CollapseRuns(true), consume.MustBeFollowedBy(unicode.IsLetter)},
+			expectedMatched:   "1",
+			expectedSeparator: "  ",
+			expectedRemaining: "  a  2 b",
+			expectedOk:        true,
+		},
+		{
+			name:              "Rejected run is skipped whole",
+			cu:                NewUntilFuncConsumer(unicode.IsSpace),
+			input:             "a  2 b",
+			ops:               []any{consume.CollapseRuns(true), digit},
+			expectedMatched:   "a  2",
+			expectedSeparator: " ",
+			expectedRemaining: " b",
+			expectedOk:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, separator, remaining, ok := tt.cu.Consume(tt.input, tt.ops...)
+			assert.Equal(t, tt.expectedMatched, matched, "matched")
+			assert.Equal(t, tt.expectedSeparator, separator, "separator")
+			assert.Equal(t, tt.expectedRemaining, remaining, "remaining")
+			assert.Equal(t, tt.expectedOk, ok, "ok")
+		})
+	}
+}
+
+func TestUntilConsumer_Bounds_AcrossTokens(t *testing.T) {
+	// The rune before a separator at the start of a token is the end of the one before, so each entry point
+	// gives the same tokens.
+	cu := NewUntilConsumer("ab")
+	notB := consume.MustBePrecededBy(func(r rune) bool { return r != 'b' })
+	expected := []string{"", "ab"}
+
+	var tokens []string
+	for token := range cu.Iterator("abab", notB) {
+		tokens = append(tokens, token)
+	}
+	assert.Equal(t, expected, tokens)
+
+	for _, r := range []io.Reader{strings.NewReader("abab"), iotest.OneByteReader(strings.NewReader("abab"))} {
+		bs := bufio.NewScanner(r)
+		bs.Split(cu.SplitFunc(notB))
+		tokens = nil
+		for bs.Scan() {
+			tokens = append(tokens, bs.Text())
+		}
+		assert.NoError(t, bs.Err())
+		assert.Equal(t, expected, tokens)
+	}
+
+	s := cu.Scanner(iotest.OneByteReader(strings.NewReader("abab")), notB)
+	tokens = nil
+	for s.Scan() {
+		tokens = append(tokens, s.Text())
+	}
+	assert.NoError(t, s.Err())
+	assert.Equal(t, expected, tokens)
+}
+
+func TestUntilConsumer_SplitFunc_WordBoundary(t *testing.T) {
+	cu := NewUntilConsumer("and")
+	input := "sand and andand and x"
+	expected := []string{"sand ", " andand ", " x"}
+
+	var tokens []string
+	for token := range cu.Iterator(input, consume.WordBoundary(true)) {
+		tokens = append(tokens, token)
+	}
+	assert.Equal(t, expected, tokens)
+
+	bs := bufio.NewScanner(iotest.OneByteReader(strings.NewReader(input)))
+	bs.Split(cu.SplitFunc(consume.WordBoundary(true)))
+	tokens = nil
+	for bs.Scan() {
+		tokens = append(tokens, bs.Text())
+	}
+	assert.NoError(t, bs.Err())
+	assert.Equal(t, expected, tokens)
+}
+
+func TestUntilConsumer_Consume_Normalization(t *testing.T) {
+	tests := []struct {
+		name              string
+		cu                UntilConsumer
+		input             string
+		ops               []any
+		expectedMatched   string
+		expectedSeparator string
+		expectedRemaining string
+		expectedOk        bool
+	}{
+		{
+			name:              "Decomposed input",
+			cu:                NewUntilConsumer("caf\u00e9"),
+			input:             "un cafe\u0301 noir",
+			ops:               []any{consume.NFC},
+			expectedMatched:   "un ",
+			expectedSeparator: "cafe\u0301",
+			expectedRemaining: "cafe\u0301 noir",
+			expectedOk:        true,
+		},
+		{
+			name:              "Composed input",
+			cu:                NewUntilConsumer("cafe\u0301"),
+			input:             "un caf\u00e9 noir",
+			ops:               []any{consume.NFD, consume.Inclusive(true)},
+			expectedMatched:   "un caf\u00e9",
+			expectedSeparator: "caf\u00e9",
+			expectedRemaining: " noir",
+			expectedOk:        true,
+		},
+		{
+			name:              "Marks in another order",
+			cu:                NewUntilConsumer("e\u0323\u0301"),
+			input:             "x\u00e9\u0323y",
+			ops:               []any{consume.NFC},
+			expectedMatched:   "x",
+			expectedSeparator: "\u00e9\u0323",
+			expectedRemaining: "\u00e9\u0323y",
+			expectedOk:        true,
+		},
+		{
+			name:              "Not part of a base rune and its marks",
+			cu:                NewUntilConsumer("\u00e9"),
+			input:             "\u1eb9\u0301 e\u0301\u0323",
+			ops:               []any{consume.NFC},
+			expectedMatched:   "",
+			expectedSeparator: "",
+			expectedRemaining: "\u1eb9\u0301 e\u0301\u0323",
+			expectedOk:        false,
+		},
+		{
+			name:              "Not the base rune alone",
+			cu:                NewUntilConsumer("e"),
+			input:             "\u00e9 e\u0301 e",
+			ops:               []any{consume.NFD},
+			expectedMatched:   "\u00e9 e\u0301 ",
+			expectedSeparator: "e",
+			expectedRemaining: "e",
+			expectedOk:        true,
+		},
+		{
+			name:              "Compatibility ligature",
+			cu:                NewUntilConsumer("fi"),
+			input:             "a \ufb01le",
+			ops:               []any{consume.NFKC},
+			expectedMatched:   "a ",
+			expectedSeparator: "\ufb01",
+			expectedRemaining: "\ufb01le",
+			expectedOk:        true,
+		},
+		{
+			name:              "Compatibility ligature without compatibility",
+			cu:                NewUntilConsumer("fi"),
+			input:             "a \ufb01le",
+			ops:               []any{consume.NFC},
+			expectedMatched:   "",
+			expectedSeparator: "",
+			expectedRemaining: "a \ufb01le",
+			expectedOk:        false,
+		},
+		{
+			name:              "Fullwidth letters with case folding",
+			cu:                NewUntilConsumer("abc"),
+			input:             "x \uff21\uff22\uff23",
+			ops:               []any{consume.NFKD, consume.CaseInsensitive(true)},
+			expectedMatched:   "x ",
+			expectedSeparator: "\uff21\uff22\uff23",
+			expectedRemaining: "\uff21\uff22\uff23",
+			expectedOk:        true,
+		},
+		{
+			name:              "Hangul",
+			cu:                NewUntilConsumer("\ud55c"),
+			input:             "\uac00\u1112\u1161\u11ab\uad6d",
+			ops:               []any{consume.NFC},
+			expectedMatched:   "\uac00",
+			expectedSeparator: "\u1112\u1161\u11ab",
+			expectedRemaining: "\u1112\u1161\u11ab\uad6d",
+			expectedOk:        true,
+		},
+		{
+			name:              "Byte for byte by default",
+			cu:                NewUntilConsumer("caf\u00e9"),
+			input:             "cafe\u0301",
+			expectedMatched:   "",
+			expectedSeparator: "",
+			expectedRemaining: "cafe\u0301",
+			expectedOk:        false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, separator, remaining, ok := tt.cu.Consume(tt.input, tt.ops...)
+			assert.Equal(t, tt.expectedMatched, matched, "matched")
+			assert.Equal(t, tt.expectedSeparator, separator, "separator")
+			assert.Equal(t, tt.expectedRemaining, remaining, "remaining")
+			assert.Equal(t, tt.expectedOk, ok, "ok")
+		})
+	}
+}
+
+func TestUntilConsumer_SplitFunc_Normalization(t *testing.T) {
+	// A separator is not cut off before the combining marks after it, however the input is read.
+	cu := NewUntilConsumer("\u00e9")
+	input := "ae\u0301be\u0301\u0323c\u00e9d"
+	expected := []string{"a", "be\u0301\u0323c", "d"}
+
+	var tokens []string
+	for token := range cu.Iterator(input, consume.NFC) {
+		tokens = append(tokens, token)
+	}
+	assert.Equal(t, expected, tokens)
+
+	for _, r := range []io.Reader{strings.NewReader(input), iotest.OneByteReader(strings.NewReader(input))} {
+		bs := bufio.NewScanner(r)
+		bs.Split(cu.SplitFunc(consume.NFC))
+		tokens = nil
+		for bs.Scan() {
+			tokens = append(tokens, bs.Text())
+		}
+		assert.NoError(t, bs.Err())
+		assert.Equal(t, expected, tokens)
+	}
+
+	s := cu.Scanner(iotest.OneByteReader(strings.NewReader(input)), consume.NFC)
+	tokens = nil
+	for s.Scan() {
+		tokens = append(tokens, s.Text())
+	}
+	assert.NoError(t, s.Err())
+	assert.Equal(t, expected, tokens)
+}
+
+func TestUntilConsumer_SplitFunc_OneByteReads(t *testing.T) {
+	// A separator at the end of the data waits for a longer one which starts at or before it, so reading a
+	// byte at a time gives the same tokens as reading the whole input.
+	scanAll := func(r io.Reader, split bufio.SplitFunc) []string {
+		bs := bufio.NewScanner(r)
+		bs.Split(split)
+		var tokens []string
+		for bs.Scan() {
+			tokens = append(tokens, bs.Text())
+		}
+		assert.NoError(t, bs.Err())
+		return tokens
+	}
+	tests := []struct {
+		name     string
+		cu       UntilConsumer
+		input    string
+		ops      []any
+		expected []string
+	}{
+		{
+			name:     "Longer separator sharing a prefix",
+			cu:       NewUntilConsumer("A", "A,", "b"),
+			input:    `,\b,,\A,(A)`,
+			expected: []string{`,\`, `,,\`, `(`, `)`},
+		},
+		{
+			name:     "Longer separator starting sooner",
+			cu:       NewUntilConsumer("xyz", "y"),
+			input:    "axyzbyc",
+			expected: []string{"a", "b", "c"},
+		},
+		{
+			name:     "With an escape and an encasing",
+			cu:       NewUntilConsumer("A", "A,", "b"),
+			input:    `,\b,,\A,(A)A,c`,
+			ops:      []any{consume.Escape(`\`), consume.Encasing{Start: "(", End: ")"}},
+			expected: []string{`,\b,,\A,(A)`, "c"},
+		},
+		{
+			name:     "Case insensitive",
+			cu:       NewUntilConsumer("a", "a--"),
+			input:    "xA--yA-z",
+			ops:      []any{consume.CaseInsensitive(true)},
+			expected: []string{"x", "y", "-z"},
+		},
+		{
+			name:     "With a pattern",
+			cu:       NewUntilConsumer("abcd").OrRegexp(regexp.MustCompile(`b`)),
+			input:    "xabcdyabz",
+			expected: []string{"x", "ya", "z"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, scanAll(strings.NewReader(tt.input), tt.cu.SplitFunc(tt.ops...)))
+			assert.Equal(t, tt.expected, scanAll(iotest.OneByteReader(strings.NewReader(tt.input)), tt.cu.SplitFunc(tt.ops...)))
+
+			s := tt.cu.Scanner(iotest.OneByteReader(strings.NewReader(tt.input)), tt.ops...)
+			var tokens []string
+			for s.Scan() {
+				tokens = append(tokens, s.Text())
+			}
+			assert.NoError(t, s.Err())
+			assert.Equal(t, tt.expected, tokens)
+		})
+	}
+}
+
+func TestUntilConsumer_SplitFunc_OneByteReads_Random(t *testing.T) {
+	alphabet := []string{"a", "b", "A", ",", "\\", "(", ")", "é", "É"}
+	optionSets := [][]any{
+		nil,
+		{consume.Inclusive(true)},
+		{consume.CaseInsensitive(true)},
+		{consume.Escape("\\"), consume.Encasing{Start: "(", End: ")"}},
+		{consume.NFC},
+	}
+	r := rand.New(rand.NewSource(1))
+	randString := func(maxLen int) string {
+		var sb strings.Builder
+		for n := r.Intn(maxLen + 1); n > 0; n-- {
+			sb.WriteString(alphabet[r.Intn(len(alphabet))])
+		}
+		return sb.String()
+	}
+	scanAll := func(rd io.Reader, split bufio.SplitFunc) ([]string, error) {
+		bs := bufio.NewScanner(rd)
+		bs.Split(split)
+		var tokens []string
+		for bs.Scan() {
+			tokens = append(tokens, bs.Text())
+		}
+		return tokens, bs.Err()
+	}
+	for n := 0; n < 1000; n++ {
+		var seps []string
+		for k := r.Intn(3); k >= 0; k-- {
+			if sep := randString(3); sep != "" {
+				seps = append(seps, sep)
+			}
+		}
+		if len(seps) == 0 {
+			continue
+		}
+		cu := NewUntilConsumer(seps...)
+		input := randString(16)
+		ops := optionSets[r.Intn(len(optionSets))]
+
+		expected, err := scanAll(strings.NewReader(input), cu.SplitFunc(ops...))
+		if err != nil {
+			continue
+		}
+		got, err := scanAll(iotest.OneByteReader(strings.NewReader(input)), cu.SplitFunc(ops...))
+		if err != nil || !assert.Equal(t, expected, got, "SplitFunc %q with separators %q and options %#v", input, seps, ops) {
+			return
+		}
+		s := cu.Scanner(iotest.OneByteReader(strings.NewReader(input)), ops...)
+		got = nil
+		for s.Scan() {
+			got = append(got, s.Text())
+		}
+		if s.Err() != nil || !assert.Equal(t, expected, got, "Scanner %q with separators %q and options %#v", input, seps, ops) {
+			return
+		}
+	}
+}